@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// AbsentFilterConfig configures the filter of known-absent keys. Zero
+// fields take their defaults.
+type AbsentFilterConfig struct {
+	// ExpectedKeys is how many absent keys the filter is sized for.
+	// Default 100,000.
+	ExpectedKeys int
+	// FalsePositiveRate is the probability that a key that was never
+	// reported absent is treated as absent. Default 0.001.
+	FalsePositiveRate float64
+	// Rotation is how long a key reported absent is remembered: keys are
+	// forgotten between one and two rotations after being reported.
+	// Default 10 minutes.
+	Rotation time.Duration
+	// RemoteTTL, if positive, also records keys reported absent as
+	// tombstones in the backing store for that long, so that the other
+	// processes sharing the store fail fast on them too instead of
+	// reaching the origin. Tombstones are only cleared by SetThrough and
+	// by expiring, so RemoteTTL should be shorter than the TTL of values.
+	// It has no effect without WithStore.
+	RemoteTTL time.Duration
+}
+
+// WithAbsentFilter remembers keys whose getter returned an error wrapping
+// ErrNotFound in a Bloom filter, so repeated lookups of nonexistent keys
+// fail fast with ErrNotFound instead of reaching the origin again. Being
+// probabilistic, the filter may occasionally report an existing key as
+// absent (at cfg.FalsePositiveRate); keys reported absent are retried once
+// they rotate out of the filter. Cached entries are always served.
+func WithAbsentFilter(cfg AbsentFilterConfig) Option {
+	return func(o *options) {
+		o.absentFilter = &cfg
+	}
+}
+
+// absentPrefix starts the backing store keys of tombstones.
+const absentPrefix = "__absent__:"
+
+// absentFilter is a pair of Bloom filters: keys are added to current and
+// looked up in both, and every rotation current becomes previous and a
+// fresh filter takes its place.
+type absentFilter struct {
+	seed     maphash.Seed
+	hashes   int
+	rotation time.Duration
+	// remoteTTL is how long tombstones are kept in the backing store;
+	// zero disables them
+	remoteTTL time.Duration
+
+	mu        sync.Mutex
+	current   []uint64
+	previous  []uint64
+	rotatedAt time.Time
+}
+
+func newAbsentFilter(cfg AbsentFilterConfig, now time.Time) *absentFilter {
+	if cfg.ExpectedKeys <= 0 {
+		cfg.ExpectedKeys = 100_000
+	}
+	if cfg.FalsePositiveRate <= 0 || cfg.FalsePositiveRate >= 1 {
+		cfg.FalsePositiveRate = 0.001
+	}
+	if cfg.Rotation <= 0 {
+		cfg.Rotation = 10 * time.Minute
+	}
+	// Optimal Bloom filter parameters for n keys at false positive rate p
+	n, p := float64(cfg.ExpectedKeys), cfg.FalsePositiveRate
+	bits := math.Ceil(-n * math.Log(p) / (math.Ln2 * math.Ln2))
+	hashes := int(math.Max(1, math.Round(bits/n*math.Ln2)))
+	words := int(bits+63) / 64
+	return &absentFilter{
+		seed:      maphash.MakeSeed(),
+		hashes:    hashes,
+		rotation:  cfg.Rotation,
+		remoteTTL: cfg.RemoteTTL,
+		current:   make([]uint64, words),
+		previous:  make([]uint64, words),
+		rotatedAt: now,
+	}
+}
+
+// positions returns the bit positions of an entry, using double hashing.
+func (f *absentFilter) positions(valueType reflect.Type, key any) (h1, h2, bits uint64) {
+	var h maphash.Hash
+	h.SetSeed(f.seed)
+	h.WriteString(keyString(valueType, key))
+	sum := h.Sum64()
+	return sum, sum>>32 | 1, uint64(len(f.current)) * 64
+}
+
+// add records key as absent.
+func (f *absentFilter) add(valueType reflect.Type, key any, now time.Time) {
+	h1, h2, bits := f.positions(valueType, key)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rotateLocked(now)
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % bits
+		f.current[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// contains reports whether key was probably recorded as absent.
+func (f *absentFilter) contains(valueType reflect.Type, key any, now time.Time) bool {
+	h1, h2, bits := f.positions(valueType, key)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rotateLocked(now)
+	return f.testLocked(f.current, h1, h2, bits) || f.testLocked(f.previous, h1, h2, bits)
+}
+
+func (f *absentFilter) testLocked(set []uint64, h1, h2, bits uint64) bool {
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % bits
+		if set[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *absentFilter) rotateLocked(now time.Time) {
+	elapsed := now.Sub(f.rotatedAt)
+	if elapsed < f.rotation {
+		return
+	}
+	if elapsed >= 2*f.rotation {
+		// Both filters are outdated
+		f.previous = make([]uint64, len(f.current))
+	} else {
+		f.previous = f.current
+	}
+	f.current = make([]uint64, len(f.previous))
+	f.rotatedAt = now
+}
+
+// tombstones returns the absent filter if keys reported absent are also
+// recorded in the backing store.
+func (s *store) tombstones() (*absentFilter, bool) {
+	filter := s.absent.Load()
+	if filter == nil || filter.remoteTTL <= 0 || s.remote == nil {
+		return nil, false
+	}
+	return filter, true
+}
+
+// tombstoneKey returns the backing store key of the tombstone of key.
+func (s *store) tombstoneKey(valueType reflect.Type, key any) string {
+	return s.keyPrefix + absentPrefix + valueType.String() + ":" + s.cfg().keyCodec.EncodeKey(key)
+}
+
+// loadTombstone reports whether another process recorded key as absent in
+// the backing store, in which case it is added to the local filter too.
+func (s *store) loadTombstone(valueType reflect.Type, key any) bool {
+	filter, ok := s.tombstones()
+	if !ok {
+		return false
+	}
+	_, found, err := s.remote.Get(context.Background(), s.tombstoneKey(valueType, key))
+	if err != nil {
+		s.emit(Event{Kind: EventStoreError, Type: valueType, Key: key, Err: err})
+		return false
+	}
+	if found {
+		filter.add(valueType, key, s.now())
+	}
+	return found
+}
+
+// storeTombstone records key as absent in the backing store.
+func (s *store) storeTombstone(valueType reflect.Type, key any) {
+	filter, ok := s.tombstones()
+	if !ok {
+		return
+	}
+	if err := s.remote.Set(context.Background(), s.tombstoneKey(valueType, key), []byte{}, filter.remoteTTL); err != nil {
+		s.emit(Event{Kind: EventStoreError, Type: valueType, Key: key, Err: err})
+	}
+}
+
+// clearTombstone removes the tombstone of key from the backing store, if
+// any.
+func (s *store) clearTombstone(valueType reflect.Type, key any) {
+	if _, ok := s.tombstones(); !ok {
+		return
+	}
+	if err := s.remote.Delete(context.Background(), s.tombstoneKey(valueType, key)); err != nil {
+		s.emit(Event{Kind: EventStoreError, Type: valueType, Key: key, Err: err})
+	}
+}
@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AbsentTestSuite struct {
+	suite.Suite
+	clock *FakeClock
+	calls atomic.Int32
+}
+
+func TestAbsentSuite(t *testing.T) {
+	suite.Run(t, new(AbsentTestSuite))
+}
+
+// SetupTest runs before each test
+func (s *AbsentTestSuite) SetupTest() {
+	s.clock = NewFakeClock(time.Now())
+	s.calls.Store(0)
+}
+
+// loader finds even keys only
+func (s *AbsentTestSuite) loader(key int) (string, error) {
+	s.calls.Add(1)
+	if key%2 != 0 {
+		return "", fmt.Errorf("user %d: %w", key, ErrNotFound)
+	}
+	return "user", nil
+}
+
+func (s *AbsentTestSuite) newCache() *Cache[int, string] {
+	return New[int, string](
+		WithClock(s.clock),
+		WithLoader(s.loader),
+		WithAbsentFilter(AbsentFilterConfig{ExpectedKeys: 1000, Rotation: time.Minute}),
+	)
+}
+
+// TestAbsentKeysShortCircuit verifies that absent keys skip the origin
+func (s *AbsentTestSuite) TestAbsentKeysShortCircuit() {
+	c := s.newCache()
+
+	_, err := c.Get(1)
+	s.ErrorIs(err, ErrNotFound)
+	_, err = c.Get(1)
+	s.ErrorIs(err, ErrNotFound)
+	var loadErr *LoadError
+	s.True(errors.As(err, &loadErr))
+	s.Equal(1, loadErr.Key)
+
+	s.Equal(int32(1), s.calls.Load(), "The origin should be asked once")
+}
+
+// TestOtherErrorsAreNotRemembered verifies that only ErrNotFound feeds the filter
+func (s *AbsentTestSuite) TestOtherErrorsAreNotRemembered() {
+	c := New[int, string](
+		WithLoader(func(key int) (string, error) {
+			s.calls.Add(1)
+			return "", errors.New("database down")
+		}),
+		WithAbsentFilter(AbsentFilterConfig{}),
+	)
+
+	_, _ = c.Get(1)
+	_, _ = c.Get(1)
+	s.Equal(int32(2), s.calls.Load())
+}
+
+// TestAbsentKeysRotateOut verifies that absent keys are retried after rotation
+func (s *AbsentTestSuite) TestAbsentKeysRotateOut() {
+	c := s.newCache()
+	_, _ = c.Get(1)
+
+	s.clock.Advance(time.Minute)
+	_, err := c.Get(1)
+	s.ErrorIs(err, ErrNotFound)
+	s.Equal(int32(1), s.calls.Load(), "Keys are remembered for at least one rotation")
+
+	s.clock.Advance(time.Minute)
+	_, _ = c.Get(1)
+	s.Equal(int32(2), s.calls.Load(), "Keys are forgotten after two rotations")
+}
+
+// TestCachedValuesWin verifies that a key set after being reported absent is served
+func (s *AbsentTestSuite) TestCachedValuesWin() {
+	c := s.newCache()
+	_, _ = c.Get(1)
+
+	c.Set(1, "created")
+	value, err := c.Get(1)
+	s.NoError(err)
+	s.Equal("created", value)
+}
+
+// TestFalsePositiveRate verifies the filter stays close to its configured rate
+func (s *AbsentTestSuite) TestFalsePositiveRate() {
+	now := time.Now()
+	filter := newAbsentFilter(AbsentFilterConfig{ExpectedKeys: 10_000, FalsePositiveRate: 0.01}, now)
+	valueType := getTypeOf(0)
+	for key := 0; key < 10_000; key++ {
+		filter.add(valueType, key, now)
+	}
+	falsePositives := 0
+	for key := 10_000; key < 20_000; key++ {
+		if filter.contains(valueType, key, now) {
+			falsePositives++
+		}
+	}
+	s.Less(falsePositives, 200, "False positives should stay near the configured rate")
+}
+
+// TestRemoteTombstones verifies that keys found absent by one cache are
+// absent for the others sharing its backing store, until SetThrough
+// stores them
+func (s *AbsentTestSuite) TestRemoteTombstones() {
+	remote := newMemoryStore()
+	newCache := func() *Cache[int, string] {
+		return New[int, string](
+			WithClock(s.clock),
+			WithLoader(s.loader),
+			WithStore(remote),
+			WithAbsentFilter(AbsentFilterConfig{Rotation: time.Minute, RemoteTTL: 10 * time.Second}),
+		)
+	}
+	first, second := newCache(), newCache()
+
+	_, err := first.Get(1)
+	s.ErrorIs(err, ErrNotFound)
+	s.Equal(10*time.Second, remote.ttls["__absent__:string:1"])
+	_, err = second.Get(1)
+	s.ErrorIs(err, ErrNotFound)
+	s.Equal(int32(1), s.calls.Load(), "The sibling finds the tombstone")
+
+	s.NoError(first.SetThrough(1, "created"))
+	value, err := newCache().Get(1)
+	s.NoError(err)
+	s.Equal("created", value)
+	_, found, _ := remote.Get(context.Background(), "__absent__:string:1")
+	s.False(found)
+}
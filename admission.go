@@ -0,0 +1,117 @@
+package cache
+
+import "reflect"
+
+// AdmissionPolicy decides whether a new key may enter a cache that is full,
+// at the price of evicting another entry. Keeping one-hit wonders out of a
+// full cache protects the entries that are read repeatedly.
+// Implementations must be safe for concurrent use; they are called with
+// the cache's lock held and must not call back into the cache.
+type AdmissionPolicy interface {
+	// Record notes an access of key, whether it was a hit or a miss.
+	Record(key any)
+	// Admit reports whether key, with an estimated size of cost bytes
+	// (zero when sizes are not tracked), may enter the full cache.
+	Admit(key any, cost int64) bool
+}
+
+// VictimAdmissionPolicy is implemented by admission policies that decide
+// by comparing the candidate with the entry it would displace. The cache
+// calls AdmitOver instead of Admit when it knows the victim.
+type VictimAdmissionPolicy interface {
+	AdmissionPolicy
+	AdmitOver(candidate, victim any) bool
+}
+
+// WithAdmissionPolicy sets the policy consulted before a new key enters a
+// full cache. Rejected writes are not cached and are counted in
+// Statistics.Rejections; the value is still returned to the caller that
+// loaded it. Without a policy every key is admitted.
+func WithAdmissionPolicy(policy AdmissionPolicy) Option {
+	return func(o *options) {
+		o.admission = policy
+	}
+}
+
+// AlwaysAdmit is the AdmissionPolicy admitting every key.
+type AlwaysAdmit struct{}
+
+// Record does nothing.
+func (AlwaysAdmit) Record(any) {}
+
+// Admit returns true.
+func (AlwaysAdmit) Admit(any, int64) bool { return true }
+
+// TinyLFU is an AdmissionPolicy admitting a new key into a full cache only
+// if it has been accessed more often recently than the entry it would
+// displace, as estimated by a count-min sketch with aging.
+type TinyLFU struct {
+	sketch *sketch
+}
+
+// NewTinyLFU returns a TinyLFU policy sized for a cache of about capacity
+// entries.
+func NewTinyLFU(capacity int) *TinyLFU {
+	return &TinyLFU{sketch: newSketch(capacity)}
+}
+
+// Record counts an access of key.
+func (t *TinyLFU) Record(key any) {
+	t.sketch.increment(t.sketch.hash(nil, key))
+}
+
+// Admit admits key if it has been seen before, which filters out keys
+// accessed only once when no victim is known.
+func (t *TinyLFU) Admit(key any, cost int64) bool {
+	return t.estimate(key) > 1
+}
+
+// AdmitOver admits candidate if it is more frequent than victim.
+func (t *TinyLFU) AdmitOver(candidate, victim any) bool {
+	return t.estimate(candidate) > t.estimate(victim)
+}
+
+func (t *TinyLFU) estimate(key any) uint32 {
+	return t.sketch.estimate(t.sketch.hash(nil, key))
+}
+
+// admitLocked reports whether e may be stored as the new key of the
+// valueType partition. Must be called with s.mu held for writing.
+func (s *store) admitLocked(valueType reflect.Type, key any, e *entry) bool {
+	policy := s.cfg().admission
+	if policy == nil {
+		return true
+	}
+
+	var onlyType reflect.Type
+	switch cfg := s.cfg(); {
+	case s.typeFullLocked(valueType):
+		onlyType = valueType
+	case (cfg.maxEntries > 0 && s.count+1 > cfg.maxEntries) ||
+		(cfg.maxBytes > 0 && s.bytes+e.size > cfg.maxBytes):
+	default:
+		return true
+	}
+
+	admitted := true
+	if p, ok := policy.(VictimAdmissionPolicy); ok {
+		if victim, found := s.victimLocked(entryKey{valueType, key}, onlyType); found {
+			admitted = p.AdmitOver(key, victim.key)
+		} else {
+			admitted = p.Admit(key, e.size)
+		}
+	} else {
+		admitted = policy.Admit(key, e.size)
+	}
+	if !admitted {
+		s.rejections.Add(1)
+	}
+	return admitted
+}
+
+// typeFullLocked reports whether a new key of valueType would exceed the
+// type's own entry limit.
+func (s *store) typeFullLocked(valueType reflect.Type) bool {
+	limit := s.typeLimit(valueType)
+	return limit > 0 && s.lenLocked(valueType)+1 > limit
+}
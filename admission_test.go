@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AdmissionTestSuite struct {
+	suite.Suite
+}
+
+func TestAdmissionSuite(t *testing.T) {
+	suite.Run(t, new(AdmissionTestSuite))
+}
+
+// recordingPolicy is an AdmissionPolicy remembering the keys it was asked
+// about and admitting none.
+type recordingPolicy struct {
+	mu       sync.Mutex
+	recorded []any
+	asked    []any
+}
+
+func (p *recordingPolicy) Record(key any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recorded = append(p.recorded, key)
+}
+
+func (p *recordingPolicy) Admit(key any, cost int64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.asked = append(p.asked, key)
+	return false
+}
+
+// TestTinyLFURejectsColdKeys verifies that a rarely used key does not
+// displace a frequently used one
+func (s *AdmissionTestSuite) TestTinyLFURejectsColdKeys() {
+	policy := NewTinyLFU(16)
+	c := New[string, int](WithMaxEntries(1), WithAdmissionPolicy(policy))
+	c.Set("hot", 1)
+	for i := 0; i < 5; i++ {
+		_, _ = c.Get("hot")
+	}
+
+	c.Set("cold", 2)
+	_, ok := c.Peek("cold")
+	s.False(ok, "The cold key is turned away")
+	_, ok = c.Peek("hot")
+	s.True(ok, "The hot key stays cached")
+	s.Equal(uint64(1), c.Stats().Rejections)
+
+	for i := 0; i < 10; i++ {
+		policy.Record("cold")
+	}
+	c.Set("cold", 2)
+	_, ok = c.Peek("cold")
+	s.True(ok, "Once more popular, the key is admitted")
+	s.Equal(uint64(1), c.Stats().Evictions)
+}
+
+// TestAlwaysAdmit verifies that AlwaysAdmit keeps plain eviction
+func (s *AdmissionTestSuite) TestAlwaysAdmit() {
+	c := New[string, int](WithMaxEntries(1), WithAdmissionPolicy(AlwaysAdmit{}))
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	_, ok := c.Peek("b")
+	s.True(ok)
+	s.Equal(uint64(0), c.Stats().Rejections)
+	s.Equal(uint64(1), c.Stats().Evictions)
+}
+
+// TestCustomPolicy verifies that a policy sees accesses and is only asked
+// about new keys when the cache is full
+func (s *AdmissionTestSuite) TestCustomPolicy() {
+	policy := &recordingPolicy{}
+	c := New[string, int](WithMaxEntries(1), WithAdmissionPolicy(policy))
+	c.Set("a", 1)
+	_, _ = c.Get("a")
+	c.Set("a", 2)
+	c.Set("b", 3)
+
+	s.Equal([]any{"a"}, policy.recorded)
+	s.Equal([]any{"b"}, policy.asked, "Only the write of a new key into a full cache is checked")
+	v, _ := c.Get("a")
+	s.Equal(2, v)
+}
@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// AuditOp identifies the kind of invalidation recorded by an audit log.
+type AuditOp string
+
+const (
+	// AuditDelete records a Delete.
+	AuditDelete AuditOp = "delete"
+	// AuditClear records a Clear.
+	AuditClear AuditOp = "clear"
+	// AuditInvalidateTags records an InvalidateTags.
+	AuditInvalidateTags AuditOp = "invalidate-tags"
+	// AuditRefresh records a Get with WithForceRefresh.
+	AuditRefresh AuditOp = "refresh"
+)
+
+// AuditRecord describes an invalidation.
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	Op   AuditOp   `json:"op"`
+	// Type is the name of the value type involved, if any.
+	Type string `json:"type,omitempty"`
+	// Key is the key involved, if any, formatted with fmt after going
+	// through the Redactor.
+	Key string `json:"key,omitempty"`
+	// Tags are the tags of an InvalidateTags.
+	Tags []string `json:"tags,omitempty"`
+	// Reason is the reason given with WithReason.
+	Reason string `json:"reason,omitempty"`
+	// Caller is the file and line of the code that made the call.
+	Caller string `json:"caller"`
+}
+
+// AuditQuery selects audit records. Zero fields match every record.
+type AuditQuery struct {
+	Op    AuditOp
+	Type  string
+	Key   string
+	Since time.Time
+}
+
+// WithAuditLog records the last size invalidations of the cache, namely
+// Delete, Clear, InvalidateTags and Get with WithForceRefresh, together with
+// their time, caller and reason, answering who blew away the cache and
+// when. Query the log with AuditRecords or serve it with AuditHandler.
+func WithAuditLog(size int) Option {
+	return func(o *options) {
+		o.auditSize = size
+	}
+}
+
+// WithReason explains an invalidation in the audit log; it applies to
+// Delete, Clear and Get with WithForceRefresh.
+//
+//	cache.Delete[int, *User](id, cache.WithReason("user renamed"))
+func WithReason(reason string) Option {
+	return func(o *options) {
+		o.reason = reason
+	}
+}
+
+// reasonOf returns the reason set by opts with WithReason.
+func reasonOf(opts []Option) string {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.reason
+}
+
+// auditLog is a ring buffer of the most recent audit records.
+type auditLog struct {
+	mu      sync.Mutex
+	records []AuditRecord
+	// next is the index the next record is written at
+	next int
+	full bool
+}
+
+func newAuditLog(size int) *auditLog {
+	return &auditLog{records: make([]AuditRecord, size)}
+}
+
+func (l *auditLog) add(r AuditRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records[l.next] = r
+	l.next = (l.next + 1) % len(l.records)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// query returns the records matching q, oldest first.
+func (l *auditLog) query(q AuditQuery) []AuditRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ordered := l.records[:l.next]
+	if l.full {
+		ordered = append(append([]AuditRecord(nil), l.records[l.next:]...), l.records[:l.next]...)
+	}
+	var matched []AuditRecord
+	for _, r := range ordered {
+		if (q.Op == "" || r.Op == q.Op) && (q.Type == "" || r.Type == q.Type) &&
+			(q.Key == "" || r.Key == q.Key) && !r.Time.Before(q.Since) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// audit records an invalidation if the store keeps an audit log. It must
+// be called directly by the exported function making the invalidation, so
+// that the caller recorded is the code calling that function.
+func (s *store) audit(op AuditOp, valueType reflect.Type, key any, tags []string, reason string) {
+	l := s.auditLog.Load()
+	if l == nil {
+		return
+	}
+	r := AuditRecord{Time: s.now(), Op: op, Tags: tags, Reason: reason}
+	if valueType != nil {
+		r.Type = s.typeName(valueType)
+		if key != nil {
+			r.Key = fmt.Sprint(s.redactKey(r.Type, key))
+		}
+	}
+	if _, file, line, ok := runtime.Caller(2); ok {
+		r.Caller = fmt.Sprintf("%s:%d", file, line)
+	}
+	l.add(r)
+}
+
+// AuditRecords returns the records of the package-level audit log matching
+// q, oldest first. It returns nil unless WithAuditLog was passed to
+// SetDefaults.
+func AuditRecords(q AuditQuery) []AuditRecord {
+	return globalStore().auditRecords(q)
+}
+
+// AuditRecords returns the records of the cache's audit log matching q,
+// oldest first. It returns nil unless the cache has an audit log.
+func (c *Cache[K, V]) AuditRecords(q AuditQuery) []AuditRecord {
+	return c.s.auditRecords(q)
+}
+
+func (s *store) auditRecords(q AuditQuery) []AuditRecord {
+	l := s.auditLog.Load()
+	if l == nil {
+		return nil
+	}
+	return l.query(q)
+}
+
+// AuditHandler serves the package-level audit log as JSON, for mounting on
+// an admin endpoint. The op, type and key query parameters filter records,
+// and since takes an RFC 3339 time.
+func AuditHandler() http.Handler {
+	return auditHandler(AuditRecords)
+}
+
+// AuditHandler serves the cache's audit log as JSON; see the package-level
+// AuditHandler.
+func (c *Cache[K, V]) AuditHandler() http.Handler {
+	return auditHandler(c.AuditRecords)
+}
+
+func auditHandler(records func(AuditQuery) []AuditRecord) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		q := AuditQuery{
+			Op:   AuditOp(params.Get("op")),
+			Type: params.Get("type"),
+			Key:  params.Get("key"),
+		}
+		if since := params.Get("since"); since != "" {
+			t, err := time.Parse(time.RFC3339, since)
+			if err != nil {
+				http.Error(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			q.Since = t
+		}
+		found := records(q)
+		if found == nil {
+			found = []AuditRecord{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(found)
+	})
+}
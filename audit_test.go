@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditTestSuite struct {
+	suite.Suite
+}
+
+func TestAuditSuite(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
+
+// TestInvalidationsAreRecorded verifies that deletes and clears are
+// recorded with their caller and reason
+func (s *AuditTestSuite) TestInvalidationsAreRecorded() {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	c := New[string, int](WithClock(clock), WithAuditLog(10))
+	c.Set("a", 1)
+	c.Delete("a", WithReason("user renamed"))
+	clock.Advance(time.Minute)
+	c.Clear()
+
+	records := c.AuditRecords(AuditQuery{})
+	s.Require().Len(records, 2)
+	s.Equal(AuditDelete, records[0].Op)
+	s.Equal("int", records[0].Type)
+	s.Equal("a", records[0].Key)
+	s.Equal("user renamed", records[0].Reason)
+	s.Equal(time.Unix(1000, 0), records[0].Time)
+	s.True(strings.Contains(records[0].Caller, "audit_test.go:"), "Caller should be the test, got %s", records[0].Caller)
+	s.Equal(AuditClear, records[1].Op)
+
+	since := c.AuditRecords(AuditQuery{Since: time.Unix(1030, 0)})
+	s.Require().Len(since, 1)
+	s.Equal(AuditClear, since[0].Op)
+}
+
+// TestPackageLevelAudit verifies recording of tag invalidations and forced
+// refreshes on the package-level cache
+func (s *AuditTestSuite) TestPackageLevelAudit() {
+	Reset()
+	defer Reset()
+	s.Nil(AuditRecords(AuditQuery{}), "The audit log is off by default")
+
+	SetDefaults(WithAuditLog(10))
+	getter := func(key int) (int, error) { return key, nil }
+	_, err := Get(1, getter, WithForceRefresh(), WithReason("stale price"))
+	s.NoError(err)
+	InvalidateTags("prices")
+	Delete[int, int](1)
+
+	records := AuditRecords(AuditQuery{})
+	s.Require().Len(records, 3)
+	s.Equal(AuditRefresh, records[0].Op)
+	s.Equal("stale price", records[0].Reason)
+	s.Equal([]string{"prices"}, records[1].Tags)
+	s.Len(AuditRecords(AuditQuery{Op: AuditDelete, Key: "1"}), 1)
+}
+
+// TestAuditLogKeepsLatestRecords verifies that the oldest records make way
+func (s *AuditTestSuite) TestAuditLogKeepsLatestRecords() {
+	c := New[int, int](WithAuditLog(3))
+	for i := 0; i < 5; i++ {
+		c.Delete(i)
+	}
+	records := c.AuditRecords(AuditQuery{})
+	s.Require().Len(records, 3)
+	for i, r := range records {
+		s.Equal(string(rune('2'+i)), r.Key)
+	}
+}
+
+// TestAuditHandler verifies the JSON admin endpoint
+func (s *AuditTestSuite) TestAuditHandler() {
+	c := New[string, int](WithAuditLog(10))
+	c.Delete("a")
+	c.Delete("b")
+
+	rec := httptest.NewRecorder()
+	c.AuditHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/audit?key=b", nil))
+	s.Equal(http.StatusOK, rec.Code)
+	var records []AuditRecord
+	s.NoError(json.Unmarshal(rec.Body.Bytes(), &records))
+	s.Require().Len(records, 1)
+	s.Equal("b", records[0].Key)
+
+	rec = httptest.NewRecorder()
+	c.AuditHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/audit?since=yesterday", nil))
+	s.Equal(http.StatusBadRequest, rec.Code)
+}
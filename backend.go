@@ -0,0 +1,257 @@
+package cache
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Backend is the local storage engine holding the entries of one value
+// type. Each value type of a cache gets its own Backend. Values are opaque
+// records owned by the cache and must be returned unchanged.
+//
+// The cache serializes every call that modifies a Backend, but reads may
+// run concurrently with each other, so implementations must allow
+// concurrent Load, Len and Range calls. Range must tolerate no
+// modification while it runs; the cache never modifies a Backend from
+// within Range.
+type Backend interface {
+	// Load returns the value stored for key.
+	Load(key any) (value any, ok bool)
+	// Store sets the value for key.
+	Store(key, value any)
+	// Delete removes key.
+	Delete(key any)
+	// Len returns the number of keys stored.
+	Len() int
+	// Range calls fn for every key and value until fn returns false. The
+	// order is unspecified; eviction samples entries in Range order, so
+	// a randomized order gives better approximate LRU.
+	Range(fn func(key, value any) bool)
+	// Clone returns an independent copy, used when a snapshot shares the
+	// Backend and the cache is about to modify it.
+	Clone() Backend
+}
+
+// WithBackend sets the constructor of the local storage engine, called
+// once for every value type cached. By default entries are kept in a Go
+// map, see NewMapBackend.
+func WithBackend(newBackend func() Backend) Option {
+	return func(o *options) {
+		o.newBackend = newBackend
+	}
+}
+
+// NewMapBackend returns the default Backend, a Go map.
+func NewMapBackend() Backend {
+	return mapBackend{}
+}
+
+// mapBackend is a Backend backed by a Go map.
+type mapBackend map[any]any
+
+func (m mapBackend) Load(key any) (any, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func (m mapBackend) Store(key, value any) { m[key] = value }
+func (m mapBackend) Delete(key any)       { delete(m, key) }
+func (m mapBackend) Len() int             { return len(m) }
+
+func (m mapBackend) Range(fn func(key, value any) bool) {
+	for key, value := range m {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+func (m mapBackend) Clone() Backend {
+	clone := make(mapBackend, len(m))
+	for key, value := range m {
+		clone[key] = value
+	}
+	return clone
+}
+
+// NewSyncMapBackend returns a Backend backed by a sync.Map. Lookups of a
+// cache using it read the Backend directly instead of taking the cache's
+// read lock, so reads scale across cores without contending on the lock.
+// It suits read-mostly workloads with a stable set of keys; writes, Range
+// and Clone are slower than with NewMapBackend, and it uses more memory.
+func NewSyncMapBackend() Backend {
+	return &syncMapBackend{}
+}
+
+// concurrentBackend is implemented by Backends whose Load may run
+// concurrently with Store and Delete, which the cache reads without its
+// lock.
+type concurrentBackend interface {
+	Backend
+	concurrentLoads()
+}
+
+// syncMapBackend is a Backend backed by a sync.Map.
+type syncMapBackend struct {
+	m sync.Map
+	n atomic.Int64
+}
+
+func (b *syncMapBackend) concurrentLoads() {}
+
+func (b *syncMapBackend) Load(key any) (any, bool) {
+	return b.m.Load(key)
+}
+
+func (b *syncMapBackend) Store(key, value any) {
+	// Writes are serialized by the cache, so the count stays exact
+	if _, ok := b.m.Load(key); !ok {
+		b.n.Add(1)
+	}
+	b.m.Store(key, value)
+}
+
+func (b *syncMapBackend) Delete(key any) {
+	if _, ok := b.m.LoadAndDelete(key); ok {
+		b.n.Add(-1)
+	}
+}
+
+func (b *syncMapBackend) Len() int {
+	return int(b.n.Load())
+}
+
+func (b *syncMapBackend) Range(fn func(key, value any) bool) {
+	b.m.Range(fn)
+}
+
+func (b *syncMapBackend) Clone() Backend {
+	clone := &syncMapBackend{}
+	b.m.Range(func(key, value any) bool {
+		clone.m.Store(key, value)
+		clone.n.Add(1)
+		return true
+	})
+	return clone
+}
+
+// partitionView is the set of partitions as read by lookups. When there is
+// a single partition, as in every instance cache, lookups compare its type
+// instead of hashing it into data.
+type partitionView struct {
+	data map[reflect.Type]Backend
+	// soleType and sole are the only partition, if there is exactly one
+	soleType reflect.Type
+	sole     Backend
+}
+
+func newPartitionView(data map[reflect.Type]Backend) partitionView {
+	v := partitionView{data: data}
+	if len(data) == 1 {
+		for valueType, b := range data {
+			v.soleType, v.sole = valueType, b
+		}
+	}
+	return v
+}
+
+// partition returns the valueType partition, if any.
+func (v *partitionView) partition(valueType reflect.Type) (Backend, bool) {
+	if v.sole != nil {
+		return v.sole, v.soleType == valueType
+	}
+	b, ok := v.data[valueType]
+	return b, ok
+}
+
+// entry returns the entry stored for key in the valueType partition,
+// expired or not.
+func (v *partitionView) entry(valueType reflect.Type, key any) (*entry, bool) {
+	b, ok := v.partition(valueType)
+	if !ok {
+		return nil, false
+	}
+	value, ok := b.Load(canonicalKey(key))
+	if !ok {
+		return nil, false
+	}
+	return value.(*entry), true
+}
+
+// publishLocked refreshes the view of the partitions after one was added,
+// dropped or replaced, along with the copy read by lookups without s.mu if
+// the store keeps one. Must be called with s.mu held for writing.
+func (s *store) publishLocked() {
+	s.view = newPartitionView(s.data)
+	if !s.concurrentReads {
+		return
+	}
+	data := make(map[reflect.Type]Backend, len(s.data))
+	for valueType, b := range s.data {
+		data[valueType] = b
+	}
+	published := newPartitionView(data)
+	s.published.Store(&published)
+}
+
+// entryLocked returns the entry stored for key in the valueType partition,
+// expired or not. Must be called with s.mu held.
+func (s *store) entryLocked(valueType reflect.Type, key any) (*entry, bool) {
+	return s.view.entry(valueType, key)
+}
+
+// lenLocked returns the number of entries in the valueType partition.
+// Must be called with s.mu held.
+func (s *store) lenLocked(valueType reflect.Type) int {
+	if b, ok := s.data[valueType]; ok {
+		return b.Len()
+	}
+	return 0
+}
+
+// rangeLocked calls fn for every entry of the valueType partition until fn
+// returns false. fn must not modify the store. Must be called with s.mu
+// held.
+func (s *store) rangeLocked(valueType reflect.Type, fn func(key any, e *entry) bool) {
+	if b, ok := s.data[valueType]; ok {
+		rangeEntries(b, fn)
+	}
+}
+
+// rangeAllLocked calls fn for every entry of every partition until fn
+// returns false. fn must not modify the store. Must be called with s.mu
+// held.
+func (s *store) rangeAllLocked(fn func(valueType reflect.Type, key any, e *entry) bool) {
+	for valueType, b := range s.data {
+		more := true
+		rangeEntries(b, func(key any, e *entry) bool {
+			more = fn(valueType, key, e)
+			return more
+		})
+		if !more {
+			return
+		}
+	}
+}
+
+// rangeEntries calls fn for every entry of b until fn returns false.
+func rangeEntries(b Backend, fn func(key any, e *entry) bool) {
+	b.Range(func(key, value any) bool {
+		return fn(key, value.(*entry))
+	})
+}
+
+// newPartition returns an empty partition of the store's engine. Must be
+// called with s.mu held for writing.
+func (s *store) newPartition() Backend {
+	if s.newBackend == nil {
+		return make(mapBackend, s.initialCapacity)
+	}
+	b := s.newBackend()
+	if _, ok := b.(concurrentBackend); ok {
+		// Lookups can skip the lock from now on
+		s.concurrentReads = true
+	}
+	return b
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BackendTestSuite struct {
+	suite.Suite
+}
+
+func TestBackendSuite(t *testing.T) {
+	suite.Run(t, new(BackendTestSuite))
+}
+
+// countingBackend is a map Backend counting the calls it receives.
+type countingBackend struct {
+	Backend
+	stores, deletes, clones *atomic.Int32
+}
+
+func newCountingBackend() countingBackend {
+	return countingBackend{
+		Backend: NewMapBackend(),
+		stores:  new(atomic.Int32),
+		deletes: new(atomic.Int32),
+		clones:  new(atomic.Int32),
+	}
+}
+
+func (b countingBackend) Store(key, value any) {
+	b.stores.Add(1)
+	b.Backend.Store(key, value)
+}
+
+func (b countingBackend) Delete(key any) {
+	b.deletes.Add(1)
+	b.Backend.Delete(key)
+}
+
+func (b countingBackend) Clone() Backend {
+	b.clones.Add(1)
+	clone := b
+	clone.Backend = b.Backend.Clone()
+	return clone
+}
+
+// TestCustomBackend verifies that entries are kept in the configured
+// backend
+func (s *BackendTestSuite) TestCustomBackend() {
+	backend := newCountingBackend()
+	created := 0
+	c := New[string, int](WithBackend(func() Backend {
+		created++
+		return backend
+	}))
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Delete("a")
+
+	v, err := c.Get("b")
+	s.NoError(err)
+	s.Equal(2, v)
+	s.Equal(1, created, "One backend per value type")
+	s.Equal(int32(2), backend.stores.Load())
+	s.Equal(int32(1), backend.deletes.Load())
+	s.Equal(1, backend.Len())
+}
+
+// TestBackendSnapshots verifies that a shared backend is cloned before it is
+// modified
+func (s *BackendTestSuite) TestBackendSnapshots() {
+	backend := newCountingBackend()
+	c := New[string, int](WithBackend(func() Backend { return backend }))
+	c.Set("a", 1)
+
+	snap := c.Snapshot()
+	c.Set("a", 2)
+	c.Set("b", 3)
+
+	v, ok := snap.Get("a")
+	s.True(ok)
+	s.Equal(1, v, "The snapshot keeps the old value")
+	s.Equal(1, snap.Len())
+	s.Equal(int32(1), backend.clones.Load(), "Only the first write after a snapshot copies")
+}
+
+// TestSyncMapBackend verifies that lookups served without the lock see
+// concurrent writes, deletions and snapshots consistently
+func (s *BackendTestSuite) TestSyncMapBackend() {
+	c := New[int, int](WithBackend(NewSyncMapBackend))
+	for i := 0; i < 100; i++ {
+		s.Require().NoError(c.Set(i, i))
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if v, ok := c.Peek(i % 100); ok {
+					s.Equal(i%100, v)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Set(i%100, i%100)
+				if i%10 == 0 {
+					c.Delete(i % 100)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	c.Clear()
+	s.Require().NoError(c.Set(1, 1))
+	snap := c.Snapshot()
+	s.Require().NoError(c.Set(1, 2))
+	v, ok := snap.Get(1)
+	s.True(ok)
+	s.Equal(1, v, "The snapshot keeps the old value")
+	v, ok = c.Peek(1)
+	s.True(ok)
+	s.Equal(2, v)
+}
+
+// TestPartitionView verifies that lookups find entries in the partition of
+// their value type alone, whether the store holds one partition or several
+func (s *BackendTestSuite) TestPartitionView() {
+	c := New[int, int]()
+	s.Require().NoError(c.Set(1, 10))
+	intType, stringType := getTypeOf(0), getTypeOf("")
+
+	_, ok := c.s.lookupEntry(stringType, 1)
+	s.False(ok, "The sole partition holds another type")
+	e, ok := c.s.lookupEntry(intType, 1)
+	s.Require().True(ok)
+	s.Equal(10, e.value)
+
+	c.s.mu.Lock()
+	c.s.putLocked(stringType, 1, c.s.newEntry(stringType, "one"))
+	c.s.mu.Unlock()
+	e, ok = c.s.lookupEntry(stringType, 1)
+	s.Require().True(ok)
+	s.Equal("one", e.value)
+	e, ok = c.s.lookupEntry(intType, 1)
+	s.Require().True(ok)
+	s.Equal(10, e.value)
+
+	c.Delete(1)
+	_, ok = c.s.lookupEntry(intType, 1)
+	s.False(ok)
+	e, ok = c.s.lookupEntry(stringType, 1)
+	s.Require().True(ok, "The remaining partition becomes the sole one")
+	s.Equal("one", e.value)
+}
+
+// TestInitialCapacity verifies that a pre-sized cache allocates entries in
+// blocks and keeps working past its initial capacity
+func (s *BackendTestSuite) TestInitialCapacity() {
+	c := New[int, int](WithInitialCapacity(1000))
+	s.Require().NotNil(c.s.entries)
+	s.Equal(maxEntryBlock, c.s.entries.size)
+
+	for i := 0; i < 2000; i++ {
+		s.Require().NoError(c.Set(i, i))
+	}
+	for i := 0; i < 2000; i++ {
+		v, ok := c.Peek(i)
+		s.Require().True(ok)
+		s.Equal(i, v)
+	}
+	first, _ := c.s.lookupEntry(getTypeOf(0), 0)
+	second, _ := c.s.lookupEntry(getTypeOf(0), 1)
+	s.Equal(unsafe.Sizeof(entry{}), uintptr(unsafe.Pointer(second))-uintptr(unsafe.Pointer(first)),
+		"Consecutive entries come from the same block")
+
+	c.Clear()
+	s.Require().NoError(c.Set(1, 1))
+	v, ok := c.Peek(1)
+	s.True(ok)
+	s.Equal(1, v)
+}
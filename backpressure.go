@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BackpressureConfig configures the detection of miss storms.
+type BackpressureConfig struct {
+	// MaxInFlight is the number of getters running at once above which
+	// the cache is under pressure. Zero ignores it.
+	MaxInFlight int64
+	// MaxMissRatio is the share of lookups missing over a Window above
+	// which the cache is under pressure. Zero ignores it.
+	MaxMissRatio float64
+	// MinLookups is how many lookups a Window needs for its miss ratio to
+	// count. Default 100.
+	MinLookups int64
+	// Window is the period the miss ratio is measured over. Default 1s.
+	Window time.Duration
+	// OnChange is called when the cache comes under pressure and when it
+	// recovers. It is called synchronously by the lookup or load that
+	// caused the change and must not block.
+	OnChange func(Pressure)
+}
+
+// Pressure describes the load on the origin when backpressure changes.
+type Pressure struct {
+	// Active reports whether the cache is under pressure.
+	Active bool
+	// InFlight is the number of getters running.
+	InFlight int64
+	// MissRatio is the share of lookups that missed over the last
+	// complete window.
+	MissRatio float64
+}
+
+// WithBackpressure signals miss storms, such as after a deploy or a mass
+// invalidation, so the application can shed load or degrade its responses
+// while the cache refills. The cache is under pressure while more getters
+// than cfg.MaxInFlight run or more than cfg.MaxMissRatio of the lookups
+// miss; cfg.OnChange is told when that starts and ends, and UnderPressure
+// reports it at any time.
+func WithBackpressure(cfg BackpressureConfig) Option {
+	return func(o *options) {
+		o.backpressure = &cfg
+	}
+}
+
+// UnderPressure reports whether the package-level cache is under pressure
+// as configured with WithBackpressure.
+func UnderPressure() bool {
+	return globalStore().underPressure()
+}
+
+// UnderPressure reports whether the cache is under pressure as configured
+// with WithBackpressure.
+func (c *Cache[K, V]) UnderPressure() bool {
+	return c.s.underPressure()
+}
+
+type pressureMonitor struct {
+	cfg BackpressureConfig
+
+	// lookups and misses count the lookups of the current window, which
+	// started at windowStart (in Unix nanoseconds)
+	lookups     atomic.Int64
+	misses      atomic.Int64
+	windowStart atomic.Int64
+
+	// mu serializes state changes so OnChange sees them in order
+	mu        sync.Mutex
+	missRatio float64
+	active    atomic.Bool
+}
+
+func newPressureMonitor(cfg BackpressureConfig, now time.Time) *pressureMonitor {
+	if cfg.MinLookups <= 0 {
+		cfg.MinLookups = 100
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Second
+	}
+	m := &pressureMonitor{cfg: cfg}
+	m.windowStart.Store(now.UnixNano())
+	return m
+}
+
+func (s *store) underPressure() bool {
+	m := s.pressure.Load()
+	return m != nil && m.active.Load()
+}
+
+// observeLookup feeds a hit or miss to the pressure monitor, if any.
+func (s *store) observeLookup(miss bool) {
+	m := s.pressure.Load()
+	if m == nil {
+		return
+	}
+	m.lookups.Add(1)
+	if miss {
+		m.misses.Add(1)
+	}
+	now := s.now()
+	start := m.windowStart.Load()
+	if now.UnixNano()-start < int64(m.cfg.Window) || !m.windowStart.CompareAndSwap(start, now.UnixNano()) {
+		return
+	}
+	// This lookup closed the window
+	lookups, misses := m.lookups.Swap(0), m.misses.Swap(0)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.missRatio = 0
+	if lookups >= m.cfg.MinLookups {
+		m.missRatio = float64(misses) / float64(lookups)
+	}
+	m.updateLocked(s.inFlight.Load())
+}
+
+// observeInFlight tells the pressure monitor, if any, that the number of
+// running getters changed.
+func (s *store) observeInFlight(inFlight int64) {
+	m := s.pressure.Load()
+	if m == nil || m.cfg.MaxInFlight <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updateLocked(inFlight)
+}
+
+// updateLocked re-evaluates the pressure and calls OnChange if it changed.
+// Must be called with m.mu held.
+func (m *pressureMonitor) updateLocked(inFlight int64) {
+	active := (m.cfg.MaxInFlight > 0 && inFlight > m.cfg.MaxInFlight) ||
+		(m.cfg.MaxMissRatio > 0 && m.missRatio > m.cfg.MaxMissRatio)
+	if active == m.active.Load() {
+		return
+	}
+	m.active.Store(active)
+	if m.cfg.OnChange != nil {
+		m.cfg.OnChange(Pressure{Active: active, InFlight: inFlight, MissRatio: m.missRatio})
+	}
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BackpressureTestSuite struct {
+	suite.Suite
+	mu      sync.Mutex
+	changes []Pressure
+}
+
+func TestBackpressureSuite(t *testing.T) {
+	suite.Run(t, new(BackpressureTestSuite))
+}
+
+func (s *BackpressureTestSuite) SetupTest() {
+	s.changes = nil
+}
+
+func (s *BackpressureTestSuite) onChange(p Pressure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changes = append(s.changes, p)
+}
+
+func (s *BackpressureTestSuite) recorded() []Pressure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Pressure(nil), s.changes...)
+}
+
+// TestInFlight verifies that too many concurrent getters signal pressure
+// until they finish
+func (s *BackpressureTestSuite) TestInFlight() {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	loader := func(key string) (string, error) {
+		started <- struct{}{}
+		<-release
+		return key, nil
+	}
+	c := New[string, string](WithLoader(loader), WithBackpressure(BackpressureConfig{
+		MaxInFlight: 1,
+		OnChange:    s.onChange,
+	}))
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			_, _ = c.Get(key)
+		}(key)
+	}
+	<-started
+	<-started
+	s.True(c.UnderPressure())
+
+	close(release)
+	wg.Wait()
+	s.False(c.UnderPressure())
+	changes := s.recorded()
+	s.Len(changes, 2)
+	s.Equal(Pressure{Active: true, InFlight: 2}, changes[0])
+	s.False(changes[1].Active)
+}
+
+// TestMissRatio verifies that a window with mostly misses signals pressure
+// and that a window of hits ends it
+func (s *BackpressureTestSuite) TestMissRatio() {
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := New[string, int](WithClock(clock), WithBackpressure(BackpressureConfig{
+		MaxMissRatio: 0.5,
+		MinLookups:   4,
+		Window:       time.Second,
+		OnChange:     s.onChange,
+	}))
+	c.Set("hot", 1)
+
+	for i := 0; i < 4; i++ {
+		_, _ = c.Get(fmt.Sprint("cold", i))
+	}
+	s.False(c.UnderPressure(), "Pressure is only evaluated once the window ends")
+	clock.Advance(time.Second)
+	_, _ = c.Get("hot")
+	s.True(c.UnderPressure())
+
+	for i := 0; i < 4; i++ {
+		_, _ = c.Get("hot")
+	}
+	clock.Advance(time.Second)
+	_, _ = c.Get("hot")
+	s.False(c.UnderPressure())
+
+	changes := s.recorded()
+	s.Len(changes, 2)
+	s.Equal(Pressure{Active: true, MissRatio: 0.8}, changes[0])
+	s.Equal(Pressure{Active: false}, changes[1])
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Promise is the eventual result of a load started by GetOrLoadMany.
+type Promise[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func newPromise[V any]() *Promise[V] {
+	return &Promise[V]{done: make(chan struct{})}
+}
+
+func (p *Promise[V]) resolve(value V, err error) {
+	p.value, p.err = value, err
+	close(p.done)
+}
+
+// Done returns a channel that is closed once the result is available.
+func (p *Promise[V]) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait blocks until the result is available and returns it, with the
+// error Get would have returned.
+func (p *Promise[V]) Wait() (V, error) {
+	<-p.done
+	return p.value, p.err
+}
+
+// GetOrLoadMany returns a promise for the value of each of keys. Promises
+// of cached keys are resolved on return; the others resolve as their loads
+// complete, so callers can use whatever is ready first. Loads go through
+// Get: they are coalesced with concurrent loads of the same key and at
+// most limit of them run at once, without limit if it is not positive.
+// Duplicate keys share a promise, and keys that cannot be map keys, such
+// as interface keys holding slices, are left out.
+//
+//	promises := users.GetOrLoadMany(ids, 8)
+//	for _, id := range ids {
+//		if user, err := promises[id].Wait(); err == nil {
+//			render(user)
+//		}
+//	}
+func (c *Cache[K, V]) GetOrLoadMany(keys []K, limit int) map[K]*Promise[V] {
+	var sem chan struct{}
+	if limit > 0 {
+		sem = make(chan struct{}, limit)
+	}
+	promises := make(map[K]*Promise[V], len(keys))
+	for _, key := range keys {
+		if errors.Is(c.s.checkKey(key), ErrUnhashableKey) {
+			continue
+		}
+		if _, dup := promises[key]; dup {
+			continue
+		}
+		p := newPromise[V]()
+		promises[key] = p
+		if _, cached := c.Peek(key); cached {
+			p.resolve(c.Get(key))
+			continue
+		}
+		go func(key K) {
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
+			p.resolve(c.Get(key))
+		}(key)
+	}
+	return promises
+}
+
+// GroupLoader schedules loads from a Cache on an errgroup.Group, so that
+// they share its cancellation and concurrency limit, and collects their
+// values.
+//
+//	g, ctx := errgroup.WithContext(ctx)
+//	g.SetLimit(8)
+//	loads := cache.NewGroupLoader(ctx, g, users)
+//	loads.Load(ids...)
+//	if err := g.Wait(); err != nil {
+//		return err
+//	}
+//	byID := loads.Results()
+type GroupLoader[K comparable, V any] struct {
+	ctx   context.Context
+	group *errgroup.Group
+	cache *Cache[K, V]
+
+	mu      sync.Mutex
+	results map[K]V
+}
+
+// NewGroupLoader returns a GroupLoader running the loads of c on g. ctx is
+// usually the context returned by errgroup.WithContext along with g.
+func NewGroupLoader[K comparable, V any](ctx context.Context, g *errgroup.Group, c *Cache[K, V]) *GroupLoader[K, V] {
+	return &GroupLoader[K, V]{ctx: ctx, group: g, cache: c, results: make(map[K]V)}
+}
+
+// Load schedules a Get of each of keys on the group. An error returned by
+// Get fails the group, and loads that have not started by the time ctx is
+// done fail with its error instead of calling Get. Like Group.Go, Load
+// blocks while the concurrency limit of the group is reached.
+func (l *GroupLoader[K, V]) Load(keys ...K) {
+	for _, key := range keys {
+		key := key
+		l.group.Go(func() error {
+			if err := l.ctx.Err(); err != nil {
+				return err
+			}
+			value, err := l.cache.Get(key)
+			if err != nil {
+				return err
+			}
+			l.mu.Lock()
+			l.results[key] = value
+			l.mu.Unlock()
+			return nil
+		})
+	}
+}
+
+// Results returns the values loaded so far by key. Once Wait on the group
+// has returned nil, it holds the value of every key passed to Load.
+func (l *GroupLoader[K, V]) Results() map[K]V {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	results := make(map[K]V, len(l.results))
+	for key, value := range l.results {
+		results[key] = value
+	}
+	return results
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/sync/errgroup"
+)
+
+type BatchTestSuite struct {
+	suite.Suite
+}
+
+func TestBatchSuite(t *testing.T) {
+	suite.Run(t, new(BatchTestSuite))
+}
+
+// TestGetOrLoadMany verifies that cached keys resolve at once and misses
+// resolve as their loads complete, within the concurrency limit
+func (s *BatchTestSuite) TestGetOrLoadMany() {
+	release := make(chan struct{})
+	var running, peak atomic.Int32
+	c := New[int, int](WithLoader(func(key int) (int, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return key * 10, nil
+	}))
+	s.Require().NoError(c.Set(1, 100))
+
+	promises := c.GetOrLoadMany([]int{1, 2, 3, 4, 5, 2}, 2)
+	s.Len(promises, 5)
+	select {
+	case <-promises[1].Done():
+	default:
+		s.Fail("Cached keys resolve immediately")
+	}
+	value, err := promises[1].Wait()
+	s.NoError(err)
+	s.Equal(100, value)
+
+	close(release)
+	for key := 2; key <= 5; key++ {
+		value, err := promises[key].Wait()
+		s.NoError(err)
+		s.Equal(key*10, value)
+	}
+	s.LessOrEqual(peak.Load(), int32(2))
+}
+
+// TestGetOrLoadManyCacheAside verifies that misses of a cache-aside cache
+// resolve with ErrNotCached
+func (s *BatchTestSuite) TestGetOrLoadManyCacheAside() {
+	c := New[string, string]()
+	s.Require().NoError(c.Set("a", "value"))
+
+	promises := c.GetOrLoadMany([]string{"a", "b"}, 0)
+	value, err := promises["a"].Wait()
+	s.NoError(err)
+	s.Equal("value", value)
+	_, err = promises["b"].Wait()
+	s.ErrorIs(err, ErrNotCached)
+}
+
+// TestGroupLoader verifies that loads scheduled on an errgroup collect
+// their values and stop at the first failure
+func (s *BatchTestSuite) TestGroupLoader() {
+	errBroken := errors.New("broken")
+	var calls atomic.Int32
+	c := New[int, string](WithLoader(func(key int) (string, error) {
+		calls.Add(1)
+		if key < 0 {
+			return "", errBroken
+		}
+		return "user" + strconv.Itoa(key), nil
+	}))
+
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetLimit(2)
+	loads := NewGroupLoader(ctx, g, c)
+	loads.Load(1, 2, 3)
+	s.Require().NoError(g.Wait())
+	s.Equal(map[int]string{1: "user1", 2: "user2", 3: "user3"}, loads.Results())
+
+	calls.Store(0)
+	g, ctx = errgroup.WithContext(context.Background())
+	g.SetLimit(1)
+	loads = NewGroupLoader(ctx, g, c)
+	loads.Load(-1, 4, 5)
+	s.ErrorIs(g.Wait(), errBroken)
+	s.Equal(int32(1), calls.Load(), "Loads after the failure are cancelled")
+	s.Empty(loads.Results())
+}
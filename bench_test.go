@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// BenchmarkGetHit measures Get for a cached key.
+func BenchmarkGetHit(b *testing.B) {
+	c := New[int, string](WithLoader(func(key int) (string, error) {
+		return strconv.Itoa(key), nil
+	}))
+	_, _ = c.Get(1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = c.Get(1)
+	}
+}
+
+// BenchmarkGetHitParallel measures Get for cached keys from many
+// goroutines.
+func BenchmarkGetHitParallel(b *testing.B) {
+	c := New[int, string](WithLoader(func(key int) (string, error) {
+		return strconv.Itoa(key), nil
+	}))
+	for i := 0; i < 1024; i++ {
+		_, _ = c.Get(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			_, _ = c.Get(i % 1024)
+			i++
+		}
+	})
+}
+
+// BenchmarkGetMiss measures Get for keys that are not cached yet, getter
+// call and store included.
+func BenchmarkGetMiss(b *testing.B) {
+	c := New[int, string](WithLoader(func(key int) (string, error) {
+		return "value", nil
+	}))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = c.Get(i)
+	}
+}
+
+// BenchmarkGetContendedMiss measures Get when goroutines miss on the same
+// keys at once, so that loads are coalesced.
+func BenchmarkGetContendedMiss(b *testing.B) {
+	c := New[int64, string](WithLoader(func(key int64) (string, error) {
+		return "value", nil
+	}))
+	var next atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			// Groups of calls share a key
+			_, _ = c.Get(next.Add(1) / 8)
+		}
+	})
+}
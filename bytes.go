@@ -0,0 +1,73 @@
+package cache
+
+// BytesMode decides how []byte values are stored and served, for caches of
+// raw payloads such as response bodies.
+type BytesMode int
+
+const (
+	// BytesAsIs stores and serves []byte values like any other value, with
+	// sizes estimated like other values. It is the default.
+	BytesAsIs BytesMode = iota
+	// BytesReadOnly stores []byte values without copying them and serves
+	// views of the cached bytes, capped at their length so that appending
+	// to a view never writes into the cache. Callers must not modify the
+	// bytes they store or read. Entries count their exact length towards
+	// byte limits and statistics.
+	BytesReadOnly
+	// BytesCopyOnGet is BytesReadOnly except that every read returns a
+	// private copy, which callers are free to modify.
+	BytesCopyOnGet
+)
+
+// String returns a human-readable name for the mode.
+func (m BytesMode) String() string {
+	switch m {
+	case BytesAsIs:
+		return "as-is"
+	case BytesReadOnly:
+		return "read-only"
+	case BytesCopyOnGet:
+		return "copy-on-get"
+	default:
+		return "unknown"
+	}
+}
+
+// WithBytesMode sets how []byte values are stored and served; values of
+// other types are not affected. The default is BytesAsIs.
+//
+//	bodies := cache.New[string, []byte](cache.WithBytesMode(cache.BytesReadOnly))
+func WithBytesMode(mode BytesMode) Option {
+	return func(o *options) {
+		o.bytesMode = mode
+	}
+}
+
+// storedBytes returns the value to store for value and its exact size, or
+// value itself and -1 if it is not a []byte held in a bytes mode.
+func (s *store) storedBytes(value any) (any, int64) {
+	b, ok := value.([]byte)
+	if !ok || s.cfg().bytesMode == BytesAsIs {
+		return value, -1
+	}
+	return b[:len(b):len(b)], int64(len(b))
+}
+
+// serve returns the value to hand to a caller for the stored value.
+func (m BytesMode) serve(value any) any {
+	b, ok := value.([]byte)
+	if !ok {
+		return value
+	}
+	switch m {
+	case BytesReadOnly:
+		return b[:len(b):len(b)]
+	case BytesCopyOnGet:
+		if b == nil {
+			return b
+		}
+		return append([]byte{}, b...)
+	default:
+		return value
+	}
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BytesTestSuite struct {
+	suite.Suite
+}
+
+func TestBytesSuite(t *testing.T) {
+	suite.Run(t, new(BytesTestSuite))
+}
+
+// TestBytesReadOnly verifies that read-only views share the cached bytes,
+// cannot be appended into them and count their exact length
+func (s *BytesTestSuite) TestBytesReadOnly() {
+	c := New[string, []byte](WithBytesMode(BytesReadOnly))
+	payload := make([]byte, 5, 64)
+	copy(payload, "hello")
+	s.Require().NoError(c.Set("a", payload))
+
+	view, err := c.Get("a")
+	s.Require().NoError(err)
+	s.Equal([]byte("hello"), view)
+	s.Same(&payload[0], &view[0], "Views are not copies")
+	s.Equal(len(view), cap(view))
+
+	_ = append(view, " world"...)
+	s.Equal(byte(0), payload[:6][5], "Appending to a view reallocates")
+	s.Equal(int64(5), c.Stats().Bytes)
+
+	snap := c.Snapshot()
+	view, ok := snap.Get("a")
+	s.Require().True(ok)
+	s.Equal(len(view), cap(view))
+}
+
+// TestBytesCopyOnGet verifies that every read, including the one loading
+// the value, gets a private copy
+func (s *BytesTestSuite) TestBytesCopyOnGet() {
+	c := New[string, []byte](
+		WithBytesMode(BytesCopyOnGet),
+		WithLoader(func(key string) ([]byte, error) { return []byte(key), nil }),
+	)
+
+	loaded, err := c.Get("abc")
+	s.Require().NoError(err)
+	loaded[0] = 'x'
+	again, err := c.Get("abc")
+	s.Require().NoError(err)
+	s.Equal([]byte("abc"), again)
+	again[0] = 'y'
+	peeked, ok := c.Peek("abc")
+	s.True(ok)
+	s.Equal([]byte("abc"), peeked)
+	s.Equal(int64(3), c.Stats().Bytes)
+}
+
+// TestBytesInSnapshots verifies that snapshots serve []byte values in the
+// mode of their cache, whether read with Get or Range
+func (s *BytesTestSuite) TestBytesInSnapshots() {
+	c := New[string, []byte](WithBytesMode(BytesCopyOnGet))
+	c.Set("abc", []byte("abc"))
+	sn := c.Snapshot()
+
+	got, ok := sn.Get("abc")
+	s.True(ok)
+	got[0] = 'x'
+	sn.Range(func(key string, value []byte) bool {
+		s.Equal([]byte("abc"), value)
+		value[0] = 'y'
+		return true
+	})
+	got, _ = sn.Get("abc")
+	s.Equal([]byte("abc"), got)
+
+	ro := New[string, []byte](WithBytesMode(BytesReadOnly))
+	ro.Set("abc", make([]byte, 3, 8))
+	ro.Snapshot().Range(func(key string, value []byte) bool {
+		s.Equal(3, cap(value), "Views are capped at their length")
+		return true
+	})
+}
+
+// TestBytesAsIs verifies that the default leaves []byte values alone
+func (s *BytesTestSuite) TestBytesAsIs() {
+	c := New[string, []byte]()
+	payload := make([]byte, 3, 8)
+	s.Require().NoError(c.Set("a", payload))
+	value, ok := c.Peek("a")
+	s.True(ok)
+	s.Equal(8, cap(value))
+	s.Equal(BytesAsIs.String(), "as-is")
+}
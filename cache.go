@@ -0,0 +1,288 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Mode describes how a Cache obtains values that are not cached.
+type Mode int
+
+const (
+	// ReadThrough caches load missing keys through the loader registered
+	// with WithLoader.
+	ReadThrough Mode = iota + 1
+	// CacheAside caches only hold what callers Set; Get reports misses
+	// with ErrNotCached.
+	CacheAside
+)
+
+// String returns a human-readable name for the mode.
+func (m Mode) String() string {
+	switch m {
+	case ReadThrough:
+		return "read-through"
+	case CacheAside:
+		return "cache-aside"
+	default:
+		return "unknown"
+	}
+}
+
+// Cache is an independent cache instance for values of type V keyed by K.
+// Unlike the package-level functions it has its own storage, statistics and
+// configuration. A Cache must be created with New and is safe for
+// concurrent use.
+type Cache[K comparable, V any] struct {
+	s         *store
+	valueType reflect.Type
+	loader    weightedGetter[K, V]
+	parent    *Cache[K, V]
+	opts      options
+
+	nsMu       sync.Mutex
+	namespaces map[string]*Cache[K, V]
+}
+
+// New creates a Cache. When a loader is registered with WithLoader the
+// cache operates in read-through mode, otherwise in cache-aside mode.
+//
+// New panics if the loader passed to WithLoader or the parent passed to
+// WithParent does not match K and V.
+func New[K comparable, V any](opts ...Option) *Cache[K, V] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return newCache[K, V](o)
+}
+
+func newCache[K comparable, V any](o options) *Cache[K, V] {
+	var zero V
+	c := &Cache[K, V]{
+		s:         newStore(),
+		valueType: getTypeOf(zero),
+		opts:      o,
+	}
+	switch loader := o.loader.(type) {
+	case nil:
+	case func(K) (V, error):
+		c.loader = unweighted(loader)
+	case func(K) (V, int64, error):
+		c.loader = loader
+	default:
+		panic(fmt.Sprintf("cache: loader of type %T does not match Cache[%v, %v]", o.loader, getTypeOf(*new(K)), c.valueType))
+	}
+	if o.parent != nil {
+		c.setParent(o.parent)
+	}
+	c.s.settings.Store(newSettings(o))
+	c.s.newBackend = o.newBackend
+	if o.initialCapacity > 0 {
+		c.s.initialCapacity = o.initialCapacity
+		c.s.entries = newEntryAllocator(o.initialCapacity)
+	}
+	if o.remoteWrites != RemoteLocalOnly {
+		c.s.remote = o.remote
+	}
+	c.s.remoteWrites = o.remoteWrites
+	if o.invalidation != nil {
+		c.s.invalidation = *o.invalidation
+	}
+	c.s.keyPrefix = o.keyPrefix
+	if c.s.remote != nil && o.writeBehind != nil {
+		c.s.writeBehind = newWriteBehind(c.s, *o.writeBehind)
+	}
+	c.s.persistOnShutdown = o.persistOnShutdown
+	if o.spillover != nil {
+		c.s.spiller = newSpiller(*o.spillover)
+	}
+	if o.absentFilter != nil {
+		c.s.absent.Store(newAbsentFilter(*o.absentFilter, c.s.now()))
+	}
+	if o.auditSize > 0 {
+		c.s.auditLog.Store(newAuditLog(o.auditSize))
+	}
+	if o.backpressure != nil {
+		c.s.pressure.Store(newPressureMonitor(*o.backpressure, c.s.now()))
+	}
+	if o.adaptive != nil {
+		c.s.tuner.Store(newSegmentTuner(*o.adaptive, o.hotFraction))
+	}
+	if o.memoryPressure != nil {
+		c.s.startMemoryMonitor(*o.memoryPressure, readHeap)
+	}
+	if o.janitorInterval > 0 {
+		c.s.startJanitor(o.janitorInterval)
+	}
+	if o.trackFrequency {
+		c.s.trackFrequency(o.maxEntries)
+	}
+	if o.frequencies != nil {
+		c.s.startSketchSaver(*o.frequencies, o.maxEntries)
+	}
+	if o.remote != nil && o.warmup > 0 {
+		c.s.trackFrequency(o.warmup)
+		c.s.warmup = o.warmup
+		go func() {
+			if _, err := c.Warm(context.Background()); err != nil {
+				c.s.emit(Event{Kind: EventStoreError, Type: c.valueType, Err: err})
+			}
+		}()
+	}
+	return c
+}
+
+// Mode reports whether the cache is read-through or cache-aside.
+func (c *Cache[K, V]) Mode() Mode {
+	if c.loader != nil {
+		return ReadThrough
+	}
+	return CacheAside
+}
+
+// Get returns the value cached for key. In read-through mode a miss is
+// loaded through the registered loader, with concurrent misses for the same
+// key coalesced into a single call. In cache-aside mode a miss returns
+// ErrNotCached. With WithParent, the parent's entry is served on a local
+// miss.
+func (c *Cache[K, V]) Get(key K) (V, error) {
+	if err := c.s.checkKey(key); err != nil {
+		var zero V
+		return zero, err
+	}
+	if c.loader != nil {
+		if _, local := peek[K, V](c.s, key); !local && c.parent != nil {
+			if value, ok := c.fromParent(key); ok {
+				return value, nil
+			}
+		}
+		return load(c.s, key, c.loader, options{})
+	}
+
+	value, ok := cached[K, V](c.s, key, true)
+	if !ok && c.parent != nil {
+		if value, ok = c.fromParent(key); ok {
+			return value, nil
+		}
+	}
+	if !ok {
+		c.s.recordMiss(c.valueType, key)
+		return value, ErrNotCached
+	}
+	c.s.recordHit(c.valueType, key)
+	if c.s.cfg().strict {
+		c.s.checkKeyUnchanged(c.valueType, key)
+	}
+	return value, nil
+}
+
+// Peek returns the cached value for key without loading it and without
+// affecting statistics. The boolean reports whether an entry is present,
+// here or, with WithParent, in the parent.
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+	value, ok := peek[K, V](c.s, key)
+	if !ok && c.parent != nil {
+		return c.parent.Peek(key)
+	}
+	return value, ok
+}
+
+// Set stores value for key, replacing any existing entry. With
+// WithWriteBehind the value is also queued for the backing store, and with
+// the WriteThrough parent policy it is also set in the parent. WithTTL,
+// WithExpireAt, WithTags and WithPriority apply to the entry; other options
+// are ignored. It returns ErrFrozen if the cache is frozen.
+func (c *Cache[K, V]) Set(key K, value V, opts ...Option) error {
+	if c.s.frozen.Load() {
+		return ErrFrozen
+	}
+	if err := c.s.checkKey(key); err != nil {
+		return err
+	}
+	var call options
+	for _, opt := range opts {
+		opt(&call)
+	}
+	e := c.s.newEntry(c.valueType, value)
+	if !c.s.applyCall(c.valueType, e, call) {
+		// Already expired: drop the previous value instead
+		c.s.delete(c.valueType, key)
+		if c.writesThrough() {
+			return c.parent.Set(key, value, opts...)
+		}
+		return nil
+	}
+	version := e.version
+	c.s.swap(c.valueType, key, e)
+	if c.s.cfg().strict {
+		c.s.rememberKey(c.valueType, key)
+	}
+	if c.s.writeBehind != nil {
+		c.s.queueWrite(c.valueType, key, value, version)
+	}
+	if c.writesThrough() {
+		return c.parent.Set(key, value, opts...)
+	}
+	return nil
+}
+
+// SetIfAbsent stores value for key unless a live entry is already cached,
+// and reports whether it did. The check and the write are atomic, so of
+// several concurrent calls for the same key exactly one succeeds, which
+// makes the cache usable for deduplication. It takes the same options as
+// Set. It reports false if the cache is frozen.
+func (c *Cache[K, V]) SetIfAbsent(key K, value V, opts ...Option) bool {
+	if c.s.frozen.Load() || !c.s.usableKey(key) {
+		return false
+	}
+	var call options
+	for _, opt := range opts {
+		opt(&call)
+	}
+	e := c.s.newEntry(c.valueType, value)
+	version := e.version
+	if !c.s.applyCall(c.valueType, e, call) || !c.s.add(c.valueType, key, e) {
+		return false
+	}
+	if c.s.writeBehind != nil {
+		c.s.queueWrite(c.valueType, key, value, version)
+	}
+	return true
+}
+
+// Delete removes the entry for key, if any. A load of key already in
+// progress still returns its result to its callers, but the result is not
+// cached and later calls load the value afresh. With the WriteThrough
+// parent policy the parent's entry is deleted too. WithReason is the only
+// option honored.
+func (c *Cache[K, V]) Delete(key K, opts ...Option) {
+	if !c.s.usableKey(key) {
+		return
+	}
+	c.s.audit(AuditDelete, c.valueType, key, nil, reasonOf(opts))
+	c.s.delete(c.valueType, key)
+	if c.writesThrough() {
+		c.parent.Delete(key, opts...)
+	}
+}
+
+// Clear removes every entry from the cache. Like Delete, it keeps the
+// results of loads already in progress out of the cache. WithReason is the
+// only option honored.
+func (c *Cache[K, V]) Clear(opts ...Option) {
+	c.s.audit(AuditClear, nil, nil, nil, reasonOf(opts))
+	c.s.clear()
+}
+
+// Close is Shutdown without a deadline.
+func (c *Cache[K, V]) Close() error {
+	return c.Shutdown(context.Background())
+}
+
+// Stats returns a summary of the cache.
+func (c *Cache[K, V]) Stats() Statistics {
+	return c.s.stats()
+}
@@ -0,0 +1,355 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheTestSuite struct {
+	suite.Suite
+	callCount atomic.Int32
+}
+
+func TestCacheSuite(t *testing.T) {
+	suite.Run(t, new(CacheTestSuite))
+}
+
+// SetupTest runs before each test
+func (s *CacheTestSuite) SetupTest() {
+	s.callCount.Store(0)
+}
+
+// TestReadThroughUsesRegisteredLoader verifies that Get loads misses through WithLoader
+func (s *CacheTestSuite) TestReadThroughUsesRegisteredLoader() {
+	c := New[int, string](WithLoader(func(id int) (string, error) {
+		s.callCount.Add(1)
+		return "user", nil
+	}))
+	s.Equal(ReadThrough, c.Mode())
+
+	for i := 0; i < 3; i++ {
+		result, err := c.Get(1)
+		s.NoError(err)
+		s.Equal("user", result)
+	}
+	s.Equal(int32(1), s.callCount.Load(), "Loader should only be called once")
+
+	stats := c.Stats()
+	s.Equal(uint64(2), stats.Hits)
+	s.Equal(uint64(1), stats.Misses)
+	s.Equal(1, stats.Entries)
+}
+
+// TestCacheAsideReportsMisses verifies Set/Get/Delete without a loader
+func (s *CacheTestSuite) TestCacheAsideReportsMisses() {
+	c := New[string, int]()
+	s.Equal(CacheAside, c.Mode())
+
+	_, err := c.Get("answer")
+	s.ErrorIs(err, ErrNotCached)
+
+	c.Set("answer", 42)
+	result, err := c.Get("answer")
+	s.NoError(err)
+	s.Equal(42, result)
+
+	c.Delete("answer")
+	_, err = c.Get("answer")
+	s.ErrorIs(err, ErrNotCached)
+
+	stats := c.Stats()
+	s.Equal(uint64(1), stats.Hits)
+	s.Equal(uint64(2), stats.Misses)
+}
+
+// TestSetOverridesLoadedValue verifies that Set works in read-through mode too
+func (s *CacheTestSuite) TestSetOverridesLoadedValue() {
+	c := New[int, string](WithLoader(func(id int) (string, error) {
+		s.callCount.Add(1)
+		return "loaded", nil
+	}))
+
+	c.Set(1, "manual")
+	result, err := c.Get(1)
+	s.NoError(err)
+	s.Equal("manual", result)
+	s.Equal(int32(0), s.callCount.Load())
+
+	c.Clear()
+	result, err = c.Get(1)
+	s.NoError(err)
+	s.Equal("loaded", result)
+	s.Equal(int32(1), s.callCount.Load())
+}
+
+// TestInstancesAreIsolated verifies that instances don't share storage with each other or the global cache
+func (s *CacheTestSuite) TestInstancesAreIsolated() {
+	a := New[int, string]()
+	b := New[int, string]()
+
+	a.Set(1, "a")
+	_, found := b.Peek(1)
+	s.False(found, "Instances should not share entries")
+	_, found = Peek[int, string](1)
+	s.False(found, "Instances should not share entries with the global cache")
+}
+
+// TestLoaderErrorsAreNotCached verifies error handling in read-through mode
+func (s *CacheTestSuite) TestLoaderErrorsAreNotCached() {
+	errBackend := errors.New("backend unavailable")
+	c := New[int, string](WithLoader(func(id int) (string, error) {
+		if s.callCount.Add(1) == 1 {
+			return "", errBackend
+		}
+		return "ok", nil
+	}))
+
+	_, err := c.Get(1)
+	s.ErrorIs(err, errBackend)
+
+	result, err := c.Get(1)
+	s.NoError(err)
+	s.Equal("ok", result)
+	s.Equal(int32(2), s.callCount.Load())
+}
+
+// TestNilCachingOption verifies WithNilCaching(false) on an instance
+func (s *CacheTestSuite) TestNilCachingOption() {
+	c := New[int, *string](
+		WithNilCaching(false),
+		WithLoader(func(id int) (*string, error) {
+			s.callCount.Add(1)
+			return nil, nil
+		}),
+	)
+
+	_, err := c.Get(1)
+	s.NoError(err)
+	_, err = c.Get(1)
+	s.NoError(err)
+	s.Equal(int32(2), s.callCount.Load(), "Nil results should not be cached")
+}
+
+// TestMismatchedLoaderPanics verifies that New rejects a loader of the wrong type
+func (s *CacheTestSuite) TestMismatchedLoaderPanics() {
+	s.Panics(func() {
+		New[int, string](WithLoader(func(id string) (string, error) {
+			return id, nil
+		}))
+	})
+}
+
+// TestDeleteDuringLoad verifies that a load racing with Delete is not cached
+func (s *CacheTestSuite) TestDeleteDuringLoad() {
+	started, release := make(chan struct{}), make(chan struct{})
+	c := New[int, int32](WithLoader(func(id int) (int32, error) {
+		n := s.callCount.Add(1)
+		if n == 1 {
+			close(started)
+			<-release
+		}
+		return n, nil
+	}))
+
+	done := make(chan int32)
+	go func() {
+		value, _ := c.Get(1)
+		done <- value
+	}()
+	<-started
+	c.Delete(1)
+
+	// A Get after the Delete must not join the outdated load
+	fresh, err := c.Get(1)
+	s.NoError(err)
+	s.Equal(int32(2), fresh)
+
+	close(release)
+	s.Equal(int32(1), <-done, "The outdated load still answers its caller")
+	cached, _ := c.Peek(1)
+	s.Equal(int32(2), cached, "The outdated load should not overwrite the fresh value")
+}
+
+// TestClearDuringLoad verifies that Clear keeps in-flight loads out of the cache
+func (s *CacheTestSuite) TestClearDuringLoad() {
+	started, release := make(chan struct{}), make(chan struct{})
+	c := New[int, string](WithLoader(func(id int) (string, error) {
+		close(started)
+		<-release
+		return "stale", nil
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = c.Get(1)
+	}()
+	<-started
+	c.Clear()
+	close(release)
+	<-done
+
+	_, found := c.Peek(1)
+	s.False(found, "A load started before Clear should not resurrect its key")
+}
+
+// TestTTLExpiresEntries verifies WithTTL on an instance
+func (s *CacheTestSuite) TestTTLExpiresEntries() {
+	clock := NewFakeClock(time.Now())
+	c := New[int, string](
+		WithClock(clock),
+		WithTTL(20*time.Millisecond),
+		WithLoader(func(id int) (string, error) {
+			s.callCount.Add(1)
+			return "value", nil
+		}),
+	)
+
+	_, err := c.Get(1)
+	s.NoError(err)
+	_, err = c.Get(1)
+	s.NoError(err)
+	s.Equal(int32(1), s.callCount.Load())
+
+	clock.Advance(30 * time.Millisecond)
+	s.Equal(0, c.Stats().Entries, "Expired entries should not be counted")
+
+	_, err = c.Get(1)
+	s.NoError(err)
+	s.Equal(int32(2), s.callCount.Load(), "Expired entry should be reloaded")
+}
+
+// TestClockDecidesExpiration verifies that expiration follows the clock only
+func (s *CacheTestSuite) TestClockDecidesExpiration() {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := New[int, string](WithClock(clock), WithTTL(time.Hour))
+	c.Set(1, "value")
+
+	clock.Advance(time.Hour - time.Nanosecond)
+	_, found := c.Peek(1)
+	s.True(found, "Entry should be served until its TTL has elapsed")
+
+	snap := c.Snapshot()
+	clock.Advance(time.Nanosecond)
+	_, found = c.Peek(1)
+	s.False(found, "Entry should expire exactly after its TTL")
+	_, found = snap.Get(1)
+	s.True(found, "Snapshots keep the time they were taken at")
+}
+
+// TestSetWithOptions verifies the per-entry options of Set
+func (s *CacheTestSuite) TestSetWithOptions() {
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := New[string, string](WithClock(clock), WithTTL(time.Hour))
+	c.Set("token", "abc", WithExpireAt(clock.Now().Add(time.Minute)))
+	c.Set("session", "def", WithTTL(2*time.Minute))
+
+	clock.Advance(time.Minute)
+	_, found := c.Peek("token")
+	s.False(found)
+	_, found = c.Peek("session")
+	s.True(found)
+
+	c.Set("session", "expired", WithExpireAt(clock.Now().Add(-time.Second)))
+	_, found = c.Peek("session")
+	s.False(found, "Setting an expired value must not leave the previous one behind")
+}
+
+// TestSetIfAbsent verifies that only the first of concurrent writers stores
+// its value
+func (s *CacheTestSuite) TestSetIfAbsent() {
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := New[string, int](WithClock(clock), WithTTL(time.Minute))
+
+	var wg sync.WaitGroup
+	var stored atomic.Int32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if c.SetIfAbsent("job", i) {
+				stored.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	s.Equal(int32(1), stored.Load())
+
+	clock.Advance(time.Minute)
+	s.True(c.SetIfAbsent("job", 100), "Expired entries count as absent")
+	value, _ := c.Peek("job")
+	s.Equal(100, value)
+}
+
+// TestCoalescingPerInstance verifies that instances coalesce loads on their
+// own, so clearing one does not affect the loads of another
+func (s *CacheTestSuite) TestCoalescingPerInstance() {
+	release := make(chan struct{})
+	newCounted := func(calls *atomic.Int32) *Cache[string, int] {
+		return New[string, int](WithLoader(func(string) (int, error) {
+			calls.Add(1)
+			<-release
+			return 1, nil
+		}))
+	}
+	var callsA, callsB atomic.Int32
+	a, b := newCounted(&callsA), newCounted(&callsB)
+
+	var wg sync.WaitGroup
+	get := func(c *Cache[string, int]) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = c.Get("key")
+		}()
+	}
+	get(a)
+	get(b)
+	s.Eventually(func() bool {
+		return callsA.Load() == 1 && callsB.Load() == 1
+	}, time.Second, time.Millisecond, "Each instance runs its own loader")
+
+	a.Clear()
+	get(a)
+	get(b)
+	s.Eventually(func() bool {
+		return callsA.Load() == 2
+	}, time.Second, time.Millisecond, "Clear makes later loads start afresh")
+	s.Eventually(func() bool {
+		return b.Stats().Coalesced == 0 && b.Stats().Misses == 2
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	s.Equal(int32(1), callsB.Load(), "Clearing another instance must not split loads")
+	s.Equal(uint64(1), b.Stats().Coalesced)
+}
+
+// TestLockKey verifies that LockKey serializes callers per key only
+func (s *CacheTestSuite) TestLockKey() {
+	c := New[string, int]()
+	s.Require().NoError(c.LockKey("user"))
+
+	acquired := make(chan struct{})
+	go func() {
+		s.NoError(c.LockKey("user"))
+		close(acquired)
+		c.UnlockKey("user")
+	}()
+	s.Require().NoError(c.LockKey("other"), "Other keys are not blocked")
+	c.UnlockKey("other")
+
+	select {
+	case <-acquired:
+		s.Fail("The key was locked twice")
+	case <-time.After(20 * time.Millisecond):
+	}
+	c.UnlockKey("user")
+	<-acquired
+
+	s.Panics(func() { c.UnlockKey("user") })
+}
@@ -0,0 +1,192 @@
+// Package cachebench simulates traffic against a cache so that policy
+// changes, such as a different eviction limit, TTL or coalescing window,
+// can be evaluated by numbers rather than by intuition. Keys are drawn
+// from a Zipf popularity distribution and the simulated origin has a
+// configurable latency and error rate:
+//
+//	report, err := cachebench.Run(ctx, cachebench.Config{
+//		Keys:      100_000,
+//		ZipfS:     1.1,
+//		Requests:  1_000_000,
+//		Latency:   5 * time.Millisecond,
+//		ErrorRate: 0.01,
+//	}, cache.WithMaxEntries(10_000))
+//	fmt.Println(report)
+package cachebench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alexanderbotero/cache"
+)
+
+// ErrOrigin is returned by the simulated origin for the share of calls set
+// by Config.ErrorRate.
+var ErrOrigin = errors.New("cachebench: simulated origin failure")
+
+// Config describes the simulated traffic. Zero fields take their defaults.
+type Config struct {
+	// Keys is the number of distinct keys. Default 10,000.
+	Keys int
+	// ZipfS is the exponent of the Zipf distribution of key popularity.
+	// It must be greater than 1; larger values concentrate the traffic on
+	// fewer keys. Zero draws keys uniformly.
+	ZipfS float64
+	// Workers is the number of concurrent callers. Default GOMAXPROCS.
+	Workers int
+	// Requests is the number of lookups to make. Default 100,000.
+	Requests int
+	// Duration, if positive, stops the run after that long even if fewer
+	// lookups were made.
+	Duration time.Duration
+	// Latency is how long every origin call takes, plus a uniformly
+	// random extra of up to Jitter.
+	Latency time.Duration
+	Jitter  time.Duration
+	// ErrorRate is the share of origin calls failing with ErrOrigin.
+	ErrorRate float64
+	// Seed seeds the key sequences, so runs with the same seed make the
+	// same lookups. Default 1.
+	Seed int64
+}
+
+// Report summarizes a run.
+type Report struct {
+	// Requests is the number of lookups made.
+	Requests uint64
+	// Errors is the number of lookups that returned an error.
+	Errors uint64
+	// OriginCalls is the number of calls that reached the origin.
+	OriginCalls uint64
+	// HitRatio is the share of lookups served from the cache.
+	HitRatio float64
+	// Elapsed is how long the run took.
+	Elapsed time.Duration
+	// OriginQPS is the rate of origin calls, the load the cache lets
+	// through.
+	OriginQPS float64
+	// Stats are the statistics of the cache at the end of the run.
+	Stats cache.Statistics
+}
+
+// String returns a one-line summary of the report.
+func (r Report) String() string {
+	return fmt.Sprintf("%d requests in %v: hit ratio %.2f%%, %d origin calls (%.0f/s), %d errors",
+		r.Requests, r.Elapsed.Round(time.Millisecond), 100*r.HitRatio, r.OriginCalls, r.OriginQPS, r.Errors)
+}
+
+// Run simulates the traffic described by cfg against a new cache of int
+// keys and values configured with opts, whose loader is the simulated
+// origin. It stops early with ctx.Err() if ctx is done.
+func Run(ctx context.Context, cfg Config, opts ...cache.Option) (Report, error) {
+	if cfg.ZipfS != 0 && cfg.ZipfS <= 1 {
+		return Report{}, fmt.Errorf("cachebench: ZipfS must be greater than 1, got %v", cfg.ZipfS)
+	}
+	if cfg.ErrorRate < 0 || cfg.ErrorRate > 1 {
+		return Report{}, fmt.Errorf("cachebench: ErrorRate must be between 0 and 1, got %v", cfg.ErrorRate)
+	}
+	if cfg.Keys <= 0 {
+		cfg.Keys = 10_000
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.GOMAXPROCS(0)
+	}
+	if cfg.Requests <= 0 {
+		cfg.Requests = 100_000
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = 1
+	}
+
+	o := &origin{cfg: cfg, rand: rand.New(rand.NewSource(cfg.Seed))}
+	c := cache.New[int, int](append(opts, cache.WithLoader(o.load))...)
+	defer c.Close()
+
+	run := ctx
+	if cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		run, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
+	var requests, errs atomic.Uint64
+	remaining := int64(cfg.Requests)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			next := keys(cfg, cfg.Seed+int64(w))
+			for atomic.AddInt64(&remaining, -1) >= 0 && run.Err() == nil {
+				if _, err := c.Get(next()); err != nil {
+					errs.Add(1)
+				}
+				requests.Add(1)
+			}
+		}(w)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	stats := c.Stats()
+	report := Report{
+		Requests:    requests.Load(),
+		Errors:      errs.Load(),
+		OriginCalls: o.calls.Load(),
+		Elapsed:     elapsed,
+		Stats:       stats,
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		report.HitRatio = float64(stats.Hits) / float64(lookups)
+	}
+	if elapsed > 0 {
+		report.OriginQPS = float64(report.OriginCalls) / elapsed.Seconds()
+	}
+	return report, ctx.Err()
+}
+
+// keys returns a function drawing keys as described by cfg.
+func keys(cfg Config, seed int64) func() int {
+	r := rand.New(rand.NewSource(seed))
+	if cfg.ZipfS == 0 {
+		return func() int { return r.Intn(cfg.Keys) }
+	}
+	zipf := rand.NewZipf(r, cfg.ZipfS, 1, uint64(cfg.Keys-1))
+	return func() int { return int(zipf.Uint64()) }
+}
+
+// origin is the simulated source of the values.
+type origin struct {
+	cfg   Config
+	calls atomic.Uint64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func (o *origin) load(key int) (int, error) {
+	o.calls.Add(1)
+	o.mu.Lock()
+	jitter := time.Duration(0)
+	if o.cfg.Jitter > 0 {
+		jitter = time.Duration(o.rand.Int63n(int64(o.cfg.Jitter)))
+	}
+	fail := o.rand.Float64() < o.cfg.ErrorRate
+	o.mu.Unlock()
+
+	if d := o.cfg.Latency + jitter; d > 0 {
+		time.Sleep(d)
+	}
+	if fail {
+		return 0, ErrOrigin
+	}
+	return key, nil
+}
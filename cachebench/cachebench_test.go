@@ -0,0 +1,85 @@
+package cachebench_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alexanderbotero/cache"
+	"github.com/alexanderbotero/cache/cachebench"
+)
+
+type CachebenchTestSuite struct {
+	suite.Suite
+}
+
+func TestCachebenchSuite(t *testing.T) {
+	suite.Run(t, new(CachebenchTestSuite))
+}
+
+// TestRunReportsHitRatio verifies that a run makes the requested lookups
+// and reaches the origin at most once per key when nothing is evicted
+func (s *CachebenchTestSuite) TestRunReportsHitRatio() {
+	report, err := cachebench.Run(context.Background(), cachebench.Config{
+		Keys:     100,
+		ZipfS:    1.2,
+		Workers:  4,
+		Requests: 5000,
+	})
+	s.Require().NoError(err)
+	s.Equal(uint64(5000), report.Requests)
+	s.Zero(report.Errors)
+	s.LessOrEqual(report.OriginCalls, uint64(100))
+	s.Greater(report.HitRatio, 0.9)
+	s.Contains(report.String(), "5000 requests")
+}
+
+// TestRunComparesPolicies verifies that a smaller cache lets more calls
+// through to the origin
+func (s *CachebenchTestSuite) TestRunComparesPolicies() {
+	cfg := cachebench.Config{Keys: 1000, Workers: 1, Requests: 5000}
+	large, err := cachebench.Run(context.Background(), cfg)
+	s.Require().NoError(err)
+	small, err := cachebench.Run(context.Background(), cfg, cache.WithMaxEntries(10))
+	s.Require().NoError(err)
+	s.Greater(small.OriginCalls, large.OriginCalls)
+	s.Less(small.HitRatio, large.HitRatio)
+}
+
+// TestRunOriginFailures verifies that origin errors surface as lookup
+// errors
+func (s *CachebenchTestSuite) TestRunOriginFailures() {
+	report, err := cachebench.Run(context.Background(), cachebench.Config{
+		Keys:      10,
+		Requests:  200,
+		ErrorRate: 1,
+		Latency:   time.Microsecond,
+	})
+	s.Require().NoError(err)
+	s.Equal(report.Requests, report.Errors)
+	s.Equal(report.Requests, report.OriginCalls+report.Stats.Coalesced)
+}
+
+// TestRunValidatesConfig verifies that invalid distributions are rejected
+// and that the run stops with its context
+func (s *CachebenchTestSuite) TestRunValidatesConfig() {
+	_, err := cachebench.Run(context.Background(), cachebench.Config{ZipfS: 0.5})
+	s.Error(err)
+	_, err = cachebench.Run(context.Background(), cachebench.Config{ErrorRate: 2})
+	s.Error(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err := cachebench.Run(ctx, cachebench.Config{Requests: 1000})
+	s.ErrorIs(err, context.Canceled)
+	s.Zero(report.Requests)
+
+	report, err = cachebench.Run(context.Background(), cachebench.Config{
+		Requests: 1 << 30,
+		Duration: 20 * time.Millisecond,
+	})
+	s.NoError(err, "Reaching Duration is not an error")
+	s.Less(report.Requests, uint64(1<<30))
+}
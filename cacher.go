@@ -1,82 +1,308 @@
 package cache
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"reflect"
-	"sync"
-
-	"golang.org/x/sync/singleflight"
+	"time"
 )
 
-type store struct {
-	data  map[reflect.Type]map[any]any
-	mu    sync.RWMutex
-	group singleflight.Group
-}
-
-var cacheStore = &store{
-	data: make(map[reflect.Type]map[any]any),
-}
-
 // Get retrieves a value from cache or computes it using getterFunc.
 // It is thread-safe and handles concurrent access correctly.
 // Errors from getterFunc are not cached, allowing retries.
 //
+// If a cached entry is found to hold a value of the wrong type, it is
+// evicted, an EventCorruption event is emitted and the value is reloaded
+// through getterFunc, unless WithCorruptionPolicy says otherwise.
+//
+// Options adjust this call only: WithTTL, WithExpireAt, WithTags,
+// WithPriority, WithTimeout, WithForceRefresh and WithSkipCache are
+// honored, other options are ignored. Concurrent calls for the same key
+// share one getter call, whose result is stored with the options of the
+// call that started it.
+//
+//	user, err := cache.Get(id, loadUser, cache.WithTTL(time.Minute), cache.WithTags("users"))
+//
 // Returns an error if:
-//   - getterFunc is nil
-//   - getterFunc returns an error
-//   - cache corruption is detected
-func Get[K comparable, V any](key K, getterFunc func(K) (V, error)) (V, error) {
+//   - getterFunc is nil (ErrNilGetter)
+//   - key holds a slice, map or function (ErrUnhashableKey)
+//   - getterFunc returns an error (*LoadError wrapping it)
+//   - the key is known to be absent (*LoadError wrapping ErrNotFound, see
+//     WithAbsentFilter)
+//   - the value is not available within the WithTimeout duration (ErrTimeout)
+//   - the getter call is not allowed by WithRateLimit (ErrRateLimited)
+//   - cache corruption is detected in a freshly computed result, or in a
+//     cached one under CorruptionError (ErrCorruption)
+func Get[K comparable, V any](key K, getterFunc func(K) (V, error), opts ...Option) (V, error) {
+	if err := globalStore().checkKey(key); err != nil {
+		var zero V
+		return zero, err
+	}
+	var call options
+	for _, opt := range opts {
+		opt(&call)
+	}
+	s := globalStore()
+	if call.forceRefresh {
+		var zero V
+		if s.refreshThrottled(getTypeOf(zero), key) {
+			call.forceRefresh = false
+		} else {
+			s.audit(AuditRefresh, getTypeOf(zero), key, nil, call.reason)
+		}
+	}
+	return load(s, key, unweighted(getterFunc), call)
+}
+
+// Peek returns the cached value for key without calling any getter and
+// without affecting statistics. The boolean reports whether an entry is
+// present, which distinguishes a cached nil (nil, true) from a miss
+// (zero value, false).
+func Peek[K comparable, V any](key K) (V, bool) {
+	return peek[K, V](globalStore(), key)
+}
+
+// Delete removes the value of type V cached for key, if any. A getter
+// already running for key still returns its result to its callers, but the
+// result is not cached and later calls load the value afresh. WithReason
+// is the only option honored.
+func Delete[K comparable, V any](key K, opts ...Option) {
+	if !globalStore().usableKey(key) {
+		return
+	}
+	var zero V
+	s := globalStore()
+	s.audit(AuditDelete, getTypeOf(zero), key, nil, reasonOf(opts))
+	s.delete(getTypeOf(zero), key)
+}
+
+// Clear removes every value of every type from the package-level cache.
+// Like Delete, it keeps the results of getters already running out of the
+// cache. Settings and statistics are left untouched. WithReason is the only
+// option honored.
+func Clear(opts ...Option) {
+	s := globalStore()
+	s.audit(AuditClear, nil, nil, nil, reasonOf(opts))
+	s.clear()
+}
+
+// SetNilCaching controls whether nil results from a getter (nil pointers,
+// maps, slices, interfaces, ...) are cached. It is enabled by default.
+// When disabled, nil results are returned to the caller but not stored, so
+// the next Get for the same key calls the getter again.
+func SetNilCaching(enabled bool) {
+	globalStore().updateSettings(WithNilCaching(enabled))
+}
+
+// Reset empties the package-level cache and restores its defaults, undoing
+// SetDefaults, Configure and SetEventHandler and zeroing its statistics.
+// It is meant for tests and must not run concurrently with other uses of
+// the package-level functions.
+func Reset() {
+	globalStore().reset()
+}
+
+// load implements the read-through path shared by Get and Cache instances.
+// call holds the per-call options of Get.
+func load[K comparable, V any](s *store, key K, getterFunc weightedGetter[K, V], call options) (V, error) {
 	var zero V
 	if getterFunc == nil {
-		return zero, errors.New("getterFunc cannot be nil")
+		if s.cfg().strict {
+			panic(ErrNilGetter)
+		}
+		return zero, ErrNilGetter
 	}
 	// Get type safely
 	valueType := getTypeOf(zero)
+	useCached := !call.forceRefresh && !call.skipCache && !call.refresh
 
 	// Fast path: check if already cached
-	cacheStore.mu.RLock()
-	storedValue, keyExists := cacheStore.data[valueType][key]
-	if keyExists {
-		cacheStore.mu.RUnlock()
-		// Safe type assertion
-		if typedValue, ok := storedValue.(V); ok {
-			return typedValue, nil
+	if useCached {
+		s.injectEviction(valueType, key)
+		if e, value, keyExists := s.lookupValue(valueType, key); keyExists {
+			// Safe type assertion
+			if typedValue, ok := value.(V); ok {
+				s.recordHit(valueType, key)
+				if s.cfg().strict {
+					s.checkKeyUnchanged(valueType, key)
+				}
+				if shadow := s.cfg().shadow; shadow != nil && shadow.sampled() {
+					shadowRead(s, shadow, e, key, getterFunc, typedValue)
+				}
+				s.touch(e)
+				s.promote(e)
+				if e.dueForRefresh(s.now()) && !s.frozen.Load() {
+					refreshAhead(s, e, key, getterFunc)
+				}
+				return typedValue, nil
+			}
+			// This case indicates cache corruption (internal bug):
+			// drop the bad entry and fall through to the getter
+			if err := evictCorrupted[V](s, valueType, key, value); s.cfg().corruption == CorruptionError {
+				s.recordMiss(valueType, key)
+				return zero, err
+			}
+		}
+
+		// Keys known not to exist fail fast without reaching the origin
+		if filter := s.absent.Load(); filter != nil && filter.contains(valueType, key, s.now()) {
+			s.recordMiss(valueType, key)
+			return zero, s.loadError(valueType, key, ErrNotFound)
 		}
-		// This case indicates cache corruption (internal bug)
-		return zero, errors.New("cache corruption: stored value type mismatch")
 	}
-	cacheStore.mu.RUnlock()
+	return loadMiss(s, key, getterFunc, call, useCached)
+}
 
-	// Ensure the type exists
-	ensureType(valueType)
+// loadMiss is the part of load past the cache lookup. It is kept apart so
+// that hits do not pay for the state the getter call captures.
+func loadMiss[K comparable, V any](s *store, key K, getterFunc weightedGetter[K, V], call options, useCached bool) (V, error) {
+	var zero V
+	valueType := getTypeOf(zero)
+
+	// Concurrent loads of the same key of the same type are coalesced
+	group := groupFor[K](s, valueType)
+	ck := callKey[K]{key: key}
+	switch {
+	case call.refresh:
+		// Background refreshes are not lookups and are not counted
+		ck.refresh = true
+	case call.skipCache:
+		// Bypassing calls are not shared with anyone
+		group = nil
+	case call.forceRefresh:
+		// Forced refreshes must not join a flight that may serve the
+		// cached value
+		ck.refresh = true
+		s.recordMiss(valueType, key)
+	default:
+		s.recordMiss(valueType, key)
+	}
+	if _, nan := canonicalKey(key).(nanKey); nan {
+		// NaN keys would never be found again in the group
+		group = nil
+	}
 
-	// Create a unique singleflight key that combines type + key
-	// This ensures that different types don't collide
-	sfKey := fmt.Sprintf("%v:%v", valueType, key)
+	ttl := s.callTTL(valueType, call)
 
-	// Use singleflight to deduplicate concurrent calls
-	result, err, _ := cacheStore.group.Do(sfKey, func() (any, error) {
-		// Double-check: another goroutine might have cached while we were waiting
-		cacheStore.mu.RLock()
-		if storedValue, exists := cacheStore.data[valueType][key]; exists {
-			cacheStore.mu.RUnlock()
-			return storedValue, nil
+	result, err := run(call.ctx, s, valueType, group, ck, call.timeout, func(forget func()) (any, error) {
+		// Register the load so that a Delete or Clear racing with it keeps
+		// its result out of the cache
+		k := entryKey{valueType, canonicalKey(key)}
+		var f *flight
+		if !call.skipCache {
+			// A getter call that just completed serves bursts of misses,
+			// and another goroutine might have cached the value while we
+			// were waiting; both are checked while registering the load
+			var value any
+			var found bool
+			f, value, found = s.beginLoad(k, forget, useCached)
+			if found {
+				if _, ok := value.(V); ok {
+					return value, nil
+				}
+				if err := evictCorrupted[V](s, valueType, key, value); s.cfg().corruption == CorruptionError {
+					return nil, err
+				}
+				f, _, _ = s.beginLoad(k, forget, false)
+			}
+			defer s.endFlight(k, f)
+		}
+
+		if useCached && ttl >= 0 {
+			// Consult the backing store before calling the getter. This
+			// runs within the shared load, so concurrent misses of the key
+			// make a single request to the store
+			if s.remote != nil {
+				if stored, found := loadRemote[V](context.Background(), s, valueType, key); found {
+					s.recordRemoteHit(valueType)
+					if s.frozen.Load() {
+						return stored, nil
+					}
+					e := s.newEntry(valueType, stored)
+					if call.priority != PriorityNormal {
+						e.priority = call.priority
+					}
+					s.putFlight(k, f, e)
+					return stored, nil
+				}
+				// Another process may have found the key absent
+				if s.loadTombstone(valueType, key) {
+					return nil, s.loadError(valueType, key, ErrNotFound)
+				}
+			}
+		}
+
+		// Refreshes ahead of expiry may be coordinated across replicas
+		if call.refresh {
+			unlock, err := s.lockRefresh(valueType, key, ttl)
+			if err != nil {
+				return nil, err
+			}
+			defer unlock()
+		}
+
+		// The origin may only be reached so often
+		if serveStale, err := s.allowLoad(call, valueType); err != nil {
+			if serveStale {
+				if stale, ok := s.staleValue(valueType, key); ok {
+					if _, valid := stale.(V); valid {
+						return stale, nil
+					}
+				}
+			}
+			return nil, err
 		}
-		cacheStore.mu.RUnlock()
 
 		// Execute the getter (only ONE goroutine reaches here)
-		uncached, err := getterFunc(key)
+		getter := getterFunc
+		if faults := s.cfg().faults; faults != nil {
+			getter = withFaults(faults, getter)
+		}
+		start := time.Now()
+		uncached, cost, err := callGetter(s, getter, key)
+		s.recordLoad(valueType, time.Since(start), err)
 		if err != nil {
-			return nil, fmt.Errorf("cache getter failed for key %v: %w", key, err)
+			if filter := s.absent.Load(); filter != nil && !call.skipCache && errors.Is(err, ErrNotFound) {
+				filter.add(valueType, key, s.now())
+				s.storeTombstone(valueType, key)
+			}
+			return nil, s.loadError(valueType, key, err)
+		}
+		if s.cfg().strict {
+			checkDeterministic(s, valueType, key, getterFunc, uncached)
+		}
+		if f != nil {
+			s.rememberResult(k, f, uncached)
+		}
+
+		// Nil results are handed back but not stored when nil caching is
+		// off, zero values when WithZeroValueTTL is negative, and nothing
+		// is stored in frozen caches
+		zeroTTL, zero := s.zeroValueTTL(uncached)
+		if call.skipCache || (isNil(uncached) && s.cfg().skipNil) || (zero && zeroTTL < 0) || s.frozen.Load() {
+			return uncached, nil
 		}
 
 		// Cache the result
-		cacheStore.mu.Lock()
-		typeMapLocked := cacheStore.data[valueType]
-		typeMapLocked[key] = uncached
-		cacheStore.mu.Unlock()
+		e := s.newSizedEntry(valueType, uncached, cost)
+		if !s.applyCall(valueType, e, call) {
+			return uncached, nil
+		}
+		if zero {
+			s.limitExpiry(e, zeroTTL)
+		}
+		version := e.version
+		stored := s.putFlight(k, f, e)
+		if stored && s.cfg().strict {
+			s.rememberKey(valueType, key)
+		}
+		if stored && s.remote != nil {
+			if s.remoteWrites == RemoteWriteBehind && s.writeBehind != nil {
+				s.queueWrite(valueType, key, uncached, version)
+			} else {
+				storeRemote(s, valueType, key, uncached, e.ttl)
+			}
+		}
 
 		return uncached, nil
 	})
@@ -86,14 +312,122 @@ func Get[K comparable, V any](key K, getterFunc func(K) (V, error)) (V, error) {
 	}
 
 	// Final type assertion
-	typedValue, ok := result.(V)
+	typedValue, ok := s.cfg().bytesMode.serve(result).(V)
 	if !ok {
-		return zero, errors.New("cache corruption: stored value type mismatch")
+		return zero, s.reportCorruption(valueType, key, result)
 	}
 
 	return typedValue, nil
 }
 
+// run executes fn as the load of ck in group, shared with the concurrent
+// callers of the same key, or on its own when group is nil. fn receives
+// the function making later callers start a new load instead of joining
+// this one, or nil when the load is not shared. With a positive timeout it
+// stops waiting after that long and returns ErrTimeout, and if ctx ends
+// first it returns ctx.Err(); fn still runs to completion. ctx may be nil.
+func run[K comparable](ctx context.Context, s *store, valueType reflect.Type, group *callGroup[K], ck callKey[K], timeout time.Duration, fn func(forget func()) (any, error)) (any, error) {
+	var cancel <-chan struct{}
+	if ctx != nil {
+		cancel = ctx.Done()
+	}
+	wait := timeout > 0 || cancel != nil
+
+	var c *call
+	if group == nil {
+		if !wait {
+			return fn(nil)
+		}
+		c = &call{done: make(chan struct{})}
+		go func() {
+			c.val, c.err = fn(nil)
+			close(c.done)
+		}()
+	} else {
+		var leader bool
+		c, leader = group.join(ck)
+		if leader {
+			forget := func() { group.forget(ck, c) }
+			load := func() {
+				callers := group.do(ck, c, func() (any, error) {
+					return fn(forget)
+				})
+				s.settleLoad(valueType, callers)
+			}
+			if wait {
+				go load()
+			} else {
+				load()
+			}
+		}
+	}
+	if !wait {
+		<-c.done
+		return c.val, c.err
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-expired:
+		return nil, ErrTimeout
+	case <-cancel:
+		return nil, ctx.Err()
+	}
+}
+
+func peek[K comparable, V any](s *store, key K) (V, bool) {
+	return cached[K, V](s, key, false)
+}
+
+// cached returns the value cached for key, evicting it if it is corrupted.
+// When touch is set the access counts towards recency-based eviction.
+func cached[K comparable, V any](s *store, key K, touch bool) (V, bool) {
+	var zero V
+	if !s.usableKey(key) {
+		return zero, false
+	}
+	valueType := getTypeOf(zero)
+
+	e, value, exists := s.lookupValue(valueType, key)
+	if !exists {
+		return zero, false
+	}
+	typedValue, ok := value.(V)
+	if !ok {
+		_ = evictCorrupted[V](s, valueType, key, value)
+		return zero, false
+	}
+	if touch {
+		s.touch(e)
+		s.promote(e)
+	}
+	return typedValue, true
+}
+
+// evictCorrupted removes the entry for key if it still holds a value that is
+// not a V and reports the corruption, returning the error lookups fail
+// with under CorruptionError.
+func evictCorrupted[V any](s *store, valueType reflect.Type, key, corrupted any) error {
+	s.lock()
+	// Only delete if nobody replaced the entry with a valid value meanwhile
+	if current, ok := s.entryLocked(valueType, key); ok {
+		value, _ := current.get()
+		if _, valid := value.(V); !valid {
+			s.removeLocked(valueType, key, RemovalCorrupted)
+		}
+	}
+	s.mu.Unlock()
+
+	return s.reportCorruption(valueType, key, corrupted)
+}
+
 func getTypeOf[T any](zero T) reflect.Type {
 	typ := reflect.TypeOf(zero)
 	// If nil (interfaces or pointers), use alternative method
@@ -103,22 +437,17 @@ func getTypeOf[T any](zero T) reflect.Type {
 	return typ
 }
 
-func ensureType(valueType reflect.Type) {
-	// First check: fast read with RLock
-	cacheStore.mu.RLock()
-	_, ok := cacheStore.data[valueType]
-	cacheStore.mu.RUnlock()
-
-	// If it already exists, return immediately
-	if ok {
-		return
+// isNil reports whether v is nil or holds a nil pointer, map, slice,
+// channel, function or interface.
+func isNil(v any) bool {
+	if v == nil {
+		return true
 	}
-
-	// Second check: with Lock to avoid race condition
-	cacheStore.mu.Lock()
-	defer cacheStore.mu.Unlock()
-	// Check again in case another goroutine created it while we were waiting for the Lock
-	if _, ok := cacheStore.data[valueType]; !ok {
-		cacheStore.data[valueType] = make(map[any]any)
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Chan,
+		reflect.Func, reflect.Interface, reflect.UnsafePointer:
+		return rv.IsNil()
 	}
+	return false
 }
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -15,6 +17,7 @@ import (
 type CacherTestSuite struct {
 	suite.Suite
 	callCount atomic.Int32
+	clock     *FakeClock
 }
 
 func TestCacherSuite(t *testing.T) {
@@ -24,9 +27,9 @@ func TestCacherSuite(t *testing.T) {
 // SetupTest runs before each test
 func (s *CacherTestSuite) SetupTest() {
 	// Clean the cache before each test
-	cacheStore.mu.Lock()
-	cacheStore.data = make(map[reflect.Type]map[any]any)
-	cacheStore.mu.Unlock()
+	Reset()
+	s.clock = NewFakeClock(time.Unix(0, 0))
+	SetDefaults(WithClock(s.clock))
 
 	// Reset counter
 	s.callCount.Store(0)
@@ -35,9 +38,7 @@ func (s *CacherTestSuite) SetupTest() {
 // TearDownTest runs after each test
 func (s *CacherTestSuite) TearDownTest() {
 	// Explicit cache cleanup
-	cacheStore.mu.Lock()
-	cacheStore.data = make(map[reflect.Type]map[any]any)
-	cacheStore.mu.Unlock()
+	Reset()
 }
 
 // TestCacheCallsGetterOnlyOnce verifies that the getter is called only once
@@ -166,12 +167,27 @@ func (s *CacherTestSuite) TestCacheDoesNotCacheErrors() {
 	s.Equal(int32(3), s.callCount.Load(), "Getter should NOT be called because it's now cached")
 }
 
+// TestCacheGetterErrorIsTyped verifies that getter failures can be inspected with errors.Is/As
+func (s *CacherTestSuite) TestCacheGetterErrorIsTyped() {
+	errBackend := errors.New("backend unavailable")
+	getter := func(key int) (string, error) {
+		return "", errBackend
+	}
+
+	_, err := Get(7, getter)
+	s.ErrorIs(err, errBackend)
+
+	var loadErr *LoadError
+	s.Require().ErrorAs(err, &loadErr)
+	s.Equal(7, loadErr.Key)
+	s.Equal(errBackend, loadErr.Err)
+}
+
 // TestCacheWithNilGetterFunc verifies that it returns an error when getterFunc is nil
 func (s *CacherTestSuite) TestCacheWithNilGetterFunc() {
 	result, err := Get[int, string](1, nil)
-	s.Error(err)
+	s.ErrorIs(err, ErrNilGetter)
 	s.Equal("", result)
-	s.Contains(err.Error(), "getterFunc cannot be nil")
 }
 
 // TestCacheWithPointerTypes verifies that it works with pointer types
@@ -293,30 +309,496 @@ func (s *CacherTestSuite) TestCacheWithNilPointerReturned() {
 	s.Equal(int32(1), s.callCount.Load(), "Should not call again")
 }
 
+// TestPeekDistinguishesCachedNilFromAbsent verifies Peek's presence flag
+func (s *CacherTestSuite) TestPeekDistinguishesCachedNilFromAbsent() {
+	type Product struct {
+		SKU string
+	}
+
+	// Nothing cached yet
+	product, found := Peek[int, *Product](1)
+	s.False(found)
+	s.Nil(product)
+
+	_, err := Get(1, func(id int) (*Product, error) {
+		return nil, nil
+	})
+	s.NoError(err)
+
+	// Now a nil is cached and Peek reports it as present
+	product, found = Peek[int, *Product](1)
+	s.True(found, "A cached nil should be reported as present")
+	s.Nil(product)
+
+	// Peek does not call getters nor count as hit or miss
+	stats := Stats()
+	s.Equal(uint64(0), stats.Hits)
+	s.Equal(uint64(1), stats.Misses)
+}
+
+// TestStatsCountsNilEntries verifies that Stats reports nil entries separately
+func (s *CacherTestSuite) TestStatsCountsNilEntries() {
+	getter := func(id int) (*int, error) {
+		if id%2 == 0 {
+			return nil, nil
+		}
+		return &id, nil
+	}
+
+	for i := 1; i <= 4; i++ {
+		_, err := Get(i, getter)
+		s.NoError(err)
+	}
+	_, err := Get(1, getter)
+	s.NoError(err)
+
+	stats := Stats()
+	s.Equal(4, stats.Entries)
+	s.Equal(2, stats.NilEntries)
+	s.Equal(uint64(1), stats.Hits)
+	s.Equal(uint64(4), stats.Misses)
+}
+
+// TestStatsPerType verifies that Stats breaks the numbers down by value type
+func (s *CacherTestSuite) TestStatsPerType() {
+	for i := 0; i < 3; i++ {
+		_, err := Get(i, func(id int) (string, error) { return "user", nil })
+		s.NoError(err)
+	}
+	_, err := Get(0, func(id int) (string, error) { return "user", nil })
+	s.NoError(err)
+	_, err = Get(0, func(id int) (int, error) { return 1, nil })
+	s.NoError(err)
+
+	types := Stats().Types
+	s.Len(types, 2)
+	s.Equal(uint64(1), types["string"].Hits)
+	s.Equal(uint64(3), types["string"].Misses)
+	s.Equal(3, types["string"].Entries)
+	s.Equal(uint64(3), types["string"].Loads.Count)
+	s.Equal(1, types["int"].Entries)
+	s.Equal(uint64(1), types["int"].Loads.Count)
+
+	Reset()
+	s.Nil(Stats().Types)
+}
+
+// TestTTLDistribution verifies that Stats buckets entries by remaining TTL
+func (s *CacherTestSuite) TestTTLDistribution() {
+	getter := func(id int) (string, error) { return "value", nil }
+	_, _ = Get(1, getter, WithTTL(30*time.Second))
+	_, _ = Get(2, getter, WithTTL(2*time.Hour))
+	_, _ = Get(3, getter)
+	s.clock.Advance(25 * time.Second)
+
+	ttl := Stats().TTL
+	s.Equal([7]int{0, 1, 0, 0, 0, 1, 0}, ttl.Expiring)
+	s.Equal(0, ttl.Later)
+	s.Equal(1, ttl.NoExpiry)
+	s.Equal(10*time.Second, TTLBucketBounds()[1])
+}
+
+// TestStatsString verifies the human-readable report
+func (s *CacherTestSuite) TestStatsString() {
+	SetDefaults(WithFrequencyTracking())
+	for i := 0; i < 3; i++ {
+		_, err := Get("popular", func(key string) (int, error) { return 1, nil })
+		s.NoError(err)
+	}
+	_, err := Get(1, func(key int) (string, error) { return strings.Repeat("s", 2048), nil })
+	s.NoError(err)
+	SetDefaults(WithSizeEstimator(func(v any) int64 {
+		if str, ok := v.(string); ok {
+			return int64(len(str))
+		}
+		return 8
+	}))
+
+	report := Stats().String()
+	lines := strings.Split(strings.TrimSpace(report), "\n")
+	s.Len(lines, 5)
+	s.Equal("entries 2 (0 nil), 2.0 KiB, hit ratio 50.0% (2 hits, 2 misses)", lines[0])
+	s.Contains(lines[2], "never:2")
+	s.True(strings.HasPrefix(lines[3], "int: entries 1, 8 B, hit ratio 66.7% (2 hits, 1 misses), loads 1"))
+	s.Contains(lines[3], `hot [popular]`)
+	s.True(strings.HasPrefix(lines[4], "string: entries 1, 2.0 KiB, hit ratio 0.0% (0 hits, 1 misses)"))
+}
+
+// TestLatencyPercentiles verifies that load latency percentiles are
+// reported with bucket precision
+func (s *CacherTestSuite) TestLatencyPercentiles() {
+	var h latencyHistogram
+	for i := 0; i < 90; i++ {
+		h.observe(time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		h.observe(time.Second)
+	}
+
+	st := h.stats()
+	s.Equal(uint64(100), st.Count)
+	s.Equal(1024*time.Microsecond, st.P50)
+	s.Equal(1024*time.Microsecond, st.P90)
+	s.Equal(1<<20*time.Microsecond, st.P99)
+}
+
+// TestNilCachingDisabled verifies that nil results are not stored when disabled
+func (s *CacherTestSuite) TestNilCachingDisabled() {
+	SetNilCaching(false)
+
+	nilGetter := func(id int) (*string, error) {
+		s.callCount.Add(1)
+		return nil, nil
+	}
+
+	for i := 1; i <= 3; i++ {
+		result, err := Get(1, nilGetter)
+		s.NoError(err)
+		s.Nil(result)
+		s.Equal(int32(i), s.callCount.Load(), "Nil results should not be cached")
+	}
+
+	_, found := Peek[int, *string](1)
+	s.False(found)
+	s.Equal(0, Stats().Entries)
+}
+
+// TestConfigureTTLPerType verifies that Configure sets the TTL of one type only
+func (s *CacherTestSuite) TestConfigureTTLPerType() {
+	Configure[string](WithTTL(20 * time.Millisecond))
+
+	var stringCalls, intCalls atomic.Int32
+	stringGetter := func(key int) (string, error) {
+		stringCalls.Add(1)
+		return "value", nil
+	}
+	intGetter := func(key int) (int, error) {
+		intCalls.Add(1)
+		return 42, nil
+	}
+
+	_, err := Get(1, stringGetter)
+	s.NoError(err)
+	_, err = Get(1, intGetter)
+	s.NoError(err)
+
+	s.clock.Advance(30 * time.Millisecond)
+
+	// The string entry expired, the int entry did not
+	_, found := Peek[int, string](1)
+	s.False(found, "Expired entries should not be served")
+	_, err = Get(1, stringGetter)
+	s.NoError(err)
+	s.Equal(int32(2), stringCalls.Load())
+
+	_, err = Get(1, intGetter)
+	s.NoError(err)
+	s.Equal(int32(1), intCalls.Load(), "Types without configuration never expire")
+}
+
+// TestConfigureMaxEntriesPerType verifies that a per-type limit only evicts that type
+func (s *CacherTestSuite) TestConfigureMaxEntriesPerType() {
+	Configure[string](WithMaxEntries(2))
+
+	for i := 0; i < 5; i++ {
+		_, err := Get(i, func(key int) (string, error) { return "s", nil })
+		s.NoError(err)
+		_, err = Get(i, func(key int) (int, error) { return key, nil })
+		s.NoError(err)
+	}
+
+	stats := Stats()
+	s.Equal(7, stats.Entries, "2 strings and 5 ints should be cached")
+	s.Equal(uint64(3), stats.Evictions)
+	_, found := Peek[int, string](4)
+	s.True(found, "Most recent string should be kept")
+}
+
+// TestConfigureCodecPerType verifies codec resolution per type
+func (s *CacherTestSuite) TestConfigureCodecPerType() {
+	type customCodec struct{ JSONCodec }
+	Configure[string](WithCodec(customCodec{}))
+
+	s.Equal(customCodec{}, globalStore().codecFor(getTypeOf("")))
+	s.Equal(JSONCodec{}, globalStore().codecFor(getTypeOf(0)))
+}
+
+// TestSetDefaultsTTL verifies that SetDefaults applies a TTL to every type
+func (s *CacherTestSuite) TestSetDefaultsTTL() {
+	SetDefaults(WithTTL(20 * time.Millisecond))
+
+	_, err := Get(1, func(key int) (string, error) { return "value", nil })
+	s.NoError(err)
+	_, found := Peek[int, string](1)
+	s.True(found)
+
+	s.clock.Advance(30 * time.Millisecond)
+
+	_, found = Peek[int, string](1)
+	s.False(found, "Entries should expire after the default TTL")
+}
+
+// TestSetDefaultsEnforcesLimits verifies that a lowered limit evicts at once
+func (s *CacherTestSuite) TestSetDefaultsEnforcesLimits() {
+	for i := 0; i < 5; i++ {
+		_, err := Get(i, func(key int) (int, error) { return key, nil })
+		s.NoError(err)
+	}
+
+	SetDefaults(WithMaxEntries(2))
+
+	stats := Stats()
+	s.Equal(2, stats.Entries)
+	s.Equal(uint64(3), stats.Evictions)
+}
+
+// TestMaxEntriesPerType verifies that one type cannot take the whole cache
+// and that Configure overrides the per-type default
+func (s *CacherTestSuite) TestMaxEntriesPerType() {
+	SetDefaults(WithMaxEntriesPerType(3))
+	Configure[string](WithMaxEntries(5))
+	for i := 0; i < 10; i++ {
+		_, err := Get(i, func(key int) (int, error) { return key, nil })
+		s.NoError(err)
+		_, err = Get(i, func(key int) (string, error) { return "value", nil })
+		s.NoError(err)
+	}
+	_, err := Get(0, func(key int) (float64, error) { return 1, nil })
+	s.NoError(err)
+
+	types := Stats().Types
+	s.Equal(3, types["int"].Entries)
+	s.Equal(5, types["string"].Entries)
+	s.Equal(1, types["float64"].Entries, "Other types keep their room")
+
+	SetDefaults(WithMaxEntriesPerType(1))
+	s.Equal(1, Stats().Types["int"].Entries, "A lowered limit evicts at once")
+}
+
+// TestPriorityEviction verifies that low-priority entries are evicted
+// before normal and high-priority ones, whatever their recency
+func (s *CacherTestSuite) TestPriorityEviction() {
+	SetDefaults(WithMaxEntries(3))
+	Configure[string](WithPriority(PriorityHigh))
+	getter := func(key int) (int, error) { return key, nil }
+
+	_, err := Get(1, getter)
+	s.NoError(err)
+	_, err = Get(0, func(key int) (string, error) { return "critical", nil })
+	s.NoError(err)
+	_, err = Get(2, getter, WithPriority(PriorityLow))
+	s.NoError(err)
+
+	// A new entry evicts the low-priority one although it is the newest
+	_, err = Get(3, getter)
+	s.NoError(err)
+	_, found := Peek[int, int](2)
+	s.False(found, "Low-priority entry should have been evicted first")
+
+	// Among the rest, normal priority goes before high priority
+	_, err = Get(4, getter)
+	s.NoError(err)
+	_, found = Peek[int, string](0)
+	s.True(found, "High-priority entry should outlive normal ones")
+	_, found = Peek[int, int](1)
+	s.False(found, "Least recently used normal entry should have been evicted")
+}
+
+// TestMaxBytes verifies that the byte budget spans every type, including
+// entries stored before it was set
+func (s *CacherTestSuite) TestMaxBytes() {
+	for i := 0; i < 10; i++ {
+		_, err := Get(i, func(key int) (string, error) { return strings.Repeat("s", 100), nil })
+		s.NoError(err)
+	}
+	SetDefaults(WithMaxBytes(1000), WithSizeEstimator(func(v any) int64 { return 100 }))
+	s.Equal(int64(1000), Stats().Bytes, "Existing entries are measured")
+
+	for i := 0; i < 5; i++ {
+		_, err := Get(i, func(key int) ([]byte, error) { return make([]byte, 100), nil })
+		s.NoError(err)
+	}
+	st := Stats()
+	s.Equal(int64(1000), st.Bytes)
+	s.Equal(5, st.Types["string"].Entries, "Older entries of other types are evicted")
+	s.Equal(5, st.Types["[]uint8"].Entries)
+}
+
+// TestSetDefaultsIsIncremental verifies that later calls keep earlier defaults
+func (s *CacherTestSuite) TestSetDefaultsIsIncremental() {
+	SetDefaults(WithMaxEntries(2))
+	SetDefaults(WithTTL(time.Minute))
+
+	s.Equal(2, globalStore().cfg().maxEntries)
+	s.Equal(time.Minute, globalStore().cfg().ttl)
+}
+
+// TestSetDefaultsMetrics verifies that the metrics sink sees hits, misses,
+// loads and evictions labeled by type
+func (s *CacherTestSuite) TestSetDefaultsMetrics() {
+	sink := newRecordingMetrics()
+	SetDefaults(WithMetrics(sink), WithMaxEntries(1))
+
+	getter := func(key int) (string, error) {
+		if key < 0 {
+			return "", errors.New("negative key")
+		}
+		return "value", nil
+	}
+	for _, key := range []int{1, 1, 2, -1} {
+		_, _ = Get(key, getter)
+	}
+
+	s.Equal(map[string]int{
+		"hit:string":       1,
+		"miss:string":      3,
+		"load:string":      2,
+		"loadError:string": 1,
+		"eviction:string":  1,
+	}, sink.snapshot())
+}
+
+// TestGetWithTTL verifies that a per-call TTL overrides the defaults
+func (s *CacherTestSuite) TestGetWithTTL() {
+	SetDefaults(WithTTL(time.Hour))
+
+	_, err := Get(1, func(key int) (string, error) { return "short", nil }, WithTTL(20*time.Millisecond))
+	s.NoError(err)
+	_, err = Get(2, func(key int) (string, error) { return "long", nil })
+	s.NoError(err)
+
+	s.clock.Advance(30 * time.Millisecond)
+
+	_, found := Peek[int, string](1)
+	s.False(found, "Entry should expire after its per-call TTL")
+	_, found = Peek[int, string](2)
+	s.True(found, "Other entries keep the default TTL")
+}
+
+// TestGetWithForceRefresh verifies that a forced call reloads a cached key
+func (s *CacherTestSuite) TestGetWithForceRefresh() {
+	getter := func(key int) (int32, error) {
+		return s.callCount.Add(1), nil
+	}
+
+	first, err := Get(1, getter)
+	s.NoError(err)
+	refreshed, err := Get(1, getter, WithForceRefresh())
+	s.NoError(err)
+	cached, err := Get(1, getter)
+	s.NoError(err)
+
+	s.Equal(int32(1), first)
+	s.Equal(int32(2), refreshed)
+	s.Equal(int32(2), cached, "The refreshed value should replace the cached one")
+}
+
+// TestGetWithSkipCache verifies that bypassing calls neither read nor write
+func (s *CacherTestSuite) TestGetWithSkipCache() {
+	getter := func(key int) (int32, error) {
+		return s.callCount.Add(1), nil
+	}
+
+	_, err := Get(1, getter)
+	s.NoError(err)
+	bypassed, err := Get(1, getter, WithSkipCache())
+	s.NoError(err)
+	s.Equal(int32(2), bypassed, "The getter should be called despite the cached value")
+
+	_, err = Get(2, getter, WithSkipCache())
+	s.NoError(err)
+	_, found := Peek[int, int32](2)
+	s.False(found, "Bypassing calls should not store their result")
+
+	cached, _ := Peek[int, int32](1)
+	s.Equal(int32(1), cached)
+}
+
+// TestGetWithTimeout verifies that slow getters time out but still populate
+// the cache
+func (s *CacherTestSuite) TestGetWithTimeout() {
+	release := make(chan struct{})
+	getter := func(key int) (string, error) {
+		<-release
+		return "slow", nil
+	}
+
+	_, err := Get(1, getter, WithTimeout(10*time.Millisecond))
+	s.ErrorIs(err, ErrTimeout)
+
+	close(release)
+	s.Eventually(func() bool {
+		_, found := Peek[int, string](1)
+		return found
+	}, time.Second, time.Millisecond, "The abandoned load should still be cached")
+}
+
+// TestGetWithTags verifies that tagged entries are removed together
+func (s *CacherTestSuite) TestGetWithTags() {
+	for i := 0; i < 3; i++ {
+		_, err := Get(i, func(key int) (string, error) { return "user", nil }, WithTags("users"))
+		s.NoError(err)
+	}
+	_, err := Get(1, func(key int) (int, error) { return 1, nil }, WithTags("counters"))
+	s.NoError(err)
+	_, err = Get(2, func(key int) (int, error) { return 2, nil })
+	s.NoError(err)
+
+	s.Equal(3, InvalidateTags("users"))
+	_, found := Peek[int, string](0)
+	s.False(found)
+
+	s.Equal(1, InvalidateTags("users", "counters"))
+	s.Equal(1, Stats().Entries, "Untagged entries should be kept")
+}
+
 // TestCacheCorruption simulates cache corruption (storing incorrect type)
+// and verifies that the cache evicts the bad entry and reloads it
 func (s *CacherTestSuite) TestCacheCorruption() {
 	// First cache a normal value
 	getter := func(id int) (string, error) {
+		s.callCount.Add(1)
 		return "correct value", nil
 	}
 
+	var events []Event
+	SetEventHandler(func(ev Event) {
+		events = append(events, ev)
+	})
+	defer SetEventHandler(nil)
+
 	result1, err1 := Get(1, getter)
 	s.NoError(err1)
 	s.Equal("correct value", result1)
+	s.Equal(int32(1), s.callCount.Load())
 
 	// NOTE: Direct access to internals to simulate corruption
 	// In production code this should never happen
 	var v string
 	valueType := getTypeOf(v)
-	cacheStore.mu.Lock()
-	cacheStore.data[valueType][1] = 12345 // ❌ Intentional corruption: we store int instead of string
-	cacheStore.mu.Unlock()
+	gs := globalStore()
+	gs.mu.Lock()
+	gs.data[valueType].Store(1, &entry{value: 12345}) // ❌ Intentional corruption: we store int instead of string
+	gs.mu.Unlock()
 
-	// Try to retrieve - should detect corruption
+	// Try to retrieve - should self-heal by calling the getter again
 	result2, err2 := Get(1, getter)
-	s.Error(err2)
-	s.Contains(err2.Error(), "cache corruption")
-	s.Equal("", result2) // Zero value of string
+	s.NoError(err2)
+	s.Equal("correct value", result2)
+	s.Equal(int32(2), s.callCount.Load(), "Corrupted entry should have been reloaded")
+
+	// The corruption should have been reported
+	s.Require().Len(events, 1)
+	s.Equal(EventCorruption, events[0].Kind)
+	s.Equal(valueType, events[0].Type)
+	s.Equal(1, events[0].Key)
+	s.ErrorIs(events[0].Err, ErrCorruption)
+
+	// The healed entry is served from cache again
+	result3, err3 := Get(1, getter)
+	s.NoError(err3)
+	s.Equal("correct value", result3)
+	s.Equal(int32(2), s.callCount.Load())
 }
 
 // TestConcurrentReadsAndWrites verifies concurrent operations
@@ -409,3 +891,326 @@ func (s *CacherTestSuite) TestSingleflightMultipleKeys() {
 	s.Equal(int32(3), callCount.Load(),
 		"Should be called once per unique key, even with multiple concurrent requests per key")
 }
+
+// renderedKey is a key type whose distinct values render the same.
+type renderedKey struct {
+	id     int
+	shadow bool
+}
+
+func (k renderedKey) String() string {
+	return strconv.Itoa(k.id)
+}
+
+// TestSingleflightSameRendering verifies that distinct keys never share a
+// load, even when they render the same
+func (s *CacherTestSuite) TestSingleflightSameRendering() {
+	release := make(chan struct{})
+	getter := func(key renderedKey) (bool, error) {
+		<-release
+		return key.shadow, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]bool, 2)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = Get(renderedKey{id: 1, shadow: i == 1}, getter)
+		}(i)
+	}
+	s.Eventually(func() bool {
+		return Stats().InFlight == 2
+	}, time.Second, time.Millisecond, "Each key runs its own getter")
+	close(release)
+	wg.Wait()
+	s.Equal([]bool{false, true}, results)
+}
+
+// recordingMetrics is a MetricsSink counting the calls it receives
+type recordingMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{counts: make(map[string]int)}
+}
+
+func (m *recordingMetrics) add(name, valueType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[name+":"+valueType]++
+}
+
+func (m *recordingMetrics) Hit(valueType string)      { m.add("hit", valueType) }
+func (m *recordingMetrics) Miss(valueType string)     { m.add("miss", valueType) }
+func (m *recordingMetrics) Eviction(valueType string) { m.add("eviction", valueType) }
+
+func (m *recordingMetrics) Load(valueType string, _ time.Duration, err error) {
+	if err != nil {
+		m.add("loadError", valueType)
+		return
+	}
+	m.add("load", valueType)
+}
+
+func (m *recordingMetrics) snapshot() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[string]int, len(m.counts))
+	for name, n := range m.counts {
+		counts[name] = n
+	}
+	return counts
+}
+
+// TestGetWithExpireAt verifies that entries expire exactly at their
+// deadline and that past deadlines are not cached
+func (s *CacherTestSuite) TestGetWithExpireAt() {
+	deadline := s.clock.Now().Add(90 * time.Second)
+	getter := func(key string) (string, error) { return "signed", nil }
+	_, err := Get("url", getter, WithTTL(time.Hour), WithExpireAt(deadline))
+	s.NoError(err)
+
+	s.clock.Advance(90*time.Second - time.Nanosecond)
+	_, found := Peek[string, string]("url")
+	s.True(found)
+	s.clock.Advance(time.Nanosecond)
+	_, found = Peek[string, string]("url")
+	s.False(found, "The entry must expire at its deadline, not after its TTL")
+
+	_, err = Get("late", getter, WithExpireAt(s.clock.Now()))
+	s.NoError(err)
+	_, found = Peek[string, string]("late")
+	s.False(found, "Values past their deadline must not be cached")
+}
+
+// TestCoalesceWindow verifies that uncached results are shared with loads
+// starting within the window, and only with them
+func (s *CacherTestSuite) TestCoalesceWindow() {
+	SetDefaults(WithNilCaching(false), WithCoalesceWindow(10*time.Millisecond))
+	nilGetter := func(id int) (*string, error) {
+		s.callCount.Add(1)
+		return nil, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := Get(1, nilGetter)
+		s.NoError(err)
+		s.clock.Advance(time.Millisecond)
+	}
+	s.Equal(int32(1), s.callCount.Load(), "Loads within the window must share the result")
+	s.Equal(0, Stats().Entries)
+
+	s.clock.Advance(10 * time.Millisecond)
+	_, _ = Get(1, nilGetter)
+	s.Equal(int32(2), s.callCount.Load(), "Loads after the window must call the getter")
+
+	Delete[int, *string](1)
+	_, _ = Get(1, nilGetter)
+	s.Equal(int32(3), s.callCount.Load(), "Delete must forget the shared result")
+
+	_, _ = Get(1, nilGetter, WithSkipCache())
+	s.Equal(int32(4), s.callCount.Load(), "Bypassing calls must not use shared results")
+}
+
+// TestMinRefreshInterval verifies that forced refreshes of recently loaded
+// entries are served from the cache
+func (s *CacherTestSuite) TestMinRefreshInterval() {
+	SetDefaults(WithMinRefreshInterval(time.Minute))
+	getter := func(key string) (int32, error) {
+		return s.callCount.Add(1), nil
+	}
+	_, _ = Get("price", getter)
+
+	value, err := Get("price", getter, WithForceRefresh())
+	s.NoError(err)
+	s.Equal(int32(1), value, "A refresh within the interval must be throttled")
+
+	s.clock.Advance(time.Minute)
+	value, _ = Get("price", getter, WithForceRefresh())
+	s.Equal(int32(2), value)
+	value, _ = Get("price", getter, WithForceRefresh())
+	s.Equal(int32(2), value, "The refreshed entry restarts the interval")
+}
+
+// TestZeroValueTTL verifies that zero results expire sooner, or are not
+// cached at all with a negative TTL
+func (s *CacherTestSuite) TestZeroValueTTL() {
+	SetDefaults(WithTTL(time.Hour), WithZeroValueTTL(time.Minute))
+	getter := func(key string) (int, error) {
+		s.callCount.Add(1)
+		if key != "full" {
+			return 0, nil
+		}
+		return 1, nil
+	}
+	_, _ = Get("empty", getter)
+	_, _ = Get("full", getter)
+	s.clock.Advance(time.Minute)
+	_, _ = Get("empty", getter)
+	_, _ = Get("full", getter)
+	s.Equal(int32(3), s.callCount.Load(), "Only the zero value expires after a minute")
+
+	s.callCount.Store(0)
+	SetDefaults(WithZeroValueTTL(-1))
+	for i := 0; i < 2; i++ {
+		value, err := Get("absent", getter)
+		s.NoError(err)
+		s.Zero(value)
+	}
+	s.Equal(int32(2), s.callCount.Load(), "Zero values must not be cached")
+}
+
+// TestNegativeTTLCoalescesOnly verifies that a negative TTL shares one
+// getter call among concurrent callers without caching its result
+func (s *CacherTestSuite) TestNegativeTTLCoalescesOnly() {
+	release := make(chan struct{})
+	getter := func(key string) (string, error) {
+		s.callCount.Add(1)
+		<-release
+		return "fresh", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := Get("key", getter, WithTTL(-1))
+			s.NoError(err)
+			s.Equal("fresh", value)
+		}()
+	}
+	s.Eventually(func() bool { return Stats().InFlight == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	s.Equal(int32(1), s.callCount.Load(), "Concurrent callers share the call")
+	_, cached := Peek[string, string]("key")
+	s.False(cached, "The result is not stored")
+
+	c := New[string, string](WithTTL(-1))
+	s.Require().NoError(c.Set("a", "old", WithTTL(time.Minute)))
+	s.Require().NoError(c.Set("a", "new"))
+	_, cached = c.Peek("a")
+	s.False(cached, "Set removes the entry")
+}
+
+// TestDo verifies that Do shares one call among concurrent callers, never
+// stores its result and wraps errors like Get
+func (s *CacherTestSuite) TestDo() {
+	release := make(chan struct{})
+	fn := func(key string) (int, error) {
+		s.callCount.Add(1)
+		<-release
+		return len(key), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := Do("key", fn)
+			s.NoError(err)
+			s.Equal(3, value)
+		}()
+	}
+	s.Eventually(func() bool { return Stats().InFlight == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	s.Equal(int32(1), s.callCount.Load(), "Concurrent callers share the call")
+	_, cached := Peek[string, int]("key")
+	s.False(cached, "The result is not stored")
+
+	_, err := Do("key", fn)
+	s.NoError(err)
+	s.Equal(int32(2), s.callCount.Load(), "Later calls call fn again")
+	stats := Stats()
+	s.Zero(stats.Hits+stats.Misses, "Do records no lookups")
+
+	c := New[string, int]()
+	_, err = c.Do("key", func(string) (int, error) { return 0, ErrNotFound })
+	var loadErr *LoadError
+	s.Require().ErrorAs(err, &loadErr)
+	s.ErrorIs(err, ErrNotFound)
+}
+
+// labeled is a generic value type for naming tests
+type labeled[T any] struct{ value T }
+
+// TestTypeNamer verifies that WithTypeNamer names value types in metrics
+// and statistics, and that ShortTypeName drops import paths
+func (s *CacherTestSuite) TestTypeNamer() {
+	s.Equal("cache.labeled[suite.Suite]", ShortTypeName(reflect.TypeOf(labeled[suite.Suite]{})))
+	s.Equal("[]*cache.labeled[int]", ShortTypeName(reflect.TypeOf([]*labeled[int]{})))
+	s.Equal("map[string]int", ShortTypeName(reflect.TypeOf(map[string]int{})))
+
+	sink := newRecordingMetrics()
+	c := New[string, labeled[int]](
+		WithMetrics(sink),
+		WithTypeNamer(func(reflect.Type) string { return "labels" }),
+		WithLoader(func(string) (labeled[int], error) { return labeled[int]{1}, nil }),
+	)
+	_, err := c.Get("key")
+	s.Require().NoError(err)
+	s.Equal(map[string]int{"miss:labels": 1, "load:labels": 1}, sink.snapshot())
+	s.Contains(c.Stats().Types, "labels")
+}
+
+// TestTypeNameCollisions verifies that the statistics of types sharing a
+// name are summed up under it
+func (s *CacherTestSuite) TestTypeNameCollisions() {
+	SetDefaults(WithTypeNamer(func(reflect.Type) string { return "labels" }))
+	_, err := Get("a", func(string) (labeled[int], error) { return labeled[int]{1}, nil })
+	s.Require().NoError(err)
+	_, err = Get("b", func(string) (labeled[string], error) { return labeled[string]{"b"}, nil })
+	s.Require().NoError(err)
+	_, err = Get("b", func(string) (labeled[string], error) { return labeled[string]{"b"}, nil })
+	s.Require().NoError(err)
+
+	st := Stats().Types
+	s.Len(st, 1)
+	s.Equal(2, st["labels"].Entries)
+	s.Equal(uint64(2), st["labels"].Misses)
+	s.Equal(uint64(1), st["labels"].Hits)
+	s.Equal(uint64(2), st["labels"].Loads.Count)
+}
+
+// TestCorruptionPolicy verifies that CorruptionError fails lookups of
+// corrupted entries without reloading and CorruptionPanic panics
+func (s *CacherTestSuite) TestCorruptionPolicy() {
+	getter := func(key int) (string, error) {
+		s.callCount.Add(1)
+		return "correct value", nil
+	}
+	corrupt := func() {
+		_, err := Get(1, getter)
+		s.Require().NoError(err)
+		gs := globalStore()
+		gs.mu.Lock()
+		gs.data[getTypeOf("")].Store(1, &entry{value: 12345})
+		gs.mu.Unlock()
+	}
+
+	SetDefaults(WithCorruptionPolicy(CorruptionError))
+	corrupt()
+	_, err := Get(1, getter)
+	s.ErrorIs(err, ErrCorruption)
+	s.Equal(int32(1), s.callCount.Load(), "The getter is not called")
+	value, err := Get(1, getter)
+	s.NoError(err, "The corrupted entry was evicted")
+	s.Equal("correct value", value)
+
+	Reset()
+	SetDefaults(WithCorruptionPolicy(CorruptionPanic))
+	corrupt()
+	s.PanicsWithError("cache corruption: stored value type mismatch (have int, want string)", func() {
+		_, _ = Get(1, getter)
+	})
+	s.NotPanics(func() { Peek[int, string](1) }, "The entry was evicted before panicking")
+}
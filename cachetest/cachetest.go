@@ -0,0 +1,126 @@
+// Package cachetest provides helpers for testing code that uses the
+// package-level functions of github.com/alexanderbotero/cache.
+//
+//	func TestUserService(t *testing.T) {
+//		cachetest.Reset(t)
+//		clock := cachetest.UseFakeClock(t)
+//		spy := cachetest.NewSpy(loadUser)
+//
+//		cache.Get(1, spy.Get)
+//		cache.Get(1, spy.Get)
+//		clock.Advance(time.Hour)
+//
+//		cachetest.AssertHits(t, 1)
+//		cachetest.AssertMisses(t, 1)
+//		if spy.Calls() != 1 {
+//			t.Fatal("user loaded more than once")
+//		}
+//	}
+package cachetest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexanderbotero/cache"
+)
+
+// Epoch is the time fake clocks created by this package start at.
+var Epoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Reset empties the package-level cache and restores its defaults, now and
+// again when the test finishes.
+func Reset(t testing.TB) {
+	t.Helper()
+	cache.Reset()
+	t.Cleanup(cache.Reset)
+}
+
+// NewClock returns a fake clock set to Epoch.
+func NewClock() *cache.FakeClock {
+	return cache.NewFakeClock(Epoch)
+}
+
+// UseFakeClock makes the package-level cache use a new fake clock set to
+// Epoch and returns it. Call it after Reset, which restores the real clock.
+func UseFakeClock(t testing.TB) *cache.FakeClock {
+	t.Helper()
+	clock := NewClock()
+	cache.SetDefaults(cache.WithClock(clock))
+	return clock
+}
+
+// Spy wraps a getter and counts how often it is called, in total and per
+// key. It is safe for concurrent use.
+type Spy[K comparable, V any] struct {
+	fn func(K) (V, error)
+
+	mu    sync.Mutex
+	calls map[K]int
+	total int
+}
+
+// NewSpy returns a Spy calling fn.
+func NewSpy[K comparable, V any](fn func(K) (V, error)) *Spy[K, V] {
+	return &Spy[K, V]{fn: fn, calls: make(map[K]int)}
+}
+
+// Get records the call and forwards it to the wrapped getter. Pass it
+// wherever a getter is expected.
+func (s *Spy[K, V]) Get(key K) (V, error) {
+	s.mu.Lock()
+	s.calls[key]++
+	s.total++
+	s.mu.Unlock()
+	return s.fn(key)
+}
+
+// Calls returns how many times the getter was called.
+func (s *Spy[K, V]) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
+
+// CallsFor returns how many times the getter was called for key.
+func (s *Spy[K, V]) CallsFor(key K) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[key]
+}
+
+// Reset sets the call counts back to zero.
+func (s *Spy[K, V]) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = make(map[K]int)
+	s.total = 0
+}
+
+// AssertHits fails the test unless the package-level cache served want
+// lookups from the cache.
+func AssertHits(t testing.TB, want uint64) {
+	t.Helper()
+	if got := cache.Stats().Hits; got != want {
+		t.Errorf("cache hits = %d, want %d", got, want)
+	}
+}
+
+// AssertMisses fails the test unless want lookups of the package-level
+// cache were misses.
+func AssertMisses(t testing.TB, want uint64) {
+	t.Helper()
+	if got := cache.Stats().Misses; got != want {
+		t.Errorf("cache misses = %d, want %d", got, want)
+	}
+}
+
+// AssertStats fails the test unless stats, taken from the package-level
+// cache or an instance, report the given hits and misses.
+func AssertStats(t testing.TB, stats cache.Statistics, hits, misses uint64) {
+	t.Helper()
+	if stats.Hits != hits || stats.Misses != misses {
+		t.Errorf("cache hits/misses = %d/%d, want %d/%d", stats.Hits, stats.Misses, hits, misses)
+	}
+}
@@ -0,0 +1,84 @@
+package cachetest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alexanderbotero/cache"
+	"github.com/alexanderbotero/cache/cachetest"
+)
+
+type CachetestTestSuite struct {
+	suite.Suite
+}
+
+func TestCachetestSuite(t *testing.T) {
+	suite.Run(t, new(CachetestTestSuite))
+}
+
+// TestSpyCountsCalls verifies that the spy counts calls in total and per key
+func (s *CachetestTestSuite) TestSpyCountsCalls() {
+	cachetest.Reset(s.T())
+	spy := cachetest.NewSpy(func(key int) (string, error) {
+		if key < 0 {
+			return "", errors.New("negative key")
+		}
+		return "value", nil
+	})
+
+	for _, key := range []int{1, 1, 2, -1, -1} {
+		_, _ = cache.Get(key, spy.Get)
+	}
+
+	s.Equal(4, spy.Calls(), "Failed loads should be retried")
+	s.Equal(1, spy.CallsFor(1))
+	s.Equal(2, spy.CallsFor(-1))
+	cachetest.AssertHits(s.T(), 1)
+	cachetest.AssertMisses(s.T(), 4)
+
+	spy.Reset()
+	s.Equal(0, spy.Calls())
+}
+
+// TestUseFakeClock verifies that the installed clock drives expiration
+func (s *CachetestTestSuite) TestUseFakeClock() {
+	cachetest.Reset(s.T())
+	clock := cachetest.UseFakeClock(s.T())
+	spy := cachetest.NewSpy(func(key string) (int, error) { return len(key), nil })
+
+	_, err := cache.Get("key", spy.Get, cache.WithTTL(time.Minute))
+	s.NoError(err)
+	clock.Advance(time.Minute)
+	_, err = cache.Get("key", spy.Get)
+	s.NoError(err)
+
+	s.Equal(2, spy.Calls(), "Expired entry should be reloaded")
+	s.Equal(cachetest.Epoch.Add(time.Minute), clock.Now())
+}
+
+// TestAssertStatsWithInstance verifies assertions against an instance
+func (s *CachetestTestSuite) TestAssertStatsWithInstance() {
+	spy := cachetest.NewSpy(func(key int) (int, error) { return key * 2, nil })
+	c := cache.New[int, int](cache.WithLoader(spy.Get))
+
+	_, _ = c.Get(1)
+	_, _ = c.Get(1)
+
+	cachetest.AssertStats(s.T(), c.Stats(), 1, 1)
+}
+
+// TestReset verifies that Reset forgets entries and defaults
+func (s *CachetestTestSuite) TestReset() {
+	cachetest.Reset(s.T())
+	cache.SetDefaults(cache.WithMaxEntries(1))
+	_, _ = cache.Get(1, func(key int) (int, error) { return key, nil })
+
+	cachetest.Reset(s.T())
+
+	_, found := cache.Peek[int, int](1)
+	s.False(found)
+	s.Equal(cache.Statistics{}, cache.Stats())
+}
@@ -0,0 +1,54 @@
+package cache
+
+import "sync"
+
+// maxEntryBlock bounds the blocks entries are allocated in. An entry keeps
+// its whole block alive, so large blocks would retain the memory of many
+// deleted entries.
+const maxEntryBlock = 64
+
+// WithInitialCapacity sizes the partition of each value type for n entries
+// when it is created, including after Clear, so that warming up a large
+// cache does not grow its map over and over. Entries are then allocated in
+// blocks rather than one at a time, trading a little retained memory after
+// deletions for fewer allocations. The partition size is ignored by
+// backends configured with WithBackend.
+func WithInitialCapacity(n int) Option {
+	return func(o *options) {
+		o.initialCapacity = n
+	}
+}
+
+// entryAllocator hands out entries carved from blocks of size entries, or
+// allocates them one by one if size is zero.
+type entryAllocator struct {
+	size int
+
+	mu    sync.Mutex
+	block []entry
+}
+
+func newEntryAllocator(capacity int) *entryAllocator {
+	if capacity > maxEntryBlock {
+		capacity = maxEntryBlock
+	}
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &entryAllocator{size: capacity}
+}
+
+// alloc returns a zero entry.
+func (a *entryAllocator) alloc() *entry {
+	if a == nil || a.size <= 1 {
+		return new(entry)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.block) == 0 {
+		a.block = make([]entry, a.size)
+	}
+	e := &a.block[0]
+	a.block = a.block[1:]
+	return e
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the cache the current time. All expiration decisions go
+// through it, so tests can control time instead of sleeping. Clocks that
+// also have an After method, like FakeClock, drive the cache's timers as
+// well, such as those of scheduled refreshes; with other clocks these wait
+// for the system time.
+type Clock interface {
+	Now() time.Time
+}
+
+// afterClock is a Clock that can wake waiters.
+type afterClock interface {
+	Clock
+	After(d time.Duration) <-chan time.Time
+}
+
+// RealClock is the Clock backed by the system time. It is the default.
+type RealClock struct{}
+
+// Now returns time.Now().
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock is a Clock that only moves when told to. It is safe for
+// concurrent use.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+// fakeWaiter is a channel returned by FakeClock.After.
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFakeClock returns a FakeClock set to start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.wakeLocked()
+}
+
+// Set moves the clock to t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+	c.wakeLocked()
+}
+
+// After returns a channel receiving the clock's time once it has moved
+// forward by d or more.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// wakeLocked signals the waiters whose time has come.
+func (c *FakeClock) wakeLocked() {
+	waiting := c.waiters[:0]
+	for _, w := range c.waiters {
+		if c.now.Before(w.at) {
+			waiting = append(waiting, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = waiting
+}
+
+// WithClock sets the clock used to decide when entries expire.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// now returns the current time according to the store's clock.
+func (s *store) now() time.Time {
+	return s.cfg().clock.Now()
+}
+
+// after returns a channel receiving once d has passed on the store's
+// clock, and a function releasing it early.
+func (s *store) after(d time.Duration) (<-chan time.Time, func()) {
+	if clock, ok := s.cfg().clock.(afterClock); ok {
+		return clock.After(d), func() {}
+	}
+	timer := time.NewTimer(d)
+	return timer.C, func() { timer.Stop() }
+}
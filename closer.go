@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// WithCloseValues makes the cache call Close on values implementing
+// io.Closer once they leave it, whether they are evicted, expire, are
+// replaced with another value or deleted, so caches of connections, file
+// handles and other resources do not leak them. Values are closed grace
+// after their removal, in a separate goroutine, giving callers that just
+// read them time to finish; a value cached again in the meantime under the
+// same key is not closed. Close errors are reported as EventCloseError.
+//
+// Values still referenced by a Snapshot are closed all the same.
+func WithCloseValues(grace time.Duration) Option {
+	return func(o *options) {
+		o.closeValues = true
+		o.closeGrace = grace
+	}
+}
+
+// retireLocked schedules the closing of value, which was just removed from
+// key in the valueType partition, if closing is enabled and value is an
+// io.Closer. Must be called with s.mu held for writing.
+func (s *store) retireLocked(valueType reflect.Type, key, value any) {
+	cfg := s.cfg()
+	if !cfg.closeValues {
+		return
+	}
+	closer, ok := value.(io.Closer)
+	if !ok || isNil(value) {
+		return
+	}
+	if k := (entryKey{valueType, key}); s.leasedLocked(k) {
+		s.deferCloseLocked(k, value)
+		return
+	}
+	time.AfterFunc(cfg.closeGrace, func() {
+		s.closeRetired(valueType, key, closer)
+	})
+}
+
+// closeRetired closes a value removed from key unless it has been cached
+// there again since.
+func (s *store) closeRetired(valueType reflect.Type, key any, closer io.Closer) {
+	if reflect.TypeOf(closer).Comparable() {
+		s.rlock()
+		current, ok := s.entryLocked(valueType, key)
+		s.mu.RUnlock()
+		if ok && current.spill == nil {
+			if value, _ := current.get(); value == any(closer) {
+				return
+			}
+		}
+	}
+	if err := closer.Close(); err != nil {
+		s.emit(Event{Kind: EventCloseError, Type: valueType, Key: key, Err: fmt.Errorf("closing value: %w", err)})
+	}
+}
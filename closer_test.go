@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CloserTestSuite struct {
+	suite.Suite
+}
+
+func TestCloserSuite(t *testing.T) {
+	suite.Run(t, new(CloserTestSuite))
+}
+
+// resource is an io.Closer counting how often it was closed.
+type resource struct {
+	closed atomic.Int32
+	err    error
+}
+
+func (r *resource) Close() error {
+	r.closed.Add(1)
+	return r.err
+}
+
+func (r *resource) isClosed() bool {
+	return r.closed.Load() > 0
+}
+
+// TestRemovedValuesAreClosed verifies that replaced, deleted and evicted
+// values are closed
+func (s *CloserTestSuite) TestRemovedValuesAreClosed() {
+	c := New[string, *resource](WithMaxEntries(2), WithCloseValues(0))
+	replaced, deleted, evicted := &resource{}, &resource{}, &resource{}
+	kept := []*resource{{}, {}}
+
+	c.Set("c", evicted)
+	c.Set("a", replaced)
+	c.Set("a", kept[0])
+	c.Set("b", deleted)
+	c.Delete("b")
+	c.Set("d", kept[1])
+
+	for _, r := range []*resource{replaced, deleted, evicted} {
+		s.Eventually(r.isClosed, time.Second, time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	for _, r := range kept {
+		s.False(r.isClosed(), "Cached values must stay open")
+	}
+}
+
+// TestGracePeriod verifies that values are only closed once the grace
+// period has elapsed
+func (s *CloserTestSuite) TestGracePeriod() {
+	c := New[string, *resource](WithCloseValues(50 * time.Millisecond))
+	r := &resource{}
+	c.Set("a", r)
+	c.Delete("a")
+
+	s.False(r.isClosed(), "The value must stay open during the grace period")
+	s.Eventually(r.isClosed, time.Second, time.Millisecond)
+}
+
+// TestRecachedValueIsNotClosed verifies that a value stored again under its
+// key before the grace period ends stays open
+func (s *CloserTestSuite) TestRecachedValueIsNotClosed() {
+	c := New[string, *resource](WithCloseValues(20 * time.Millisecond))
+	r := &resource{}
+	c.Set("a", r)
+	c.Set("a", r)
+	c.Delete("a")
+	c.Set("a", r)
+
+	time.Sleep(50 * time.Millisecond)
+	s.False(r.isClosed())
+}
+
+// TestCloseErrorsAreReported verifies that failing closes emit an event
+func (s *CloserTestSuite) TestCloseErrorsAreReported() {
+	events := make(chan Event, 1)
+	c := New[string, *resource](
+		WithCloseValues(0),
+		WithEventHandler(func(ev Event) { events <- ev }),
+	)
+	c.Set("a", &resource{err: errors.New("broken pipe")})
+	c.Clear()
+
+	select {
+	case ev := <-events:
+		s.Equal(EventCloseError, ev.Kind)
+		s.Equal("a", ev.Key)
+		s.ErrorContains(ev.Err, "broken pipe")
+	case <-time.After(time.Second):
+		s.Fail("No close error reported")
+	}
+}
+
+// TestClosingIsOptIn verifies that values are left open by default
+func (s *CloserTestSuite) TestClosingIsOptIn() {
+	c := New[string, *resource]()
+	r := &resource{}
+	c.Set("a", r)
+	c.Delete("a")
+
+	time.Sleep(10 * time.Millisecond)
+	s.False(r.isClosed())
+}
@@ -0,0 +1,11 @@
+// Package example shows the facade cachegen generates for a cache of
+// users by ID.
+package example
+
+//go:generate go run github.com/alexanderbotero/cache/cmd/cachegen -type UserCache -key int -value *User -by ID
+
+// User is the cached value.
+type User struct {
+	ID   int
+	Name string
+}
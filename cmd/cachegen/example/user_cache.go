@@ -0,0 +1,45 @@
+// Code generated by cachegen. DO NOT EDIT.
+
+package example
+
+import (
+	"github.com/alexanderbotero/cache"
+)
+
+// UserCache caches *User values by int.
+type UserCache struct {
+	c *cache.Cache[int, *User]
+}
+
+// NewUserCache creates a UserCache configured with opts, which are passed to
+// cache.New.
+func NewUserCache(opts ...cache.Option) *UserCache {
+	return &UserCache{c: cache.New[int, *User](opts...)}
+}
+
+// GetByID returns the value cached for id, loading it on a miss in
+// read-through mode; see cache.Cache.Get.
+func (c *UserCache) GetByID(id int) (*User, error) {
+	return c.c.Get(id)
+}
+
+// PeekByID returns the value cached for id without loading it.
+func (c *UserCache) PeekByID(id int) (*User, bool) {
+	return c.c.Peek(id)
+}
+
+// SetByID stores value for id; see cache.Cache.Set.
+func (c *UserCache) SetByID(id int, value *User, opts ...cache.Option) error {
+	return c.c.Set(id, value, opts...)
+}
+
+// DeleteByID removes the value cached for id.
+func (c *UserCache) DeleteByID(id int, opts ...cache.Option) {
+	c.c.Delete(id, opts...)
+}
+
+// Cache returns the underlying cache, for the operations the facade does
+// not wrap.
+func (c *UserCache) Cache() *cache.Cache[int, *User] {
+	return c.c
+}
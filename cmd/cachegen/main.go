@@ -0,0 +1,202 @@
+// Command cachegen generates strongly-typed facades over cache.Cache, for
+// teams that prefer domain-named caches with discoverable methods to the
+// generic API. It is meant to be run by go generate:
+//
+//	//go:generate go run github.com/alexanderbotero/cache/cmd/cachegen -type UserCache -key int -value *User -by ID
+//
+// generates user_cache.go in the package of the file holding the
+// directive, declaring
+//
+//	type UserCache struct{ ... }
+//	func NewUserCache(opts ...cache.Option) *UserCache
+//	func (c *UserCache) GetByID(id int) (*User, error)
+//	func (c *UserCache) PeekByID(id int) (*User, bool)
+//	func (c *UserCache) SetByID(id int, value *User, opts ...cache.Option) error
+//	func (c *UserCache) DeleteByID(id int, opts ...cache.Option)
+//	func (c *UserCache) Cache() *cache.Cache[int, *User]
+//
+// Key and value types from other packages are written qualified, such as
+// -value *model.User, with their packages listed with -import.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// config describes the facade to generate.
+type config struct {
+	Package string
+	Type    string
+	Key     string
+	Value   string
+	// By is the suffix of the method names, such as ID in GetByID
+	By string
+	// KeyName is the name of the key parameter
+	KeyName string
+	Imports []string
+}
+
+// importList is a flag.Value collecting repeated -import flags.
+type importList []string
+
+func (l *importList) String() string { return strings.Join(*l, ",") }
+
+func (l *importList) Set(path string) error {
+	*l = append(*l, path)
+	return nil
+}
+
+func main() {
+	var cfg config
+	var imports importList
+	flag.StringVar(&cfg.Type, "type", "", "name of the generated type (required)")
+	flag.StringVar(&cfg.Key, "key", "", "key type (required)")
+	flag.StringVar(&cfg.Value, "value", "", "value type (required)")
+	flag.StringVar(&cfg.By, "by", "", "suffix of the method names after By, such as ID for GetByID")
+	flag.StringVar(&cfg.KeyName, "keyname", "", "name of the key parameter (default derived from -by, or key)")
+	flag.StringVar(&cfg.Package, "package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	flag.Var(&imports, "import", "import path needed by the key or value type (repeatable)")
+	output := flag.String("o", "", "output file (default the snake-cased type name)")
+	flag.Parse()
+	cfg.Imports = imports
+
+	src, err := generate(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cachegen:", err)
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = snakeCase(cfg.Type) + ".go"
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "cachegen:", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the formatted source of the facade described by cfg.
+func generate(cfg config) ([]byte, error) {
+	switch {
+	case cfg.Package == "":
+		return nil, errors.New("no package: run through go generate or pass -package")
+	case cfg.Type == "" || cfg.Key == "" || cfg.Value == "":
+		return nil, errors.New("-type, -key and -value are required")
+	}
+	if cfg.By != "" {
+		cfg.By = "By" + cfg.By
+	}
+	if cfg.KeyName == "" {
+		cfg.KeyName = lowerInitial(strings.TrimPrefix(cfg.By, "By"))
+		switch cfg.KeyName {
+		case "", "c", "value", "opts":
+			// Names taken by the generated code
+			cfg.KeyName = "key"
+		default:
+			if token.IsKeyword(cfg.KeyName) {
+				cfg.KeyName = "key"
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := facade.Execute(&buf, cfg); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// lowerInitial lowercases the leading run of upper case letters of s, so
+// that ID becomes id and UserName becomes userName.
+func lowerInitial(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsUpper(r) {
+			if i > 1 {
+				// Keep the start of the next word capitalized
+				runes[i-1] = unicode.ToUpper(runes[i-1])
+			}
+			break
+		}
+		runes[i] = unicode.ToLower(r)
+	}
+	return string(runes)
+}
+
+// snakeCase turns a type name such as UserCache into user_cache.
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var facade = template.Must(template.New("facade").Parse(`// Code generated by cachegen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"github.com/alexanderbotero/cache"
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+
+// {{.Type}} caches {{.Value}} values by {{.Key}}.
+type {{.Type}} struct {
+	c *cache.Cache[{{.Key}}, {{.Value}}]
+}
+
+// New{{.Type}} creates a {{.Type}} configured with opts, which are passed to
+// cache.New.
+func New{{.Type}}(opts ...cache.Option) *{{.Type}} {
+	return &{{.Type}}{c: cache.New[{{.Key}}, {{.Value}}](opts...)}
+}
+
+// Get{{.By}} returns the value cached for {{.KeyName}}, loading it on a miss in
+// read-through mode; see cache.Cache.Get.
+func (c *{{.Type}}) Get{{.By}}({{.KeyName}} {{.Key}}) ({{.Value}}, error) {
+	return c.c.Get({{.KeyName}})
+}
+
+// Peek{{.By}} returns the value cached for {{.KeyName}} without loading it.
+func (c *{{.Type}}) Peek{{.By}}({{.KeyName}} {{.Key}}) ({{.Value}}, bool) {
+	return c.c.Peek({{.KeyName}})
+}
+
+// Set{{.By}} stores value for {{.KeyName}}; see cache.Cache.Set.
+func (c *{{.Type}}) Set{{.By}}({{.KeyName}} {{.Key}}, value {{.Value}}, opts ...cache.Option) error {
+	return c.c.Set({{.KeyName}}, value, opts...)
+}
+
+// Delete{{.By}} removes the value cached for {{.KeyName}}.
+func (c *{{.Type}}) Delete{{.By}}({{.KeyName}} {{.Key}}, opts ...cache.Option) {
+	c.c.Delete({{.KeyName}}, opts...)
+}
+
+// Cache returns the underlying cache, for the operations the facade does
+// not wrap.
+func (c *{{.Type}}) Cache() *cache.Cache[{{.Key}}, {{.Value}}] {
+	return c.c
+}
+`))
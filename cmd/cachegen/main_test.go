@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CachegenTestSuite struct {
+	suite.Suite
+}
+
+func TestCachegenSuite(t *testing.T) {
+	suite.Run(t, new(CachegenTestSuite))
+}
+
+// TestExampleUpToDate verifies that the example package holds what the
+// generator currently produces
+func (s *CachegenTestSuite) TestExampleUpToDate() {
+	src, err := generate(config{Package: "example", Type: "UserCache", Key: "int", Value: "*User", By: "ID"})
+	s.Require().NoError(err)
+	golden, err := os.ReadFile("example/user_cache.go")
+	s.Require().NoError(err)
+	s.Equal(string(golden), string(src), "Run go generate in cmd/cachegen/example")
+}
+
+// TestGenerate verifies imports, key names and argument validation
+func (s *CachegenTestSuite) TestGenerate() {
+	src, err := generate(config{
+		Package: "store",
+		Type:    "ProductCache",
+		Key:     "model.SKU",
+		Value:   "model.Product",
+		By:      "Type",
+		Imports: []string{"example.com/shop/model"},
+	})
+	s.Require().NoError(err)
+	s.Contains(string(src), "\t\"example.com/shop/model\"\n")
+	s.Contains(string(src), "func (c *ProductCache) GetByType(key model.SKU) (model.Product, error)",
+		"Keywords are not used as parameter names")
+
+	_, err = generate(config{Package: "store", Type: "ProductCache"})
+	s.Error(err)
+	_, err = generate(config{Type: "ProductCache", Key: "int", Value: "string"})
+	s.Error(err)
+}
+
+// TestNames verifies the derived parameter and file names
+func (s *CachegenTestSuite) TestNames() {
+	s.Equal("id", lowerInitial("ID"))
+	s.Equal("userName", lowerInitial("UserName"))
+	s.Equal("urlPath", lowerInitial("URLPath"))
+	s.Equal("user_cache", snakeCase("UserCache"))
+	s.Equal("http_response_cache", snakeCase("HTTPResponseCache"))
+}
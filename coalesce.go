@@ -0,0 +1,71 @@
+package cache
+
+import "time"
+
+// recentSweepMin is the number of remembered results above which writing
+// one first drops those past their window.
+const recentSweepMin = 64
+
+// WithCoalesceWindow shares the result of a getter call with the loads of
+// the same key that start within window of it completing, even when the
+// result was not cached: nil results with nil caching disabled, values
+// rejected by the admission policy or already past their deadline. It
+// absorbs bursts of misses without full caching semantics. Calls with
+// WithSkipCache neither use nor provide shared results, and deleting a
+// key forgets its result. Zero disables the window.
+func WithCoalesceWindow(window time.Duration) Option {
+	return func(o *options) {
+		o.coalesceWindow = window
+	}
+}
+
+// recentLoad is the result of a getter call kept for the coalescing window.
+type recentLoad struct {
+	value any
+	at    time.Time
+}
+
+// recentResultLocked returns the result of a getter call for k that
+// completed within the coalescing window. Must be called with s.mu held.
+func (s *store) recentResultLocked(k entryKey) (any, bool) {
+	window := s.cfg().coalesceWindow
+	if window <= 0 {
+		return nil, false
+	}
+	r, ok := s.recent[k]
+	if !ok || s.now().Sub(r.at) >= window {
+		return nil, false
+	}
+	return r.value, true
+}
+
+// rememberResult keeps value, just returned by the getter of the load f,
+// for the coalescing window. Nothing is kept if k was deleted, written or
+// cleared since f began.
+func (s *store) rememberResult(k entryKey, f *flight, value any) {
+	window := s.cfg().coalesceWindow
+	if window <= 0 {
+		return
+	}
+	s.lock()
+	defer s.mu.Unlock()
+	if f.stale {
+		return
+	}
+	now := s.now()
+	if len(s.recent) >= s.recentSweep {
+		for key, r := range s.recent {
+			if now.Sub(r.at) >= window {
+				delete(s.recent, key)
+			}
+		}
+		s.recentSweep = 2 * len(s.recent)
+		if s.recentSweep < recentSweepMin {
+			s.recentSweep = recentSweepMin
+		}
+	}
+	if s.recent == nil {
+		s.recent = make(map[entryKey]recentLoad)
+	}
+	s.recent[k] = recentLoad{value: value, at: now}
+}
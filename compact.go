@@ -0,0 +1,34 @@
+package cache
+
+// Compact releases memory held by the package-level cache beyond what its
+// entries need. Go maps never shrink, so a partition that once held many
+// more entries than it does now keeps their space; Compact copies every
+// partition into storage sized for its current entries and drops empty
+// ones. It takes time proportional to the number of entries and blocks
+// writers meanwhile, so call it during quiet periods, for instance after a
+// large Clear or invalidation.
+func Compact() {
+	globalStore().compact()
+}
+
+// Compact releases memory held by the cache beyond what its entries need,
+// like the package-level Compact.
+func (c *Cache[K, V]) Compact() {
+	c.s.compact()
+}
+
+// compact rebuilds every partition at its current size.
+func (s *store) compact() {
+	s.lock()
+	defer s.mu.Unlock()
+	for valueType, b := range s.data {
+		if b.Len() == 0 {
+			delete(s.data, valueType)
+		} else {
+			s.data[valueType] = b.Clone()
+		}
+		// The copy belongs to the store alone
+		delete(s.shared, valueType)
+	}
+	s.publishLocked()
+}
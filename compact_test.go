@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CompactTestSuite struct {
+	suite.Suite
+}
+
+func TestCompactSuite(t *testing.T) {
+	suite.Run(t, new(CompactTestSuite))
+}
+
+// partitions returns the number of value types the store holds.
+func partitions(s *store) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.data)
+}
+
+// TestEmptyPartitionsArePruned verifies that a type whose entries are all
+// deleted leaves nothing behind
+func (s *CompactTestSuite) TestEmptyPartitionsArePruned() {
+	c := New[string, int]()
+	c.Set("a", 1)
+	c.Set("b", 2)
+	s.Equal(1, partitions(c.s))
+
+	c.Delete("a")
+	c.Delete("b")
+	s.Equal(0, partitions(c.s))
+
+	c.Set("a", 3)
+	v, err := c.Get("a")
+	s.NoError(err)
+	s.Equal(3, v, "The partition is recreated on demand")
+}
+
+// TestCompact verifies that compacting keeps every entry and leaves
+// snapshots untouched
+func (s *CompactTestSuite) TestCompact() {
+	c := New[int, int]()
+	for i := 0; i < 1000; i++ {
+		c.Set(i, i)
+	}
+	for i := 10; i < 1000; i++ {
+		c.Delete(i)
+	}
+	snap := c.Snapshot()
+	c.s.mu.Lock()
+	c.s.partitionForWrite(getTypeOf(""))
+	c.s.mu.Unlock()
+	s.Equal(2, partitions(c.s))
+
+	c.Compact()
+	s.Equal(1, partitions(c.s), "Empty partitions are dropped")
+	s.Equal(10, c.Stats().Entries)
+	c.Set(0, -1)
+
+	v, ok := snap.Get(0)
+	s.True(ok)
+	s.Equal(0, v, "Snapshots keep their view")
+	v, err := c.Get(0)
+	s.NoError(err)
+	s.Equal(-1, v)
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"reflect"
+	"time"
+)
+
+// typeConfig holds the per-type defaults registered with Configure.
+type typeConfig struct {
+	ttl        time.Duration
+	ttlSet     bool
+	maxEntries int
+	codec      Codec
+	priority   Priority
+	rateLimit  *RateLimit
+}
+
+// Configure registers defaults for values of type V cached through the
+// package-level functions, so individual cached types can be tuned without
+// migrating their call sites to Cache instances. WithTTL, WithMaxEntries,
+// WithCodec, WithPriority and WithRateLimit are honored; other options are
+// ignored. A later Configure call for the same type replaces the previous
+// configuration. Entries that are already cached keep their expiration.
+//
+//	cache.Configure[*User](cache.WithTTL(5*time.Minute), cache.WithMaxEntries(10_000))
+func Configure[V any](opts ...Option) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	globalStore().configureType(getTypeOf(*new(V)), &typeConfig{
+		ttl:        o.ttl,
+		ttlSet:     o.ttlSet,
+		maxEntries: o.maxEntries,
+		codec:      o.codec,
+		priority:   o.priority,
+		rateLimit:  o.rateLimit,
+	})
+}
+
+// WithTTL sets how long entries are served after being stored. Expired
+// entries are treated as misses. Zero means entries never expire. A
+// negative ttl means values are not cached at all: Get still coalesces
+// concurrent loads of a key into one getter call whose result every
+// caller receives, but the result is not stored, and Set removes the
+// entry instead of writing it. This coalesce-only mode protects an origin
+// from stampedes where caching the values would not be safe.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+		o.ttlSet = true
+	}
+}
+
+// configureType registers cfg for valueType.
+func (s *store) configureType(valueType reflect.Type, cfg *typeConfig) {
+	s.lock()
+	defer s.mu.Unlock()
+	current, _ := s.types.Load().(map[reflect.Type]*typeConfig)
+	types := make(map[reflect.Type]*typeConfig, len(current)+1)
+	for t, c := range current {
+		types[t] = c
+	}
+	types[valueType] = cfg
+	s.types.Store(types)
+	if cfg.maxEntries > 0 {
+		s.typeLimits.Store(true)
+	}
+}
+
+// defaultTypeConfig is used for types without a registered configuration.
+var defaultTypeConfig = &typeConfig{}
+
+// configFor returns the configuration registered for valueType. The result
+// is never nil and must not be modified.
+func (s *store) configFor(valueType reflect.Type) *typeConfig {
+	types, _ := s.types.Load().(map[reflect.Type]*typeConfig)
+	if cfg, ok := types[valueType]; ok {
+		return cfg
+	}
+	return defaultTypeConfig
+}
+
+// ttlFor returns the time to live of new entries of valueType.
+func (s *store) ttlFor(valueType reflect.Type) time.Duration {
+	if cfg := s.configFor(valueType); cfg.ttlSet {
+		return cfg.ttl
+	}
+	return s.cfg().ttl
+}
+
+// priorityFor returns the eviction priority of entries of valueType.
+func (s *store) priorityFor(valueType reflect.Type) Priority {
+	if cfg := s.configFor(valueType); cfg.priority != PriorityNormal {
+		return cfg.priority
+	}
+	return s.cfg().priority
+}
+
+// codecFor returns the codec used for values of valueType.
+func (s *store) codecFor(valueType reflect.Type) Codec {
+	if cfg := s.configFor(valueType); cfg.codec != nil {
+		return cfg.codec
+	}
+	return s.cfg().codec
+}
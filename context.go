@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// requestScopeKey is the context key of the request scope set by
+// ForRequest.
+type requestScopeKey struct{}
+
+// callOptionsKey is the context key of the per-call options applied to
+// every GetCtx call made with the context.
+type callOptionsKey struct{}
+
+// GetCtx is Get for code paths carrying a context. It returns ctx.Err() if
+// ctx ends before the value is available; the getter keeps running and its
+// result is cached once it returns. Within a context returned by
+// ForRequest, it uses the request-scoped cache, and within one returned by
+// SkipCache, ForceFresh or ContextTTL it behaves as if the matching option
+// was passed.
+func GetCtx[K comparable, V any](ctx context.Context, key K, getterFunc func(K) (V, error), opts ...Option) (V, error) {
+	if err := globalStore().checkKey(key); err != nil {
+		var zero V
+		return zero, err
+	}
+	call := options{ctx: ctx}
+	ctxOpts, _ := ctx.Value(callOptionsKey{}).([]Option)
+	for _, opt := range ctxOpts {
+		opt(&call)
+	}
+	for _, opt := range opts {
+		opt(&call)
+	}
+	s := globalStore()
+	if scope, ok := ctx.Value(requestScopeKey{}).(*store); ok {
+		return loadScoped(scope, s, key, unweighted(getterFunc), call)
+	}
+	if call.forceRefresh {
+		var zero V
+		if s.refreshThrottled(getTypeOf(zero), key) {
+			call.forceRefresh = false
+		} else {
+			s.audit(AuditRefresh, getTypeOf(zero), key, nil, call.reason)
+		}
+	}
+	return load(s, key, unweighted(getterFunc), call)
+}
+
+// ForRequest returns a copy of ctx carrying a request-scoped cache layered
+// over the package-level one, for memoizing user-specific values that must
+// not leak to other requests. GetCtx with the returned context, or one
+// derived from it, reads the request scope and then the package-level
+// cache; values it loads are only stored in the request scope and are
+// discarded with the context when the request ends.
+//
+//	func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+//		ctx := cache.ForRequest(r.Context())
+//		perms, err := cache.GetCtx(ctx, userID(r), loadPermissions)
+//		...
+//	}
+func ForRequest(ctx context.Context) context.Context {
+	scope := newStore()
+	scope.settings.Store(newSettings(options{
+		clock:   globalStore().cfg().clock,
+		skipNil: globalStore().cfg().skipNil,
+	}))
+	return context.WithValue(ctx, requestScopeKey{}, scope)
+}
+
+// SkipCache returns a copy of ctx making GetCtx calls bypass the cache, as
+// with WithSkipCache, so that a middleware can exempt a whole request, for
+// example one sent with Cache-Control: no-cache, without plumbing options
+// through every call site.
+func SkipCache(ctx context.Context) context.Context {
+	return withCallOptions(ctx, WithSkipCache())
+}
+
+// ForceFresh returns a copy of ctx making GetCtx calls reload their value
+// and replace the cached entry, as with WithForceRefresh.
+func ForceFresh(ctx context.Context) context.Context {
+	return withCallOptions(ctx, WithForceRefresh())
+}
+
+// ContextTTL returns a copy of ctx giving the values loaded by GetCtx
+// calls made with it a time to live of ttl, as with WithTTL, for example
+// very short freshness during admin preview sessions. Options passed to
+// GetCtx take precedence.
+func ContextTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return withCallOptions(ctx, WithTTL(ttl))
+}
+
+// withCallOptions returns a copy of ctx whose GetCtx calls apply opts, in
+// addition to those set by its parents.
+func withCallOptions(ctx context.Context, opts ...Option) context.Context {
+	current, _ := ctx.Value(callOptionsKey{}).([]Option)
+	all := make([]Option, 0, len(current)+len(opts))
+	all = append(append(all, current...), opts...)
+	return context.WithValue(ctx, callOptionsKey{}, all)
+}
+
+// loadScoped loads key through the request scope, serving the entry of
+// parent on misses of the scope before calling the getter.
+func loadScoped[K comparable, V any](scope, parent *store, key K, getterFunc weightedGetter[K, V], call options) (V, error) {
+	if _, ok := peek[K, V](scope, key); !ok && !call.forceRefresh && !call.skipCache {
+		if value, ok := cached[K, V](parent, key, true); ok {
+			var zero V
+			parent.recordHit(getTypeOf(zero), key)
+			return value, nil
+		}
+	}
+	return load(scope, key, getterFunc, call)
+}
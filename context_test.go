@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ContextTestSuite struct {
+	suite.Suite
+	calls atomic.Int32
+}
+
+func TestContextSuite(t *testing.T) {
+	suite.Run(t, new(ContextTestSuite))
+}
+
+func (s *ContextTestSuite) SetupTest() {
+	Scoped(s.T())
+	s.calls.Store(0)
+}
+
+func (s *ContextTestSuite) getter(key string) (string, error) {
+	s.calls.Add(1)
+	return "value of " + key, nil
+}
+
+// TestForRequest verifies that request scopes read through to the
+// package-level cache and keep their own loads to themselves
+func (s *ContextTestSuite) TestForRequest() {
+	_, err := Get("shared", s.getter)
+	s.NoError(err)
+
+	ctx := ForRequest(context.Background())
+	value, err := GetCtx(ctx, "shared", s.getter)
+	s.NoError(err)
+	s.Equal("value of shared", value)
+	s.Equal(int32(1), s.calls.Load(), "Scopes must read the package-level cache")
+
+	for i := 0; i < 2; i++ {
+		_, err = GetCtx(ctx, "mine", s.getter)
+		s.NoError(err)
+	}
+	s.Equal(int32(2), s.calls.Load(), "Scopes must memoize their loads")
+	_, found := Peek[string, string]("mine")
+	s.False(found, "Scoped loads must not reach the package-level cache")
+
+	_, err = GetCtx(ForRequest(context.Background()), "mine", s.getter)
+	s.NoError(err)
+	s.Equal(int32(3), s.calls.Load(), "Every request has its own scope")
+}
+
+// TestGetCtxCanceled verifies that GetCtx stops waiting when its context
+// ends
+func (s *ContextTestSuite) TestGetCtxCanceled() {
+	release := make(chan struct{})
+	defer close(release)
+	ctx, cancel := context.WithCancel(context.Background())
+	getter := func(key string) (string, error) {
+		cancel()
+		<-release
+		return key, nil
+	}
+
+	_, err := GetCtx(ctx, "slow", getter)
+	s.True(errors.Is(err, context.Canceled))
+}
+
+// TestContextMarkers verifies that SkipCache and ForceFresh apply to every
+// GetCtx call made with the context
+func (s *ContextTestSuite) TestContextMarkers() {
+	_, err := GetCtx(context.Background(), "key", s.getter)
+	s.NoError(err)
+
+	_, err = GetCtx(SkipCache(context.Background()), "key", s.getter)
+	s.NoError(err)
+	s.Equal(int32(2), s.calls.Load(), "Bypassing contexts must call the getter")
+	_, _ = GetCtx(SkipCache(context.Background()), "other", s.getter)
+	_, found := Peek[string, string]("other")
+	s.False(found, "Bypassing contexts must not store results")
+
+	ctx, cancel := context.WithCancel(ForceFresh(context.Background()))
+	defer cancel()
+	_, err = GetCtx(ctx, "key", s.getter)
+	s.NoError(err)
+	s.Equal(int32(4), s.calls.Load(), "Markers must survive derived contexts")
+}
+
+// TestContextTTL verifies that a context can shorten the time to live of
+// the values loaded with it
+func (s *ContextTestSuite) TestContextTTL() {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetDefaults(WithClock(clock), WithTTL(time.Hour))
+	ctx := ContextTTL(context.Background(), time.Second)
+
+	_, err := GetCtx(ctx, "preview", s.getter)
+	s.NoError(err)
+	_, err = GetCtx(ctx, "explicit", s.getter, WithTTL(time.Minute))
+	s.NoError(err)
+
+	clock.Advance(time.Second)
+	_, found := Peek[string, string]("preview")
+	s.False(found)
+	_, found = Peek[string, string]("explicit")
+	s.True(found, "Options of the call must take precedence")
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// CorruptionPolicy decides how lookups respond to a cached value that does
+// not have the type its partition expects, which only a bug can cause.
+// Every policy evicts the entry and emits an EventCorruption first.
+type CorruptionPolicy int
+
+const (
+	// CorruptionReload treats the entry as missing and reloads it through
+	// the getter, so callers never notice. It is the default.
+	CorruptionReload CorruptionPolicy = iota
+	// CorruptionError fails the lookup with an error wrapping
+	// ErrCorruption instead of reloading. Peek reports a miss.
+	CorruptionError
+	// CorruptionPanic panics with an error wrapping ErrCorruption, to stop
+	// tests and CI runs at the first sign of the bug.
+	CorruptionPanic
+)
+
+// String returns a human-readable name for the policy.
+func (p CorruptionPolicy) String() string {
+	switch p {
+	case CorruptionReload:
+		return "reload"
+	case CorruptionError:
+		return "error"
+	case CorruptionPanic:
+		return "panic"
+	default:
+		return "unknown"
+	}
+}
+
+// WithCorruptionPolicy sets how lookups respond to cached values of the
+// wrong type. The default is CorruptionReload.
+//
+//	func TestMain(m *testing.M) {
+//		cache.SetDefaults(cache.WithCorruptionPolicy(cache.CorruptionPanic))
+//		os.Exit(m.Run())
+//	}
+func WithCorruptionPolicy(policy CorruptionPolicy) Option {
+	return func(o *options) {
+		o.corruption = policy
+	}
+}
+
+// reportCorruption emits an EventCorruption for a value of key that is not
+// of valueType and applies the corruption policy: it panics under
+// CorruptionPanic and otherwise returns the error, which lookups return
+// under CorruptionError.
+func (s *store) reportCorruption(valueType reflect.Type, key, corrupted any) error {
+	err := fmt.Errorf("%w (have %T, want %v)", ErrCorruption, corrupted, valueType)
+	s.emit(Event{
+		Kind: EventCorruption,
+		Type: valueType,
+		Key:  key,
+		Err:  err,
+	})
+	if s.cfg().corruption == CorruptionPanic {
+		panic(err)
+	}
+	return err
+}
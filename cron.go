@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronHorizon bounds how far ahead a cron schedule looks for its next
+// time; expressions such as "0 0 30 2 *" never match.
+const cronHorizon = 5
+
+// cronSchedule is a parsed cron expression.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record unrestricted day fields: when both days are
+	// restricted, matching either is enough
+	domAny, dowAny bool
+	loc            *time.Location
+}
+
+// cronDescriptors are the shorthands accepted in place of five fields.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	dayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// parseCron parses a standard five-field cron expression: minute, hour,
+// day of month, month and day of week, each a "*", a value, a range "a-b"
+// or a comma-separated list of those, optionally stepped with "/n". Months
+// and days of week may be given by their three-letter English names and
+// Sunday as 0 or 7. Descriptors such as "@daily" stand for common
+// expressions. Times are local unless the expression starts with
+// "CRON_TZ=" or "TZ=" and a time zone name.
+func parseCron(spec string) (*cronSchedule, error) {
+	fail := func(format string, args ...any) (*cronSchedule, error) {
+		return nil, fmt.Errorf("cache: invalid cron expression %q: %s", spec, fmt.Sprintf(format, args...))
+	}
+	expr := strings.TrimSpace(spec)
+	c := &cronSchedule{loc: time.Local}
+	if strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=") {
+		tz, rest, _ := strings.Cut(expr[strings.IndexByte(expr, '=')+1:], " ")
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return fail("%v", err)
+		}
+		c.loc, expr = loc, strings.TrimSpace(rest)
+	}
+	if full, ok := cronDescriptors[expr]; ok {
+		expr = full
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return fail("want 5 fields, have %d", len(fields))
+	}
+
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return fail("minute: %v", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return fail("hour: %v", err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return fail("day of month: %v", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return fail("month: %v", err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7, dayNames); err != nil {
+		return fail("day of week: %v", err)
+	}
+	if c.dow&(1<<7) != 0 {
+		// 7 is another name for Sunday
+		c.dow |= 1
+	}
+	// Like in Vixie cron, a field starting with "*", such as "*/2", does
+	// not restrict the day on its own
+	c.domAny = strings.HasPrefix(fields[2], "*") || fields[2] == "?"
+	c.dowAny = strings.HasPrefix(fields[4], "*") || fields[4] == "?"
+	return c, nil
+}
+
+// parseCronField returns the bit set of the values field allows between
+// min and max. names, if any, name the values from min on.
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	value := func(s string) (int, error) {
+		for i, name := range names {
+			if strings.EqualFold(s, name) {
+				return min + i, nil
+			}
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < min || n > max {
+			return 0, fmt.Errorf("%q is not a value between %d and %d", s, min, max)
+		}
+		return n, nil
+	}
+
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		span, stepText, stepped := strings.Cut(part, "/")
+		step := 1
+		if stepped {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%q is not a valid step", stepText)
+			}
+		}
+		low, high := min, max
+		switch {
+		case span == "*" || span == "?":
+		case strings.Contains(span, "-"):
+			from, to, _ := strings.Cut(span, "-")
+			var err error
+			if low, err = value(from); err != nil {
+				return 0, err
+			}
+			if high, err = value(to); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("range %q is reversed", span)
+			}
+		default:
+			n, err := value(span)
+			if err != nil {
+				return 0, err
+			}
+			low = n
+			if !stepped {
+				high = n
+			}
+		}
+		for n := low; n <= high; n += step {
+			bits |= 1 << n
+		}
+	}
+	return bits, nil
+}
+
+// next returns the first time after after that the expression matches, or
+// the zero time if there is none within cronHorizon years.
+func (c *cronSchedule) next(after time.Time) time.Time {
+	t := after.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronHorizon, 0, 0)
+	for t.Before(limit) {
+		year, month, day := t.Date()
+		switch {
+		case c.month&(1<<uint(month)) == 0:
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, c.loc)
+		case !c.dayMatches(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, c.loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, c.loc)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the day of t matches the day fields.
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CronTestSuite struct {
+	suite.Suite
+}
+
+func TestCronSuite(t *testing.T) {
+	suite.Run(t, new(CronTestSuite))
+}
+
+// next parses spec and returns its next time after after
+func (s *CronTestSuite) next(spec string, after time.Time) time.Time {
+	c, err := parseCron(spec)
+	s.Require().NoError(err, spec)
+	return c.next(after)
+}
+
+// TestNext verifies the times matched by cron expressions
+func (s *CronTestSuite) TestNext() {
+	utc := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	// 2026-10-16 is a Friday
+	friday := utc(2026, time.October, 16, 16, 5)
+	cases := []struct {
+		spec  string
+		after time.Time
+		want  time.Time
+	}{
+		{"CRON_TZ=UTC 5 16 * * 1-5", friday.Add(-time.Second), friday},
+		{"CRON_TZ=UTC 5 16 * * mon-fri", friday, utc(2026, time.October, 19, 16, 5)},
+		{"CRON_TZ=UTC */15 * * * *", utc(2026, time.October, 16, 10, 7), utc(2026, time.October, 16, 10, 15)},
+		{"CRON_TZ=UTC 0 9,17 * * *", utc(2026, time.October, 16, 9, 0), utc(2026, time.October, 16, 17, 0)},
+		{"CRON_TZ=UTC @daily", friday, utc(2026, time.October, 17, 0, 0)},
+		{"CRON_TZ=UTC @monthly", friday, utc(2026, time.November, 1, 0, 0)},
+		{"CRON_TZ=UTC 0 0 29 feb *", friday, utc(2028, time.February, 29, 0, 0)},
+		{"CRON_TZ=UTC 0 0 1 * sun", friday, utc(2026, time.October, 18, 0, 0)},
+		{"CRON_TZ=UTC 0 0 * * 7", friday, utc(2026, time.October, 18, 0, 0)},
+		{"CRON_TZ=UTC 0 0 */2 * 1", friday, utc(2026, time.October, 19, 0, 0)},
+		{"CRON_TZ=UTC 0 0 13 * */3", friday, utc(2026, time.December, 13, 0, 0)},
+		{"CRON_TZ=UTC 0 0 30 2 *", friday, time.Time{}},
+	}
+	for _, tc := range cases {
+		s.Equal(tc.want, s.next(tc.spec, tc.after), tc.spec)
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	s.Require().NoError(err)
+	got := s.next("CRON_TZ=Europe/Berlin 5 16 * * *", friday)
+	s.Equal(time.Date(2026, time.October, 17, 16, 5, 0, 0, berlin), got)
+}
+
+// TestInvalidExpressions verifies that malformed expressions are rejected
+func (s *CronTestSuite) TestInvalidExpressions() {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"CRON_TZ=Nowhere/City * * * * *",
+	} {
+		_, err := parseCron(spec)
+		s.Error(err, spec)
+	}
+}
+
+// TestScheduleRefreshCron verifies that cron schedules load their key
+// right away and reject invalid expressions
+func (s *CronTestSuite) TestScheduleRefreshCron() {
+	c := New[string, float64](WithLoader(func(string) (float64, error) { return 1.08, nil }))
+	r, err := c.ScheduleRefreshCron("EUR", "5 16 * * mon-fri", nil)
+	s.Require().NoError(err)
+	defer r.Stop()
+	s.Eventually(func() bool { _, ok := c.Peek("EUR"); return ok }, time.Second, time.Millisecond)
+
+	_, err = c.ScheduleRefreshCron("EUR", "every day", nil)
+	s.Error(err)
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"reflect"
+	"time"
+)
+
+// Do calls fn for key and returns its result, sharing the call with the
+// concurrent Do calls for the same key and value type instead of making
+// another: stampede protection without caching. The result is never
+// stored and the cache is never consulted, so every Do after the shared
+// call completes calls fn again. Errors are wrapped in a *LoadError like
+// those of Get, and calls count towards load latencies, OriginLoads and
+// Coalesced but not towards hits and misses. WithTimeout is the only
+// option honored.
+//
+//	quote, err := cache.Do(symbol, fetchQuote)
+func Do[K comparable, V any](key K, fn func(K) (V, error), opts ...Option) (V, error) {
+	return do(globalStore(), key, fn, opts)
+}
+
+// Do is the package-level Do for the calls of a cache instance, which are
+// never shared with those of other instances.
+func (c *Cache[K, V]) Do(key K, fn func(K) (V, error), opts ...Option) (V, error) {
+	return do(c.s, key, fn, opts)
+}
+
+func do[K comparable, V any](s *store, key K, fn func(K) (V, error), opts []Option) (V, error) {
+	var zero V
+	if fn == nil {
+		if s.cfg().strict {
+			panic(ErrNilGetter)
+		}
+		return zero, ErrNilGetter
+	}
+	if err := s.checkKey(key); err != nil {
+		return zero, err
+	}
+	var call options
+	for _, opt := range opts {
+		opt(&call)
+	}
+	valueType := getTypeOf(zero)
+
+	var group *callGroup[K]
+	if _, nan := canonicalKey(key).(nanKey); !nan {
+		group = groupOf[K](s, groupID{keyType: getTypeOf(*new(K)), valueType: valueType, do: true})
+	}
+	result, err := run(nil, s, valueType, group, callKey[K]{key: key}, call.timeout, func(func()) (any, error) {
+		return callOrigin(s, valueType, key, fn)
+	})
+	if err != nil {
+		return zero, err
+	}
+	typedValue, ok := result.(V)
+	if !ok {
+		return zero, s.reportCorruption(valueType, key, result)
+	}
+	return typedValue, nil
+}
+
+// callOrigin calls fn for key, accounting for the call like a getter call.
+func callOrigin[K comparable, V any](s *store, valueType reflect.Type, key K, fn func(K) (V, error)) (any, error) {
+	start := time.Now()
+	value, _, err := callGetter(s, unweighted(fn), key)
+	s.recordLoad(valueType, time.Since(start), err)
+	if err != nil {
+		return nil, s.loadError(valueType, key, err)
+	}
+	return value, nil
+}
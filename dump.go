@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// DumpOptions configures DumpJSON.
+type DumpOptions struct {
+	// Types restricts the dump to the value types with these names, as
+	// reported in Statistics.Types. Empty means every type.
+	Types []string
+	// IncludeValues adds the values, encoded as JSON, to the dump. Values
+	// are left out by default since they may hold personal data.
+	IncludeValues bool
+	// MaxEntries bounds the number of entries dumped; zero means no limit.
+	MaxEntries int
+	// MaxValueBytes leaves out values whose JSON encoding is longer; zero
+	// means no limit.
+	MaxValueBytes int
+}
+
+// Dump is the document written by DumpJSON.
+type Dump struct {
+	TakenAt time.Time   `json:"taken_at"`
+	Entries []DumpEntry `json:"entries"`
+	// Truncated is set when MaxEntries left entries out.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// DumpEntry describes a cached entry in a Dump.
+type DumpEntry struct {
+	Type      string     `json:"type"`
+	Key       string     `json:"key"`
+	Version   uint64     `json:"version"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Size      int64      `json:"size,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Priority  string     `json:"priority"`
+	// Value is the JSON encoding of the value, if requested.
+	Value json.RawMessage `json:"value,omitempty"`
+	// ValueOmitted explains why a requested value is missing.
+	ValueOmitted string `json:"value_omitted,omitempty"`
+}
+
+// DumpJSON writes the live entries of the package-level cache to w as a
+// JSON Dump, sorted by type and key, for offline inspection. Keys are
+// formatted with fmt; values are only included if opts ask for them. Both
+// go through the Redactor set with WithRedactor, if any.
+func DumpJSON(w io.Writer, opts DumpOptions) error {
+	return globalStore().dumpJSON(w, opts)
+}
+
+// DumpJSON writes the live entries of the cache to w as a JSON Dump. See
+// the package-level DumpJSON.
+func (c *Cache[K, V]) DumpJSON(w io.Writer, opts DumpOptions) error {
+	return c.s.dumpJSON(w, opts)
+}
+
+func (s *store) dumpJSON(w io.Writer, opts DumpOptions) error {
+	wanted := make(map[string]bool, len(opts.Types))
+	for _, name := range opts.Types {
+		wanted[name] = true
+	}
+
+	type dumped struct {
+		entry DumpEntry
+		value any
+	}
+	var entries []dumped
+	now := s.now()
+	s.rlock()
+	s.rangeAllLocked(func(valueType reflect.Type, key any, e *entry) bool {
+		name := s.typeName(valueType)
+		if e.expired(now) || (len(wanted) > 0 && !wanted[name]) {
+			return true
+		}
+		d := dumped{entry: DumpEntry{
+			Type:     name,
+			Key:      fmt.Sprint(s.redactKey(name, key)),
+			Version:  e.version,
+			Size:     e.size,
+			Tags:     e.tags,
+			Priority: e.priority.String(),
+		}}
+		if !e.expiresAt.IsZero() {
+			expiresAt := e.expiresAt
+			d.entry.ExpiresAt = &expiresAt
+		}
+		if opts.IncludeValues {
+			value, _ := e.get()
+			d.value = s.redactValue(name, value)
+		}
+		entries = append(entries, d)
+		return true
+	})
+	s.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].entry.Type != entries[j].entry.Type {
+			return entries[i].entry.Type < entries[j].entry.Type
+		}
+		return entries[i].entry.Key < entries[j].entry.Key
+	})
+	dump := Dump{TakenAt: now, Entries: make([]DumpEntry, 0, len(entries))}
+	if opts.MaxEntries > 0 && len(entries) > opts.MaxEntries {
+		entries, dump.Truncated = entries[:opts.MaxEntries], true
+	}
+	for _, d := range entries {
+		if opts.IncludeValues {
+			data, err := json.Marshal(d.value)
+			switch {
+			case err != nil:
+				d.entry.ValueOmitted = err.Error()
+			case opts.MaxValueBytes > 0 && len(data) > opts.MaxValueBytes:
+				d.entry.ValueOmitted = fmt.Sprintf("%d bytes exceed the limit", len(data))
+			default:
+				d.entry.Value = data
+			}
+		}
+		dump.Entries = append(dump.Entries, d.entry)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dump)
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DumpTestSuite struct {
+	suite.Suite
+}
+
+func TestDumpSuite(t *testing.T) {
+	suite.Run(t, new(DumpTestSuite))
+}
+
+// dump decodes the output of DumpJSON with opts.
+func (s *DumpTestSuite) dump(c *Cache[string, string], opts DumpOptions) Dump {
+	var buf bytes.Buffer
+	s.Require().NoError(c.DumpJSON(&buf, opts))
+	var d Dump
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &d))
+	return d
+}
+
+// TestDumpListsEntriesWithoutValues verifies that metadata is dumped and
+// values are left out by default
+func (s *DumpTestSuite) TestDumpListsEntriesWithoutValues() {
+	clock := NewFakeClock(time.Unix(0, 0).UTC())
+	c := New[string, string](WithClock(clock), WithTTL(time.Minute))
+	c.Set("b", "secret")
+	c.Set("a", "value")
+
+	d := s.dump(c, DumpOptions{})
+	s.Require().Len(d.Entries, 2)
+	s.Equal("a", d.Entries[0].Key)
+	s.Equal("b", d.Entries[1].Key)
+	s.Equal("string", d.Entries[0].Type)
+	s.Equal("normal", d.Entries[0].Priority)
+	s.Equal(clock.Now().Add(time.Minute), *d.Entries[0].ExpiresAt)
+	s.Nil(d.Entries[0].Value)
+}
+
+// TestDumpLimits verifies the entry and value size limits
+func (s *DumpTestSuite) TestDumpLimits() {
+	c := New[string, string]()
+	c.Set("a", "short")
+	c.Set("b", "a much longer value")
+	c.Set("c", "value")
+
+	d := s.dump(c, DumpOptions{IncludeValues: true, MaxEntries: 2, MaxValueBytes: 10})
+	s.True(d.Truncated)
+	s.Require().Len(d.Entries, 2)
+	s.JSONEq(`"short"`, string(d.Entries[0].Value))
+	s.Nil(d.Entries[1].Value)
+	s.NotEmpty(d.Entries[1].ValueOmitted)
+}
+
+// TestDumpFiltersTypes verifies that only the requested types are dumped
+func (s *DumpTestSuite) TestDumpFiltersTypes() {
+	Reset()
+	defer Reset()
+	_, err := Get(1, func(key int) (string, error) { return "one", nil })
+	s.NoError(err)
+	_, err = Get(1, func(key int) (int, error) { return 1, nil })
+	s.NoError(err)
+
+	var buf bytes.Buffer
+	s.Require().NoError(DumpJSON(&buf, DumpOptions{Types: []string{"int"}}))
+	var d Dump
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &d))
+	s.Require().Len(d.Entries, 1)
+	s.Equal("int", d.Entries[0].Type)
+}
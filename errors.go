@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNilGetter is returned when a nil getter function is supplied.
+	ErrNilGetter = errors.New("getterFunc cannot be nil")
+
+	// ErrCorruption is returned (or reported through events) when a cached
+	// value does not have the type expected by its partition.
+	ErrCorruption = errors.New("cache corruption: stored value type mismatch")
+
+	// ErrTimeout is returned when a value could not be obtained within the
+	// time allowed for the call.
+	ErrTimeout = errors.New("cache load timed out")
+
+	// ErrNotCached is returned when a value is requested that is not cached
+	// and cannot be loaded.
+	ErrNotCached = errors.New("cache miss: key not cached")
+
+	// ErrNotFound is returned, wrapped, by getters to report that a key
+	// does not exist at the origin. WithAbsentFilter remembers such keys.
+	ErrNotFound = errors.New("cache: key not found")
+
+	// ErrNotModified is returned by GetIfChanged when the entry still has
+	// the version the caller already knows.
+	ErrNotModified = errors.New("cache: entry not modified")
+
+	// ErrNoStore is returned by operations that require a backing store
+	// when none is configured.
+	ErrNoStore = errors.New("cache: no backing store configured")
+
+	// ErrStopped is reported by HealthCheck for background workers that
+	// are no longer running.
+	ErrStopped = errors.New("cache: worker stopped")
+
+	// ErrNotNumeric is returned by Cache.Increment when the cache does not
+	// hold numbers.
+	ErrNotNumeric = errors.New("cache: value type is not numeric")
+
+	// ErrRateLimited is returned when a getter call is not allowed by the
+	// rate limit set with WithRateLimit.
+	ErrRateLimited = errors.New("cache: origin rate limit reached")
+
+	// ErrFrozen is returned by writes to a cache made read-only with
+	// Freeze.
+	ErrFrozen = errors.New("cache: cache is frozen")
+
+	// ErrUnhashableKey is returned when a key holds a slice, map or
+	// function, which cannot be compared, typically through a key type
+	// that is an interface.
+	ErrUnhashableKey = errors.New("cache: key is not hashable")
+
+	// ErrNaNKey is returned when a key contains a floating-point NaN,
+	// which is not equal to itself, and WithNaNKeys does not allow it.
+	ErrNaNKey = errors.New("cache: key contains NaN")
+
+	// ErrNondeterministicGetter is reported through events in strict mode
+	// when two calls of a getter for the same key disagree.
+	ErrNondeterministicGetter = errors.New("cache: getter returned different values for the same key")
+
+	// ErrMutatedKey is reported through events in strict mode when the
+	// value a pointer key points to changed after the key was cached.
+	ErrMutatedKey = errors.New("cache: key was mutated after insert")
+)
+
+// LoadError is returned when the getter fails to produce a value for a key.
+// The original error is available through errors.Unwrap, errors.Is and
+// errors.As.
+type LoadError struct {
+	Key any
+	Err error
+
+	// shownKey replaces Key in the message when a Redactor is set
+	shownKey any
+}
+
+func (e *LoadError) Error() string {
+	key := e.Key
+	if e.shownKey != nil {
+		key = e.shownKey
+	}
+	return fmt.Sprintf("cache getter failed for key %v: %v", key, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
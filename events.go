@@ -0,0 +1,64 @@
+package cache
+
+import "reflect"
+
+// EventKind identifies the kind of diagnostic event emitted by the cache.
+type EventKind int
+
+const (
+	// EventCorruption is emitted when a cached value does not have the
+	// type its partition expects. The entry is evicted and the lookup
+	// proceeds as WithCorruptionPolicy says.
+	EventCorruption EventKind = iota + 1
+	// EventStoreError is emitted when reading from or writing to the
+	// backing store fails during a load. The load itself still succeeds.
+	EventStoreError
+	// EventMemoryPressure is emitted when the memory monitor evicts
+	// entries because the heap grew beyond its high watermark.
+	EventMemoryPressure
+	// EventCloseError is emitted when closing a value removed from a cache
+	// configured with WithCloseValues fails.
+	EventCloseError
+	// EventStrictViolation is emitted in strict mode when a getter or key
+	// misbehaves; Err wraps ErrNondeterministicGetter or ErrMutatedKey.
+	EventStrictViolation
+)
+
+// String returns a human-readable name for the event kind.
+func (k EventKind) String() string {
+	switch k {
+	case EventCorruption:
+		return "corruption"
+	case EventStoreError:
+		return "store-error"
+	case EventMemoryPressure:
+		return "memory-pressure"
+	case EventCloseError:
+		return "close-error"
+	case EventStrictViolation:
+		return "strict-violation"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a structured diagnostic record describing something noteworthy
+// that happened inside the cache.
+type Event struct {
+	Kind EventKind
+	// Type is the value type of the cache partition involved.
+	Type reflect.Type
+	// Key is the key of the entry involved, as returned by the Redactor's
+	// RedactKey if one is set.
+	Key any
+	// Err describes the problem, if any.
+	Err error
+}
+
+// SetEventHandler registers fn to receive diagnostic events from the
+// package-level cache. Passing nil disables event delivery. The handler is
+// called synchronously, outside of any cache lock, and must be safe for
+// concurrent use.
+func SetEventHandler(fn func(Event)) {
+	globalStore().updateSettings(WithEventHandler(fn))
+}
@@ -0,0 +1,124 @@
+package cache
+
+import "reflect"
+
+// evictionSamples is how many entries are inspected to pick an eviction
+// victim. Stores with at most this many entries evict in exact LRU order;
+// larger stores use sampled (approximate) LRU, like Redis.
+const evictionSamples = 16
+
+// WithMaxEntries bounds the number of entries in the cache. When a new
+// entry would exceed the limit, the least recently used entries are
+// evicted. Zero means unbounded.
+func WithMaxEntries(n int) Option {
+	return func(o *options) {
+		o.maxEntries = n
+	}
+}
+
+// WithMaxEntriesPerType bounds the number of entries of every value type,
+// so a single runaway type, such as one cached per request by mistake,
+// cannot consume the budget of the whole cache. When a type reaches the
+// limit its own least recently used entries are evicted. Limits set for a
+// type with Configure take precedence. Zero means unbounded.
+func WithMaxEntriesPerType(n int) Option {
+	return func(o *options) {
+		o.maxEntriesPerType = n
+	}
+}
+
+// typeLimit returns the maximum number of entries of valueType, or zero if
+// it is unbounded.
+func (s *store) typeLimit(valueType reflect.Type) int {
+	if limit := s.configFor(valueType).maxEntries; limit > 0 {
+		return limit
+	}
+	return s.cfg().maxEntriesPerType
+}
+
+// evictLocked evicts entries until the store is within its limits. The
+// entry identified by keep, which was just written, is only evicted if it
+// exceeds the byte limit on its own. Must be called with s.mu held for
+// writing.
+func (s *store) evictLocked(keep entryKey) {
+	// Per-type limits only ever evict from the type that grew
+	if limit := s.typeLimit(keep.valueType); limit > 0 {
+		for s.lenLocked(keep.valueType) > limit {
+			victim, ok := s.victimLocked(keep, keep.valueType)
+			if !ok {
+				break
+			}
+			s.removeLocked(victim.valueType, victim.key, RemovalCapacity)
+			s.evictions.Add(1)
+			s.cfg().metrics.Eviction(s.typeName(victim.valueType))
+		}
+	}
+
+	for s.overLimitLocked() {
+		victim, ok := s.victimLocked(keep, nil)
+		if !ok {
+			if len(s.leases) > 0 {
+				// Leased entries stay; Release evicts them later
+				return
+			}
+			// keep alone exceeds the byte limit: it cannot be cached
+			victim = keep
+		}
+		if _, removed := s.removeLocked(victim.valueType, victim.key, RemovalCapacity); !removed {
+			return
+		}
+		s.evictions.Add(1)
+		s.cfg().metrics.Eviction(s.typeName(victim.valueType))
+	}
+}
+
+// overLimitLocked reports whether the store exceeds any of its limits.
+func (s *store) overLimitLocked() bool {
+	cfg := s.cfg()
+	return (cfg.maxEntries > 0 && s.count > cfg.maxEntries) ||
+		(cfg.maxBytes > 0 && s.bytes > cfg.maxBytes)
+}
+
+// victimLocked picks the entry to evict among a sample, restricted to the
+// onlyType partition unless it is nil. Expired entries are picked first,
+// then the least recently used one of the lowest priority. Leased entries
+// are never picked.
+func (s *store) victimLocked(keep entryKey, onlyType reflect.Type) (entryKey, bool) {
+	var victim entryKey
+	var victimEntry *entry
+	var oldest uint64
+	found := false
+	// oldestHot is the least recently used hot entry of the sample
+	var oldestHot *entry
+	sampled := 0
+	now := s.now()
+	s.rangeAllLocked(func(valueType reflect.Type, key any, e *entry) bool {
+		if onlyType != nil && valueType != onlyType {
+			return true
+		}
+		if valueType == keep.valueType && key == keep.key {
+			return true
+		}
+		if s.leasedLocked(entryKey{valueType, key}) {
+			return true
+		}
+		if e.expired(now) {
+			victim, found = entryKey{valueType, key}, true
+			return false
+		}
+		access := e.lastAccess.Load()
+		if !found || e.evictsBefore(access, victimEntry, oldest) {
+			victim, victimEntry, oldest, found = entryKey{valueType, key}, e, access, true
+		}
+		if e.hot.Load() && (oldestHot == nil || access < oldestHot.lastAccess.Load()) {
+			oldestHot = e
+		}
+		sampled++
+		return sampled < evictionSamples
+	})
+	if oldestHot != nil && s.hotOverflowLocked() {
+		// Make room in the hot segment for the next promotions
+		s.demote(oldestHot)
+	}
+	return victim, found
+}
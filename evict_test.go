@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EvictTestSuite struct {
+	suite.Suite
+}
+
+func TestEvictSuite(t *testing.T) {
+	suite.Run(t, new(EvictTestSuite))
+}
+
+// TestMaxEntriesEvictsLeastRecentlyUsed verifies LRU eviction at capacity
+func (s *EvictTestSuite) TestMaxEntriesEvictsLeastRecentlyUsed() {
+	c := New[string, int](WithMaxEntries(3))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	// Touch "a" so "b" becomes the least recently used entry
+	_, err := c.Get("a")
+	s.NoError(err)
+
+	c.Set("d", 4)
+
+	_, found := c.Peek("b")
+	s.False(found, "Least recently used entry should have been evicted")
+	for _, key := range []string{"a", "c", "d"} {
+		_, found := c.Peek(key)
+		s.True(found, "Entry %q should still be cached", key)
+	}
+
+	stats := c.Stats()
+	s.Equal(3, stats.Entries)
+	s.Equal(uint64(1), stats.Evictions)
+}
+
+// TestMaxEntriesAppliesToLoadedValues verifies eviction on the read-through path
+func (s *EvictTestSuite) TestMaxEntriesAppliesToLoadedValues() {
+	c := New[int, int](
+		WithMaxEntries(2),
+		WithLoader(func(key int) (int, error) { return key * 10, nil }),
+	)
+
+	for i := 1; i <= 5; i++ {
+		value, err := c.Get(i)
+		s.NoError(err)
+		s.Equal(i*10, value)
+	}
+
+	stats := c.Stats()
+	s.Equal(2, stats.Entries)
+	s.Equal(uint64(3), stats.Evictions)
+	_, found := c.Peek(5)
+	s.True(found, "The most recent entry must never be evicted")
+}
+
+// TestReplacingAnEntryDoesNotEvict verifies that updates don't count as new entries
+func (s *EvictTestSuite) TestReplacingAnEntryDoesNotEvict() {
+	c := New[string, int](WithMaxEntries(2))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("a", 3)
+	c.Set("b", 4)
+
+	stats := c.Stats()
+	s.Equal(2, stats.Entries)
+	s.Equal(uint64(0), stats.Evictions)
+}
+
+// TestDeleteFreesCapacity verifies that deleted entries no longer count towards the limit
+func (s *EvictTestSuite) TestDeleteFreesCapacity() {
+	c := New[string, int](WithMaxEntries(2))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Delete("a")
+	c.Set("c", 3)
+
+	s.Equal(uint64(0), c.Stats().Evictions)
+	_, found := c.Peek("b")
+	s.True(found)
+}
+
+// TestSegmentedLRUResistsScans verifies that entries read repeatedly
+// survive a scan that would flush them from a plain LRU
+func (s *EvictTestSuite) TestSegmentedLRUResistsScans() {
+	c := New[string, int](WithMaxEntries(10), WithSegmentedLRU(0.5))
+	hot := []string{"h0", "h1", "h2", "h3", "h4"}
+	for i, key := range hot {
+		c.Set(key, i)
+		_, err := c.Get(key)
+		s.NoError(err)
+	}
+
+	for i := 0; i < 20; i++ {
+		c.Set(fmt.Sprintf("scan%d", i), i)
+	}
+
+	for _, key := range hot {
+		_, found := c.Peek(key)
+		s.True(found, "Hot entry %q should survive the scan", key)
+	}
+	s.Equal(10, c.Stats().Entries)
+}
+
+// TestSegmentedLRUDemotesBeyondHotShare verifies that the hot segment is
+// kept to its share of the entries
+func (s *EvictTestSuite) TestSegmentedLRUDemotesBeyondHotShare() {
+	c := New[int, int](WithMaxEntries(10), WithSegmentedLRU(0.2))
+	for i := 0; i < 10; i++ {
+		c.Set(i, i)
+		_, err := c.Get(i)
+		s.NoError(err)
+	}
+	s.Equal(int64(10), c.s.hotEntries.Load())
+
+	for i := 10; i < 20; i++ {
+		c.Set(i, i)
+	}
+	s.LessOrEqual(c.s.hotEntries.Load(), int64(2))
+}
+
+// TestAdaptiveSegments verifies that the hot fraction keeps its direction
+// while the hit ratio improves, turns around when it drops and stays
+// within its bounds
+func (s *EvictTestSuite) TestAdaptiveSegments() {
+	t := newSegmentTuner(AdaptiveConfig{Window: 10, Step: 0.1, MaxFraction: 0.9}, 0.5)
+	window := func(hits int) {
+		for i := 0; i < 10; i++ {
+			t.observe(i < hits)
+		}
+	}
+	window(5)
+	s.InDelta(0.6, t.current(), 1e-9)
+	window(6)
+	s.InDelta(0.7, t.current(), 1e-9, "Improving hit ratios keep the direction")
+	window(4)
+	s.InDelta(0.6, t.current(), 1e-9, "A drop turns around")
+	for i := 0; i < 20; i++ {
+		window(10 - i%2)
+	}
+	s.GreaterOrEqual(t.current(), 0.05)
+	s.LessOrEqual(t.current(), 0.9)
+
+	c := New[int, int](WithMaxEntries(10), WithSegmentedLRU(0.5), WithAdaptiveSegments(AdaptiveConfig{Window: 4}))
+	s.InDelta(0.5, c.Stats().HotFraction, 1e-9)
+	for i := 0; i < 4; i++ {
+		_, _ = c.Get(i)
+	}
+	s.InDelta(0.55, c.Stats().HotFraction, 1e-9)
+}
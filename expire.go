@@ -0,0 +1,36 @@
+package cache
+
+import "reflect"
+
+// WithExpireOnWrite makes every write and delete inspect up to samples
+// entries and remove the expired ones among them, like Redis does. Expired
+// entries are then reclaimed a little at a time as the cache is used,
+// instead of lingering until a janitor sweep, and the cost of each write
+// stays bounded. Zero disables it.
+func WithExpireOnWrite(samples int) Option {
+	return func(o *options) {
+		o.expireSamples = samples
+	}
+}
+
+// reclaimExpiredLocked removes the expired entries among a sample of the
+// configured size. Must be called with s.mu held for writing.
+func (s *store) reclaimExpiredLocked() {
+	samples := s.cfg().expireSamples
+	if samples <= 0 {
+		return
+	}
+	now := s.now()
+	var expired []entryKey
+	sampled := 0
+	s.rangeAllLocked(func(valueType reflect.Type, key any, e *entry) bool {
+		if e.expired(now) {
+			expired = append(expired, entryKey{valueType, key})
+		}
+		sampled++
+		return sampled < samples
+	})
+	for _, k := range expired {
+		s.removeLocked(k.valueType, k.key, RemovalExpired)
+	}
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExpireTestSuite struct {
+	suite.Suite
+}
+
+func TestExpireSuite(t *testing.T) {
+	suite.Run(t, new(ExpireTestSuite))
+}
+
+// storedEntries returns the number of entries the store holds, expired or
+// not.
+func storedEntries(s *store) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.count
+}
+
+// TestWritesReclaimExpiredEntries verifies that sets and deletes remove
+// expired entries without a janitor
+func (s *ExpireTestSuite) TestWritesReclaimExpiredEntries() {
+	clock := NewFakeClock(time.Now())
+	c := New[int, string](WithClock(clock), WithTTL(time.Minute), WithExpireOnWrite(evictionSamples))
+	for i := 0; i < 10; i++ {
+		c.Set(i, "expiring")
+	}
+	clock.Advance(time.Minute)
+
+	c.Set(100, "fresh")
+	s.Equal(1, storedEntries(c.s), "A sample covering the store reclaims every expired entry")
+
+	c.Set(101, "expiring")
+	clock.Advance(time.Minute)
+	c.Delete(-1)
+	s.Equal(0, storedEntries(c.s), "Deletes reclaim expired entries too")
+}
+
+// TestExpireOnWriteIsBounded verifies that a write inspects at most the
+// configured number of entries
+func (s *ExpireTestSuite) TestExpireOnWriteIsBounded() {
+	clock := NewFakeClock(time.Now())
+	c := New[int, string](WithClock(clock), WithTTL(time.Minute), WithExpireOnWrite(2))
+	for i := 0; i < 10; i++ {
+		c.Set(i, "expiring")
+	}
+	clock.Advance(time.Minute)
+
+	c.Delete(-1)
+	s.Equal(8, storedEntries(c.s))
+}
+
+// TestExpireOnWriteDisabledByDefault verifies that expired entries linger
+// without the option
+func (s *ExpireTestSuite) TestExpireOnWriteDisabledByDefault() {
+	clock := NewFakeClock(time.Now())
+	c := New[int, string](WithClock(clock), WithTTL(time.Minute))
+	c.Set(1, "expiring")
+	clock.Advance(time.Minute)
+
+	c.Set(2, "fresh")
+	s.Equal(2, storedEntries(c.s))
+}
+
+// TestTTLIntrospection verifies that the remaining time to live and the
+// expiry of entries are reported, for instances and package-level entries
+func (s *ExpireTestSuite) TestTTLIntrospection() {
+	clock := NewFakeClock(time.Now())
+	c := New[string, int](WithClock(clock), WithTTL(time.Minute))
+	s.Require().NoError(c.Set("a", 1))
+	s.Require().NoError(c.Set("forever", 2, WithTTL(0)))
+
+	clock.Advance(20 * time.Second)
+	ttl, ok := c.TTL("a")
+	s.True(ok)
+	s.Equal(40*time.Second, ttl)
+	at, ok := c.ExpiresAt("a")
+	s.True(ok)
+	s.Equal(clock.Now().Add(40*time.Second), at)
+
+	ttl, ok = c.TTL("forever")
+	s.True(ok)
+	s.Zero(ttl, "Entries without expiry report a zero TTL")
+	at, ok = c.ExpiresAt("forever")
+	s.True(ok)
+	s.True(at.IsZero())
+
+	_, ok = c.TTL("missing")
+	s.False(ok)
+	clock.Advance(time.Minute)
+	_, ok = c.ExpiresAt("a")
+	s.False(ok, "Expired entries are not reported")
+
+	Reset()
+	defer Reset()
+	_, err := Get("key", func(string) (int, error) { return 1, nil }, WithTTL(time.Hour))
+	s.Require().NoError(err)
+	ttl, ok = TTL[string, int]("key")
+	s.True(ok)
+	s.InDelta(float64(time.Hour), float64(ttl), float64(time.Second))
+	_, ok = ExpiresAt[string, string]("key")
+	s.False(ok)
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"reflect"
+	"time"
+)
+
+// WithExpireAt makes entries expire at t rather than after a time to live,
+// for values with a known absolute deadline such as signed URLs, OAuth
+// tokens or prices valid until the market closes. It applies to Get and to
+// Cache.Set and takes precedence over WithTTL; values whose deadline has
+// already passed are returned but not cached.
+//
+//	url, err := cache.Get(object, signURL, cache.WithExpireAt(deadline))
+func WithExpireAt(t time.Time) Option {
+	return func(o *options) {
+		o.expireAt = t
+	}
+}
+
+// callTTL returns the time to live of an entry of valueType written now
+// with the per-call options call.
+func (s *store) callTTL(valueType reflect.Type, call options) time.Duration {
+	switch {
+	case !call.expireAt.IsZero():
+		return call.expireAt.Sub(s.now())
+	case call.ttlSet:
+		return call.ttl
+	default:
+		return s.ttlFor(valueType)
+	}
+}
+
+// applyCall sets the expiration, tags and priority of e, just created, from
+// the per-call options call. It reports false if e expires before it could
+// be stored, or must not be stored because its time to live is negative.
+func (s *store) applyCall(valueType reflect.Type, e *entry, call options) bool {
+	ttl := s.callTTL(valueType, call)
+	if ttl < 0 {
+		return false
+	}
+	if !call.expireAt.IsZero() {
+		if ttl <= 0 {
+			return false
+		}
+		s.setExpiry(e, ttl)
+		e.expiresAt = call.expireAt
+	} else {
+		s.setExpiry(e, ttl)
+	}
+	e.tags = call.tags
+	if call.priority != PriorityNormal {
+		e.priority = call.priority
+	}
+	return true
+}
+
+// TTL returns how long the entry of type V cached for key in the
+// package-level cache has left to live; see Cache.TTL.
+func TTL[K comparable, V any](key K) (time.Duration, bool) {
+	return globalStore().remainingTTL(getTypeOf(*new(V)), key)
+}
+
+// ExpiresAt returns when the entry of type V cached for key in the
+// package-level cache expires; see Cache.ExpiresAt.
+func ExpiresAt[K comparable, V any](key K) (time.Time, bool) {
+	return globalStore().expiresAt(getTypeOf(*new(V)), key)
+}
+
+// TTL returns how long the entry cached for key has left to live, so that
+// schedulers can align their own work, such as pre-rendering, with its
+// expiry. The boolean reports whether a live entry is cached; a zero
+// duration with true means the entry never expires. Like Peek, it does
+// not affect statistics or recency.
+func (c *Cache[K, V]) TTL(key K) (time.Duration, bool) {
+	return c.s.remainingTTL(c.valueType, key)
+}
+
+// ExpiresAt returns when the entry cached for key expires. The boolean
+// reports whether a live entry is cached; the zero time with true means
+// the entry never expires.
+func (c *Cache[K, V]) ExpiresAt(key K) (time.Time, bool) {
+	return c.s.expiresAt(c.valueType, key)
+}
+
+// expiresAt returns the expiration of the live entry of valueType cached
+// for key.
+func (s *store) expiresAt(valueType reflect.Type, key any) (time.Time, bool) {
+	if !s.usableKey(key) {
+		return time.Time{}, false
+	}
+	e, ok := s.lookupEntry(valueType, key)
+	if !ok {
+		return time.Time{}, false
+	}
+	return e.expiresAt, true
+}
+
+// remainingTTL returns the time to live left to the live entry of
+// valueType cached for key.
+func (s *store) remainingTTL(valueType reflect.Type, key any) (time.Duration, bool) {
+	expiresAt, ok := s.expiresAt(valueType, key)
+	if !ok || expiresAt.IsZero() {
+		return 0, ok
+	}
+	return expiresAt.Sub(s.now()), true
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// ErrInjectedFault is the error injected into loads by WithFaults when
+// FaultConfig.Err is not set.
+var ErrInjectedFault = errors.New("cache: injected fault")
+
+// FaultConfig configures the faults injected by WithFaults. Rates are
+// probabilities between 0 and 1, drawn independently for every load or
+// lookup.
+type FaultConfig struct {
+	// DelayRate is the fraction of getter calls delayed by Delay before
+	// they start, simulating a slow origin.
+	DelayRate float64
+	Delay     time.Duration
+	// ErrorRate is the fraction of getter calls replaced by a failure
+	// returning Err, or ErrInjectedFault if Err is nil. Failures are
+	// reported like getter errors, wrapped in a *LoadError.
+	ErrorRate float64
+	Err       error
+	// EvictRate is the fraction of lookups that find their entry evicted,
+	// as if by capacity pressure, and reload it.
+	EvictRate float64
+}
+
+// WithFaults injects faults into loads and lookups of Get with a getter
+// and of read-through instances, to verify in tests and canary
+// environments that a service copes with a misbehaving cache and origin.
+// Faults are off by default; SetDefaults can turn them on at run time, and
+// off again with a zero FaultConfig. Injected delays and failures count as
+// getter calls in Stats and metrics, and injected evictions as evictions.
+//
+//	cache.SetDefaults(cache.WithFaults(cache.FaultConfig{
+//		DelayRate: 0.1, Delay: 200 * time.Millisecond,
+//		ErrorRate: 0.01,
+//		EvictRate: 0.05,
+//	}))
+func WithFaults(cfg FaultConfig) Option {
+	return func(o *options) {
+		o.faults = &cfg
+	}
+}
+
+// happens reports whether an event of probability rate happens.
+func happens(rate float64) bool {
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
+
+// withFaults wraps getterFunc so that its calls suffer the configured
+// delays and failures.
+func withFaults[K comparable, V any](cfg *FaultConfig, getterFunc weightedGetter[K, V]) weightedGetter[K, V] {
+	return func(key K) (V, int64, error) {
+		if happens(cfg.DelayRate) {
+			time.Sleep(cfg.Delay)
+		}
+		if happens(cfg.ErrorRate) {
+			var zero V
+			if cfg.Err != nil {
+				return zero, 0, cfg.Err
+			}
+			return zero, 0, ErrInjectedFault
+		}
+		return getterFunc(key)
+	}
+}
+
+// injectEviction evicts the entry of key, if the configured rate says so,
+// before a lookup.
+func (s *store) injectEviction(valueType reflect.Type, key any) {
+	faults := s.cfg().faults
+	if faults == nil || !happens(faults.EvictRate) {
+		return
+	}
+	s.lock()
+	_, removed := s.removeLocked(valueType, key, RemovalCapacity)
+	s.mu.Unlock()
+	if removed {
+		s.evictions.Add(1)
+		s.cfg().metrics.Eviction(s.typeName(valueType))
+	}
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FaultsTestSuite struct {
+	suite.Suite
+	calls atomic.Int32
+}
+
+func TestFaultsSuite(t *testing.T) {
+	suite.Run(t, new(FaultsTestSuite))
+}
+
+func (s *FaultsTestSuite) SetupTest() {
+	Reset()
+	s.calls.Store(0)
+}
+
+func (s *FaultsTestSuite) TearDownTest() {
+	Reset()
+}
+
+func (s *FaultsTestSuite) getter(key string) (int, error) {
+	s.calls.Add(1)
+	return len(key), nil
+}
+
+// TestInjectedErrors verifies that injected failures replace getter calls
+// and are reported like getter errors
+func (s *FaultsTestSuite) TestInjectedErrors() {
+	SetDefaults(WithFaults(FaultConfig{ErrorRate: 1}))
+	_, err := Get("key", s.getter)
+	var loadErr *LoadError
+	s.Require().ErrorAs(err, &loadErr)
+	s.ErrorIs(err, ErrInjectedFault)
+	s.Zero(s.calls.Load(), "The getter is not called")
+
+	boom := errors.New("boom")
+	SetDefaults(WithFaults(FaultConfig{ErrorRate: 1, Err: boom}))
+	_, err = Get("key", s.getter)
+	s.ErrorIs(err, boom)
+
+	SetDefaults(WithFaults(FaultConfig{}))
+	value, err := Get("key", s.getter)
+	s.NoError(err, "A zero config turns faults off")
+	s.Equal(3, value)
+}
+
+// TestInjectedDelays verifies that injected delays slow getter calls down
+func (s *FaultsTestSuite) TestInjectedDelays() {
+	SetDefaults(WithFaults(FaultConfig{DelayRate: 1, Delay: 20 * time.Millisecond}))
+	start := time.Now()
+	_, err := Get("key", s.getter)
+	s.Require().NoError(err)
+	s.GreaterOrEqual(time.Since(start), 20*time.Millisecond)
+	s.Equal(uint64(1), Stats().OriginLoads)
+}
+
+// TestInjectedEvictions verifies that injected evictions make lookups
+// reload their entry and count as evictions
+func (s *FaultsTestSuite) TestInjectedEvictions() {
+	SetDefaults(WithFaults(FaultConfig{EvictRate: 1}))
+	for i := 0; i < 3; i++ {
+		_, err := Get("key", s.getter)
+		s.Require().NoError(err)
+	}
+	s.Equal(int32(3), s.calls.Load())
+	stats := Stats()
+	s.Equal(uint64(2), stats.Evictions)
+	s.Equal(uint64(2), stats.Removals[RemovalCapacity])
+	s.Zero(stats.Hits)
+}
@@ -0,0 +1,111 @@
+package cache
+
+import "sync/atomic"
+
+// flight tracks a load in progress so that deletes and writes racing with
+// it can keep its result out of the cache.
+type flight struct {
+	// forget makes later loads of the key start afresh instead of
+	// joining this one; nil if the load is not shared
+	forget func()
+	// stale is set, under store.mu, once the load's result is outdated
+	stale bool
+	// ended is set once the load is no longer registered
+	ended atomic.Bool
+}
+
+// beginLoad registers a load of k, forgotten by forget once cancelled. In
+// the same critical section it looks for a value making the getter call
+// unnecessary: a result shared within the coalescing window or, with
+// lookup set, a live entry cached since the caller missed.
+func (s *store) beginLoad(k entryKey, forget func(), lookup bool) (f *flight, value any, found bool) {
+	f = &flight{forget: forget}
+	s.lock()
+	defer s.mu.Unlock()
+	if value, found = s.recentResultLocked(k); found {
+		f.ended.Store(true)
+		return f, value, true
+	}
+	if lookup {
+		if e, ok := s.entryLocked(k.valueType, k.key); ok && !e.expired(s.now()) {
+			if value, live := e.get(); live {
+				f.ended.Store(true)
+				return f, value, true
+			}
+		}
+	}
+	if s.flights == nil {
+		s.flights = make(map[entryKey]map[*flight]struct{})
+	}
+	if s.flights[k] == nil {
+		s.flights[k] = make(map[*flight]struct{})
+	}
+	s.flights[k][f] = struct{}{}
+	return f, nil, false
+}
+
+// endFlight unregisters f. It is safe to call more than once.
+func (s *store) endFlight(k entryKey, f *flight) {
+	if f.ended.Load() {
+		return
+	}
+	s.lock()
+	defer s.mu.Unlock()
+	s.dropFlightLocked(k, f)
+}
+
+// putFlight stores e as the result of f and reports whether it did. Nothing
+// is stored if the key was deleted, written or cleared since f began.
+func (s *store) putFlight(k entryKey, f *flight, e *entry) bool {
+	s.lock()
+	defer s.mu.Unlock()
+	if f.stale {
+		return false
+	}
+	s.dropFlightLocked(k, f)
+	s.putLocked(k.valueType, k.key, e)
+	return true
+}
+
+func (s *store) dropFlightLocked(k entryKey, f *flight) {
+	f.ended.Store(true)
+	flights := s.flights[k]
+	delete(flights, f)
+	if len(flights) == 0 {
+		delete(s.flights, k)
+	}
+}
+
+// cancelFlightsLocked marks the loads of k in progress as stale and makes
+// later loads of k start afresh instead of joining them. Must be called
+// with s.mu held for writing.
+func (s *store) cancelFlightsLocked(k entryKey) {
+	k.key = canonicalKey(k.key)
+	for f := range s.flights[k] {
+		f.stale = true
+		f.ended.Store(true)
+		f.forgetLoad()
+	}
+	delete(s.flights, k)
+	delete(s.recent, k)
+}
+
+// cancelAllFlightsLocked is cancelFlightsLocked for every key. Must be
+// called with s.mu held for writing.
+func (s *store) cancelAllFlightsLocked() {
+	for _, flights := range s.flights {
+		for f := range flights {
+			f.stale = true
+			f.ended.Store(true)
+			f.forgetLoad()
+		}
+	}
+	s.flights = nil
+	s.recent = nil
+}
+
+func (f *flight) forgetLoad() {
+	if f.forget != nil {
+		f.forget()
+	}
+}
@@ -0,0 +1,21 @@
+package cache
+
+// Freeze switches the cache to read-only, for example while a process
+// drains or on replicas serving a snapshot. Get keeps serving cached
+// entries, and misses still call the loader but their results are not
+// stored. Set and Increment fail with ErrFrozen, SetIfAbsent reports false
+// and Txn fails with ErrFrozen without applying anything. Delete and Clear
+// keep working so invalidations are not lost. Refresh-ahead stops.
+func (c *Cache[K, V]) Freeze() {
+	c.s.frozen.Store(true)
+}
+
+// Unfreeze makes a frozen cache writable again.
+func (c *Cache[K, V]) Unfreeze() {
+	c.s.frozen.Store(false)
+}
+
+// Frozen reports whether the cache is read-only after Freeze.
+func (c *Cache[K, V]) Frozen() bool {
+	return c.s.frozen.Load()
+}
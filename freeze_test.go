@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FreezeTestSuite struct {
+	suite.Suite
+}
+
+func TestFreezeSuite(t *testing.T) {
+	suite.Run(t, new(FreezeTestSuite))
+}
+
+// TestFrozenReads verifies that a frozen cache serves its entries and loads
+// misses without storing them
+func (s *FreezeTestSuite) TestFrozenReads() {
+	var calls atomic.Int32
+	c := New[string, string](WithLoader(func(key string) (string, error) {
+		calls.Add(1)
+		return "loaded " + key, nil
+	}))
+	s.NoError(c.Set("cached", "value"))
+	c.Freeze()
+	s.True(c.Frozen())
+
+	value, err := c.Get("cached")
+	s.NoError(err)
+	s.Equal("value", value)
+	for i := 0; i < 2; i++ {
+		value, err = c.Get("missing")
+		s.NoError(err)
+		s.Equal("loaded missing", value)
+	}
+	s.Equal(int32(2), calls.Load(), "Frozen caches must not store loaded values")
+
+	c.Unfreeze()
+	_, _ = c.Get("missing")
+	_, found := c.Peek("missing")
+	s.True(found)
+}
+
+// TestFrozenWrites verifies that writes to a frozen cache fail
+func (s *FreezeTestSuite) TestFrozenWrites() {
+	c := New[string, int]()
+	s.NoError(c.Set("a", 1))
+	c.Freeze()
+
+	s.True(errors.Is(c.Set("a", 2), ErrFrozen))
+	s.False(c.SetIfAbsent("b", 1))
+	_, err := c.Increment("a", 1)
+	s.True(errors.Is(err, ErrFrozen))
+	err = c.Txn(func(tx *Tx[string, int]) error {
+		tx.Set("c", 1)
+		return nil
+	})
+	s.True(errors.Is(err, ErrFrozen))
+	value, _ := c.Peek("a")
+	s.Equal(1, value)
+
+	c.Delete("a")
+	_, found := c.Peek("a")
+	s.False(found, "Invalidations must still apply")
+}
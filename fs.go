@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// CachingFS is an fs.FS caching the contents and stat results of the files
+// of another file system, for templates and static assets read repeatedly
+// from slow or remote file systems. It is created with CacheFS.
+//
+// Only regular files are cached; directories are read from the underlying
+// file system. Errors, such as missing files, are not cached.
+type CachingFS struct {
+	fsys  fs.FS
+	files *Cache[string, *fileData]
+}
+
+// fileData is the cached state of a file. The stat result and the
+// contents are read together, so they always describe the same version of
+// the file.
+type fileData struct {
+	info fs.FileInfo
+	// data is nil for files that are not regular
+	data []byte
+}
+
+// CacheFS returns a file system serving the files of fsys from a cache
+// whose entries live for ttl. opts configure the underlying cache, for
+// instance WithQuota to bound the memory used by contents; the ttl always
+// applies. Stat reads and caches the contents of regular files too.
+func CacheFS(fsys fs.FS, ttl time.Duration, opts ...Option) *CachingFS {
+	opts = append(append([]Option(nil), opts...), WithTTL(ttl), WithLoader(func(name string) (*fileData, error) {
+		return readFileData(fsys, name)
+	}))
+	return &CachingFS{
+		fsys:  fsys,
+		files: New[string, *fileData](opts...),
+	}
+}
+
+// readFileData reads the stat result of name and, for a regular file, its
+// contents through a single open file.
+func readFileData(fsys fs.FS, name string) (*fileData, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	info := frozenInfo{
+		name:    stat.Name(),
+		size:    stat.Size(),
+		mode:    stat.Mode(),
+		modTime: stat.ModTime(),
+		sys:     stat.Sys(),
+	}
+	if !info.Mode().IsRegular() {
+		return &fileData{info: info}, nil
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return &fileData{info: info, data: data}, nil
+}
+
+// file returns the cached state of name, loading it on a miss.
+func (c *CachingFS) file(op, name string) (*fileData, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	file, err := c.files.Get(name)
+	if err != nil {
+		return nil, rename(unwrapLoadError(err), op)
+	}
+	return file, nil
+}
+
+// Open implements fs.FS. Regular files are served from memory.
+func (c *CachingFS) Open(name string) (fs.File, error) {
+	file, err := c.file("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if !file.info.Mode().IsRegular() {
+		return c.fsys.Open(name)
+	}
+	return &cachedFile{Reader: bytes.NewReader(file.data), info: file.info}, nil
+}
+
+// ReadFile implements fs.ReadFileFS. The returned slice is a copy the
+// caller may modify.
+func (c *CachingFS) ReadFile(name string) ([]byte, error) {
+	file, err := c.file("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	if !file.info.Mode().IsRegular() {
+		return fs.ReadFile(c.fsys, name)
+	}
+	return append([]byte(nil), file.data...), nil
+}
+
+// Stat implements fs.StatFS.
+func (c *CachingFS) Stat(name string) (fs.FileInfo, error) {
+	file, err := c.file("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return file.info, nil
+}
+
+// Invalidate removes the cached contents and stat results of names, so
+// changes to those files are picked up at once.
+func (c *CachingFS) Invalidate(names ...string) {
+	for _, name := range names {
+		c.files.Delete(name)
+	}
+}
+
+// InvalidateAll removes every cached file.
+func (c *CachingFS) InvalidateAll() {
+	c.files.Clear()
+}
+
+// frozenInfo is a copy of an fs.FileInfo, which some file systems compute
+// from the live file.
+type frozenInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+	sys     any
+}
+
+func (i frozenInfo) Name() string       { return i.name }
+func (i frozenInfo) Size() int64        { return i.size }
+func (i frozenInfo) Mode() fs.FileMode  { return i.mode }
+func (i frozenInfo) ModTime() time.Time { return i.modTime }
+func (i frozenInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i frozenInfo) Sys() any           { return i.sys }
+
+// cachedFile is an open file served from memory.
+type cachedFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *cachedFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *cachedFile) Close() error               { return nil }
+
+// unwrapLoadError returns the error of the underlying file system, which
+// fs.FS callers expect, instead of the LoadError wrapping it.
+func unwrapLoadError(err error) error {
+	var loadErr *LoadError
+	if errors.As(err, &loadErr) {
+		return loadErr.Err
+	}
+	return err
+}
+
+// rename returns err with its *fs.PathError operation set to op.
+func rename(err error, op string) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return &fs.PathError{Op: op, Path: pathErr.Path, Err: pathErr.Err}
+	}
+	return err
+}
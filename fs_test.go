@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FSTestSuite struct {
+	suite.Suite
+	clock *FakeClock
+	files fstest.MapFS
+	// opens counts the files opened in the underlying file system
+	opens map[string]int
+	fsys  *CachingFS
+}
+
+func TestFSSuite(t *testing.T) {
+	suite.Run(t, new(FSTestSuite))
+}
+
+// countingFS counts the files opened in an fs.FS.
+type countingFS struct {
+	fs.FS
+	opens map[string]int
+}
+
+func (c countingFS) Open(name string) (fs.File, error) {
+	c.opens[name]++
+	return c.FS.Open(name)
+}
+
+func (s *FSTestSuite) SetupTest() {
+	s.clock = NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s.files = fstest.MapFS{
+		"templates/index.html": {Data: []byte("<h1>index</h1>")},
+	}
+	s.opens = make(map[string]int)
+	s.fsys = CacheFS(countingFS{FS: s.files, opens: s.opens}, time.Minute, WithClock(s.clock))
+}
+
+// TestContentsAreCached verifies that files are read from the underlying
+// file system once per TTL
+func (s *FSTestSuite) TestContentsAreCached() {
+	for i := 0; i < 3; i++ {
+		data, err := fs.ReadFile(s.fsys, "templates/index.html")
+		s.NoError(err)
+		s.Equal("<h1>index</h1>", string(data))
+	}
+	f, err := s.fsys.Open("templates/index.html")
+	s.Require().NoError(err)
+	data, err := io.ReadAll(f)
+	s.NoError(err)
+	s.Equal("<h1>index</h1>", string(data))
+	s.NoError(f.Close())
+
+	s.Equal(1, s.opens["templates/index.html"], "The file is read and stat once")
+
+	s.files["templates/index.html"].Data = []byte("<h1>new</h1>")
+	s.clock.Advance(time.Minute)
+	data, err = fs.ReadFile(s.fsys, "templates/index.html")
+	s.NoError(err)
+	s.Equal("<h1>new</h1>", string(data), "Expired contents are read again")
+}
+
+// TestStat verifies that stat results are cached
+func (s *FSTestSuite) TestStat() {
+	info, err := fs.Stat(s.fsys, "templates/index.html")
+	s.Require().NoError(err)
+	s.Equal(int64(len("<h1>index</h1>")), info.Size())
+
+	_, err = fs.Stat(s.fsys, "templates/index.html")
+	s.NoError(err)
+	s.Equal(1, s.opens["templates/index.html"])
+}
+
+// TestStatMatchesContents verifies that the stat result of an open file
+// describes the contents it reads
+func (s *FSTestSuite) TestStatMatchesContents() {
+	_, err := fs.Stat(s.fsys, "templates/index.html")
+	s.Require().NoError(err)
+	s.files["templates/index.html"].Data = []byte("<h1>a longer index</h1>")
+
+	f, err := s.fsys.Open("templates/index.html")
+	s.Require().NoError(err)
+	defer f.Close()
+	info, err := f.Stat()
+	s.Require().NoError(err)
+	data, err := io.ReadAll(f)
+	s.NoError(err)
+	s.Equal(info.Size(), int64(len(data)))
+}
+
+// TestErrors verifies that missing files report fs errors and are not
+// cached
+func (s *FSTestSuite) TestErrors() {
+	_, err := s.fsys.Open("missing.html")
+	s.ErrorIs(err, fs.ErrNotExist)
+
+	s.files["missing.html"] = &fstest.MapFile{Data: []byte("found")}
+	data, err := fs.ReadFile(s.fsys, "missing.html")
+	s.NoError(err)
+	s.Equal("found", string(data))
+
+	_, err = s.fsys.Open("../escape")
+	s.ErrorIs(err, fs.ErrInvalid)
+}
+
+// TestInvalidate verifies that invalidated files are read again
+func (s *FSTestSuite) TestInvalidate() {
+	_, _ = fs.ReadFile(s.fsys, "templates/index.html")
+	s.files["templates/index.html"].Data = []byte("<h1>new</h1>")
+	s.fsys.Invalidate("templates/index.html")
+
+	data, err := fs.ReadFile(s.fsys, "templates/index.html")
+	s.NoError(err)
+	s.Equal("<h1>new</h1>", string(data))
+}
+
+// TestConformance runs the standard file system checks
+func (s *FSTestSuite) TestConformance() {
+	s.NoError(fstest.TestFS(s.fsys, "templates/index.html"))
+}
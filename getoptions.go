@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"reflect"
+	"time"
+)
+
+// WithForceRefresh makes a Get call skip the cached value and call the
+// getter, replacing the cached entry with the result. With
+// WithMinRefreshInterval, entries loaded recently are served as is.
+func WithForceRefresh() Option {
+	return func(o *options) {
+		o.forceRefresh = true
+	}
+}
+
+// WithMinRefreshInterval throttles WithForceRefresh: a forced refresh of
+// an entry loaded or written less than interval ago is served from the
+// cache instead of calling the getter, protecting the origin from clients
+// that refresh repeatedly. Zero disables throttling.
+func WithMinRefreshInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.minRefreshInterval = interval
+	}
+}
+
+// refreshThrottled reports whether a forced refresh of key must be served
+// from the cache because its entry is more recent than the minimum refresh
+// interval.
+func (s *store) refreshThrottled(valueType reflect.Type, key any) bool {
+	interval := s.cfg().minRefreshInterval
+	if interval <= 0 {
+		return false
+	}
+	e, ok := s.lookupEntry(valueType, key)
+	return ok && s.now().Sub(e.storedAt) < interval
+}
+
+// WithSkipCache makes a Get call bypass the cache entirely: the getter is
+// called and its result is returned without being stored.
+func WithSkipCache() Option {
+	return func(o *options) {
+		o.skipCache = true
+	}
+}
+
+// WithTags labels the entry stored by a Get call so it can later be removed
+// with InvalidateTags.
+func WithTags(tags ...string) Option {
+	return func(o *options) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// WithTimeout bounds how long a Get call waits for a value. When it runs
+// out, Get returns ErrTimeout; the getter keeps running and its result is
+// cached once it returns.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// InvalidateTags removes every entry of the package-level cache labeled
+// with at least one of tags and returns how many were removed.
+func InvalidateTags(tags ...string) int {
+	s := globalStore()
+	s.audit(AuditInvalidateTags, nil, nil, tags, "")
+	return s.invalidateTags(tags)
+}
+
+// invalidateTags removes the entries labeled with any of tags.
+func (s *store) invalidateTags(tags []string) int {
+	if len(tags) == 0 {
+		return 0
+	}
+	wanted := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		wanted[tag] = true
+	}
+
+	s.lock()
+	defer s.mu.Unlock()
+	var matched []entryKey
+	s.rangeAllLocked(func(valueType reflect.Type, key any, e *entry) bool {
+		for _, tag := range e.tags {
+			if wanted[tag] {
+				matched = append(matched, entryKey{valueType, key})
+				break
+			}
+		}
+		return true
+	})
+	for _, k := range matched {
+		s.cancelFlightsLocked(k)
+		s.removeLocked(k.valueType, k.key, RemovalDeleted)
+	}
+	return len(matched)
+}
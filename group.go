@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// call is a load shared by the concurrent callers of one key.
+type call struct {
+	done chan struct{}
+	// val and err are the outcome of the load, set before done is closed
+	val any
+	err error
+	// callers counts the callers sharing the load, guarded by the mutex
+	// of the group
+	callers int
+}
+
+// callKey identifies a load within a group. Refreshes must not join a
+// load that may serve the cached value, so they run apart.
+type callKey[K comparable] struct {
+	key     K
+	refresh bool
+}
+
+// callGroup coalesces the concurrent loads of the keys of one key type and
+// one value type. Keys are compared as they are, not through a string
+// rendering, so distinct keys never share a load.
+type callGroup[K comparable] struct {
+	mu    sync.Mutex
+	calls map[callKey[K]]*call
+}
+
+// groupID identifies the callGroup of a key type and a value type.
+type groupID struct {
+	keyType   reflect.Type
+	valueType reflect.Type
+	// do separates the calls of Do, which run functions of their own,
+	// from loads
+	do bool
+}
+
+// groupFor returns the callGroup for keys of type K and values of
+// valueType, creating it on first use.
+func groupFor[K comparable](s *store, valueType reflect.Type) *callGroup[K] {
+	var zero K
+	return groupOf[K](s, groupID{keyType: getTypeOf(zero), valueType: valueType})
+}
+
+// groupOf returns the callGroup identified by id, creating it on first
+// use.
+func groupOf[K comparable](s *store, id groupID) *callGroup[K] {
+	if g, ok := s.groups.Load(id); ok {
+		return g.(*callGroup[K])
+	}
+	g, _ := s.groups.LoadOrStore(id, &callGroup[K]{})
+	return g.(*callGroup[K])
+}
+
+// join returns the load of k in progress, or registers a new one, in which
+// case leader is true and the caller must run it with do.
+func (g *callGroup[K]) join(k callKey[K]) (c *call, leader bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.calls[k]; ok {
+		c.callers++
+		return c, false
+	}
+	if g.calls == nil {
+		g.calls = make(map[callKey[K]]*call)
+	}
+	c = &call{done: make(chan struct{}), callers: 1}
+	g.calls[k] = c
+	return c, true
+}
+
+// do runs fn as the load c of k, hands its outcome to the callers waiting
+// for it and returns how many callers it served. If fn panics, the waiting
+// callers receive an error and the panic goes on in the caller of do.
+func (g *callGroup[K]) do(k callKey[K], c *call, fn func() (any, error)) int {
+	defer func() {
+		if r := recover(); r != nil {
+			g.finish(k, c, nil, fmt.Errorf("cache: load panicked: %v", r))
+			panic(r)
+		}
+	}()
+	val, err := fn()
+	return g.finish(k, c, val, err)
+}
+
+func (g *callGroup[K]) finish(k callKey[K], c *call, val any, err error) int {
+	g.mu.Lock()
+	if g.calls[k] == c {
+		delete(g.calls, k)
+	}
+	callers := c.callers
+	g.mu.Unlock()
+
+	c.val, c.err = val, err
+	close(c.done)
+	return callers
+}
+
+// forget makes later loads of k start afresh instead of joining c. Callers
+// already waiting for c still receive its outcome.
+func (g *callGroup[K]) forget(k callKey[K], c *call) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls[k] == c {
+		delete(g.calls, k)
+	}
+}
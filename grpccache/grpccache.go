@@ -0,0 +1,208 @@
+// Package grpccache caches the responses of unary gRPC calls on the client
+// side.
+//
+// Only methods registered with a TTL are cached. Responses are keyed by
+// the deterministic encoding of the request message, so identical requests
+// share a response. Calls carrying credentials bypass the cache, and
+// outgoing metadata naming the caller, such as a tenant header, must be
+// declared with KeyMetadata so that callers never share responses:
+//
+//	rc := grpccache.New(map[string]time.Duration{
+//		"/catalog.Catalog/GetProduct": time.Minute,
+//	}).KeyMetadata("x-tenant-id")
+//	conn, err := grpc.NewClient(target, grpc.WithUnaryInterceptor(rc.UnaryClientInterceptor()))
+package grpccache
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexanderbotero/cache"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// requestKey identifies a request by the hashes of its encoding and of
+// the outgoing metadata declared with KeyMetadata.
+type requestKey struct {
+	request  [sha256.Size]byte
+	metadata [sha256.Size]byte
+}
+
+// ResponseCache caches the responses of the unary methods it was created
+// with. It is safe for concurrent use.
+type ResponseCache struct {
+	// methods holds a cache of encoded responses per method; it is never
+	// modified after New
+	methods map[string]*cache.Cache[requestKey, []byte]
+	// metadataKeys are the sorted outgoing metadata keys responses vary
+	// on, set by KeyMetadata
+	metadataKeys []string
+}
+
+// New returns a ResponseCache caching the responses of methods, given by
+// full name ("/package.Service/Method"), for their TTL. opts configure the
+// cache of every method, for instance cache.WithMaxEntries; the TTL of the
+// method always applies.
+func New(methods map[string]time.Duration, opts ...cache.Option) *ResponseCache {
+	rc := &ResponseCache{methods: make(map[string]*cache.Cache[requestKey, []byte], len(methods))}
+	for method, ttl := range methods {
+		methodOpts := append(append([]cache.Option(nil), opts...), cache.WithTTL(ttl))
+		rc.methods[method] = cache.New[requestKey, []byte](methodOpts...)
+	}
+	return rc
+}
+
+// KeyMetadata makes cached responses vary on the values of the outgoing
+// metadata keys, such as a tenant or locale header, so that calls
+// differing in them never share a response. Listing "authorization" caches
+// the responses of credentialed calls per token instead of bypassing the
+// cache for them. It must be called before the interceptor is used and
+// returns rc.
+func (rc *ResponseCache) KeyMetadata(keys ...string) *ResponseCache {
+	for _, key := range keys {
+		rc.metadataKeys = append(rc.metadataKeys, strings.ToLower(key))
+	}
+	sort.Strings(rc.metadataKeys)
+	return rc
+}
+
+// UnaryClientInterceptor returns the interceptor serving cached responses.
+// A cached response is only served while ctx is live; on a miss the call
+// goes to the server under ctx, and only successful responses are cached.
+// Calls with an authorization metadata entry or per-call credentials go to
+// the server uncached, unless authorization was declared with KeyMetadata;
+// credentials attached to the connection are not visible to the
+// interceptor, so connections using them should not share a
+// ResponseCache.
+func (rc *ResponseCache) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		c, ok := rc.methods[method]
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		reqMsg, ok := req.(proto.Message)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		replyMsg, ok := reply.(proto.Message)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if rc.credentialed(ctx, opts) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		key, err := keyOf(reqMsg)
+		if err != nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		key.metadata = rc.metadataHash(ctx)
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if data, err := c.Get(key); err == nil {
+			if err := proto.Unmarshal(data, replyMsg); err == nil {
+				return nil
+			}
+			c.Delete(key)
+		}
+
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return err
+		}
+		if data, err := proto.Marshal(replyMsg); err == nil {
+			c.Set(key, data)
+		}
+		return nil
+	}
+}
+
+// Invalidate removes the cached responses of method to req, whatever the
+// metadata they were cached for. It inspects every cached response of
+// method.
+func (rc *ResponseCache) Invalidate(method string, req proto.Message) error {
+	c, ok := rc.methods[method]
+	if !ok {
+		return nil
+	}
+	target, err := keyOf(req)
+	if err != nil {
+		return err
+	}
+	var stale []requestKey
+	c.Snapshot().Range(func(key requestKey, _ []byte) bool {
+		if key.request == target.request {
+			stale = append(stale, key)
+		}
+		return true
+	})
+	for _, key := range stale {
+		c.Delete(key)
+	}
+	return nil
+}
+
+// InvalidateMethod removes every cached response of method.
+func (rc *ResponseCache) InvalidateMethod(method string) {
+	if c, ok := rc.methods[method]; ok {
+		c.Clear()
+	}
+}
+
+// Stats returns a summary of the cache of method, or the zero Statistics
+// if method is not cached.
+func (rc *ResponseCache) Stats(method string) cache.Statistics {
+	if c, ok := rc.methods[method]; ok {
+		return c.Stats()
+	}
+	return cache.Statistics{}
+}
+
+// keyOf returns the cache key of req.
+func keyOf(req proto.Message) (requestKey, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return requestKey{}, fmt.Errorf("grpccache: encoding request: %w", err)
+	}
+	return requestKey{request: sha256.Sum256(data)}, nil
+}
+
+// credentialed reports whether a call made with ctx and opts carries
+// credentials that are not part of the cache key.
+func (rc *ResponseCache) credentialed(ctx context.Context, opts []grpc.CallOption) bool {
+	if i := sort.SearchStrings(rc.metadataKeys, "authorization"); i < len(rc.metadataKeys) && rc.metadataKeys[i] == "authorization" {
+		return false
+	}
+	for _, opt := range opts {
+		if _, ok := opt.(grpc.PerRPCCredsCallOption); ok {
+			return true
+		}
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return len(md.Get("authorization")) > 0
+}
+
+// metadataHash returns the hash of the values of the metadata keys of rc
+// in the outgoing metadata of ctx.
+func (rc *ResponseCache) metadataHash(ctx context.Context) [sha256.Size]byte {
+	if len(rc.metadataKeys) == 0 {
+		return [sha256.Size]byte{}
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	var b strings.Builder
+	for _, key := range rc.metadataKeys {
+		values := md.Get(key)
+		fmt.Fprintf(&b, "%s=%d", key, len(values))
+		for _, value := range values {
+			fmt.Fprintf(&b, ":%d:%s", len(value), value)
+		}
+		b.WriteByte(0)
+	}
+	return sha256.Sum256([]byte(b.String()))
+}
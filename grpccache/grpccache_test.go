@@ -0,0 +1,150 @@
+package grpccache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	cachedMethod   = "/test.Service/Cached"
+	uncachedMethod = "/test.Service/Uncached"
+)
+
+type GRPCCacheTestSuite struct {
+	suite.Suite
+	rc          *ResponseCache
+	interceptor grpc.UnaryClientInterceptor
+	// calls counts the calls reaching the server
+	calls int
+	// fail makes the server fail
+	fail bool
+}
+
+func TestGRPCCacheSuite(t *testing.T) {
+	suite.Run(t, new(GRPCCacheTestSuite))
+}
+
+func (s *GRPCCacheTestSuite) SetupTest() {
+	s.rc = New(map[string]time.Duration{cachedMethod: time.Minute})
+	s.interceptor = s.rc.UnaryClientInterceptor()
+	s.calls = 0
+	s.fail = false
+}
+
+// invoke performs a call of method through the interceptor; the server
+// echoes the request in upper case.
+func (s *GRPCCacheTestSuite) invoke(ctx context.Context, method, req string) (string, error) {
+	reply := &wrapperspb.StringValue{}
+	err := s.interceptor(ctx, method, wrapperspb.String(req), reply, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			s.calls++
+			if s.fail {
+				return errors.New("unavailable")
+			}
+			reply.(*wrapperspb.StringValue).Value = "reply to " + req.(*wrapperspb.StringValue).Value
+			return nil
+		})
+	return reply.Value, err
+}
+
+// TestResponsesAreCached verifies that repeated requests are served from
+// the cache, per request
+func (s *GRPCCacheTestSuite) TestResponsesAreCached() {
+	for i := 0; i < 3; i++ {
+		reply, err := s.invoke(context.Background(), cachedMethod, "a")
+		s.NoError(err)
+		s.Equal("reply to a", reply)
+	}
+	_, _ = s.invoke(context.Background(), cachedMethod, "b")
+
+	s.Equal(2, s.calls)
+	s.Equal(uint64(2), s.rc.Stats(cachedMethod).Hits)
+}
+
+// TestUncachedMethods verifies that only registered methods are cached
+func (s *GRPCCacheTestSuite) TestUncachedMethods() {
+	_, _ = s.invoke(context.Background(), uncachedMethod, "a")
+	_, _ = s.invoke(context.Background(), uncachedMethod, "a")
+	s.Equal(2, s.calls)
+}
+
+// TestErrorsAreNotCached verifies that failed calls are retried
+func (s *GRPCCacheTestSuite) TestErrorsAreNotCached() {
+	s.fail = true
+	_, err := s.invoke(context.Background(), cachedMethod, "a")
+	s.Error(err)
+
+	s.fail = false
+	reply, err := s.invoke(context.Background(), cachedMethod, "a")
+	s.NoError(err)
+	s.Equal("reply to a", reply)
+	s.Equal(2, s.calls)
+}
+
+// TestContextIsRespected verifies that a done context fails even on a hit
+func (s *GRPCCacheTestSuite) TestContextIsRespected() {
+	_, _ = s.invoke(context.Background(), cachedMethod, "a")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.invoke(ctx, cachedMethod, "a")
+	s.ErrorIs(err, context.Canceled)
+}
+
+// TestInvalidate verifies that invalidated responses are fetched again
+func (s *GRPCCacheTestSuite) TestInvalidate() {
+	_, _ = s.invoke(context.Background(), cachedMethod, "a")
+	_, _ = s.invoke(context.Background(), cachedMethod, "b")
+
+	s.NoError(s.rc.Invalidate(cachedMethod, wrapperspb.String("a")))
+	_, _ = s.invoke(context.Background(), cachedMethod, "a")
+	_, _ = s.invoke(context.Background(), cachedMethod, "b")
+	s.Equal(3, s.calls)
+
+	s.rc.InvalidateMethod(cachedMethod)
+	_, _ = s.invoke(context.Background(), cachedMethod, "b")
+	s.Equal(4, s.calls)
+}
+
+// TestCredentialedCalls verifies that calls carrying credentials are not
+// served from or stored in the cache
+func (s *GRPCCacheTestSuite) TestCredentialedCalls() {
+	alice := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer alice")
+	_, _ = s.invoke(alice, cachedMethod, "a")
+	_, _ = s.invoke(alice, cachedMethod, "a")
+	s.Equal(2, s.calls)
+	_, _ = s.invoke(context.Background(), cachedMethod, "a")
+	s.Equal(3, s.calls, "Credentialed responses are not stored")
+}
+
+// TestKeyMetadata verifies that responses vary on the declared metadata
+// and are invalidated for all of its values
+func (s *GRPCCacheTestSuite) TestKeyMetadata() {
+	s.rc.KeyMetadata("X-Tenant-ID")
+	acme := metadata.AppendToOutgoingContext(context.Background(), "x-tenant-id", "acme")
+	globex := metadata.AppendToOutgoingContext(context.Background(), "x-tenant-id", "globex")
+
+	_, _ = s.invoke(acme, cachedMethod, "a")
+	_, _ = s.invoke(acme, cachedMethod, "a")
+	s.Equal(1, s.calls)
+	_, _ = s.invoke(globex, cachedMethod, "a")
+	s.Equal(2, s.calls, "Tenants do not share responses")
+
+	s.NoError(s.rc.Invalidate(cachedMethod, wrapperspb.String("a")))
+	_, _ = s.invoke(acme, cachedMethod, "a")
+	_, _ = s.invoke(globex, cachedMethod, "a")
+	s.Equal(4, s.calls)
+
+	s.rc.KeyMetadata("authorization")
+	alice := metadata.AppendToOutgoingContext(acme, "authorization", "Bearer alice")
+	_, _ = s.invoke(alice, cachedMethod, "a")
+	_, _ = s.invoke(alice, cachedMethod, "a")
+	s.Equal(5, s.calls, "Declared credentials are part of the key")
+}
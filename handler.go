@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxResponseSize is the largest response body a CachingHandler
+// caches unless set otherwise with MaxResponseSize.
+const DefaultMaxResponseSize = 1 << 20
+
+// CachingHandler is HTTP middleware caching the responses rendered by the
+// handler it wraps. It is created with Handler.
+type CachingHandler struct {
+	next  http.Handler
+	keyFn func(*http.Request) string
+	ttl   time.Duration
+	// maxSize is the largest body cached, in bytes
+	maxSize int
+	cache   *Cache[string, *cachedResponse]
+}
+
+// Handler returns middleware serving GET and HEAD requests from a cache of
+// the responses next renders: status, headers and body. keyFn maps a
+// request to its cache key, nil meaning the request URI; an empty key
+// leaves the request uncached. Responses are kept for ttl, or for the
+// max-age next sets in Cache-Control; wrap each route separately to give
+// it its own TTL. Only 200 responses are cached, and never those marked
+// no-store or private or those setting cookies or sending Vary, which
+// differ between clients. Requests carrying an Authorization or Cookie
+// header are passed to next without touching the cache. Bodies larger than
+// DefaultMaxResponseSize are not cached either; see MaxResponseSize. opts
+// configure the underlying cache, for instance WithMaxEntries.
+//
+// Responses are written through to the client as next renders them. A
+// copy is kept only while the response may still be cached, so uncacheable
+// and oversized responses are streamed without being buffered.
+//
+// Concurrent misses of the same key are all passed to next.
+func Handler(next http.Handler, keyFn func(*http.Request) string, ttl time.Duration, opts ...Option) *CachingHandler {
+	if keyFn == nil {
+		keyFn = func(r *http.Request) string { return r.URL.RequestURI() }
+	}
+	return &CachingHandler{
+		next:    next,
+		keyFn:   keyFn,
+		ttl:     ttl,
+		maxSize: DefaultMaxResponseSize,
+		cache:   New[string, *cachedResponse](opts...),
+	}
+}
+
+// MaxResponseSize sets the largest response body cached, in bytes; larger
+// responses are passed through without being cached. It returns h and
+// must be called before h serves requests.
+func (h *CachingHandler) MaxResponseSize(size int) *CachingHandler {
+	h.maxSize = size
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *CachingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	if r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "" {
+		// Responses to credentialed requests are personal
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	key := h.keyFn(r)
+	if key == "" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	key = r.Method + " " + key
+
+	if cached, err := h.cache.Get(key); err == nil {
+		header := w.Header()
+		for name, values := range cached.header.Clone() {
+			header[name] = values
+		}
+		w.WriteHeader(cached.status)
+		_, _ = w.Write(cached.body)
+		return
+	}
+
+	rec := &responseRecorder{ResponseWriter: w, maxSize: h.maxSize}
+	h.next.ServeHTTP(rec, r)
+	if rec.status == 0 {
+		rec.WriteHeader(http.StatusOK)
+	}
+	if !rec.keep {
+		return
+	}
+	directives := cacheControl(rec.header)
+	ttl := h.ttl
+	if maxAge, ok := seconds(directives, "max-age"); ok {
+		ttl = maxAge
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	// A frozen cache keeps its responses; the error says nothing else
+	_ = h.cache.Set(key, &cachedResponse{
+		status: rec.status,
+		header: rec.header,
+		body:   rec.body.Bytes(),
+	}, WithTTL(ttl))
+}
+
+// Invalidate removes the cached responses of keys, as returned by keyFn.
+func (h *CachingHandler) Invalidate(keys ...string) {
+	for _, key := range keys {
+		h.cache.Delete(http.MethodGet + " " + key)
+		h.cache.Delete(http.MethodHead + " " + key)
+	}
+}
+
+// InvalidateAll removes every cached response.
+func (h *CachingHandler) InvalidateAll() {
+	h.cache.Clear()
+}
+
+// Stats returns a summary of the response cache.
+func (h *CachingHandler) Stats() Statistics {
+	return h.cache.Stats()
+}
+
+// cacheable reports whether a response with status and header may be
+// cached, judging by its status and headers alone.
+func cacheable(status int, header http.Header) bool {
+	if status != http.StatusOK {
+		return false
+	}
+	if len(header.Values("Set-Cookie")) > 0 || len(header.Values("Vary")) > 0 {
+		return false
+	}
+	directives := cacheControl(header)
+	if _, noStore := directives["no-store"]; noStore {
+		return false
+	}
+	if _, private := directives["private"]; private {
+		return false
+	}
+	return true
+}
+
+// responseRecorder passes a response through to the client while keeping
+// a copy of it, as long as the response may be cached.
+type responseRecorder struct {
+	http.ResponseWriter
+	maxSize int
+	status  int
+	header  http.Header
+	// keep tells whether the body is still being copied
+	keep bool
+	body bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+		rec.header = rec.ResponseWriter.Header().Clone()
+		rec.keep = cacheable(status, rec.header)
+		if length, err := strconv.ParseInt(rec.header.Get("Content-Length"), 10, 64); err == nil && length > int64(rec.maxSize) {
+			rec.keep = false
+		}
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.WriteHeader(http.StatusOK)
+	}
+	if rec.keep {
+		if rec.body.Len()+len(p) > rec.maxSize {
+			// Too large to cache: stop copying and drop the copy
+			rec.keep = false
+			rec.body = bytes.Buffer{}
+		} else {
+			rec.body.Write(p)
+		}
+	}
+	return rec.ResponseWriter.Write(p)
+}
+
+// Flush lets streaming handlers flush through the recorder.
+func (rec *responseRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerTestSuite struct {
+	suite.Suite
+	clock *FakeClock
+	// renders counts the responses rendered by the wrapped handler
+	renders int
+	// cacheControl is the Cache-Control header the handler sets
+	cacheControl string
+	// extra holds more response headers the handler sets
+	extra   http.Header
+	handler *CachingHandler
+}
+
+func TestHandlerSuite(t *testing.T) {
+	suite.Run(t, new(HandlerTestSuite))
+}
+
+func (s *HandlerTestSuite) SetupTest() {
+	s.clock = NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s.renders = 0
+	s.cacheControl = ""
+	s.extra = nil
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.renders++
+		if s.cacheControl != "" {
+			w.Header().Set("Cache-Control", s.cacheControl)
+		}
+		for name, values := range s.extra {
+			w.Header()[name] = values
+		}
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "page "+r.URL.Path)
+	})
+	s.handler = Handler(next, nil, time.Minute, WithClock(s.clock))
+}
+
+func (s *HandlerTestSuite) serve(method, path string) *http.Response {
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec.Result()
+}
+
+func (s *HandlerTestSuite) body(resp *http.Response) string {
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	return string(body)
+}
+
+// TestResponsesAreCached verifies that a cached response is replayed with
+// its status, headers and body until it expires
+func (s *HandlerTestSuite) TestResponsesAreCached() {
+	s.Equal("page /a", s.body(s.serve(http.MethodGet, "/a")))
+	resp := s.serve(http.MethodGet, "/a")
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal("text/plain", resp.Header.Get("Content-Type"))
+	s.Equal("page /a", s.body(resp))
+	s.Equal(1, s.renders)
+
+	s.clock.Advance(time.Minute)
+	s.serve(http.MethodGet, "/a")
+	s.Equal(2, s.renders, "Expired responses are rendered again")
+}
+
+// TestUncachedResponses verifies that unsafe methods, errors and no-store
+// responses are not cached
+func (s *HandlerTestSuite) TestUncachedResponses() {
+	s.serve(http.MethodPost, "/a")
+	s.serve(http.MethodPost, "/a")
+	s.serve(http.MethodGet, "/missing")
+	s.serve(http.MethodGet, "/missing")
+	s.Equal(4, s.renders)
+
+	s.cacheControl = "no-store"
+	s.serve(http.MethodGet, "/b")
+	s.serve(http.MethodGet, "/b")
+	s.Equal(6, s.renders)
+}
+
+// TestMaxAge verifies that the handler can set the TTL of its response
+func (s *HandlerTestSuite) TestMaxAge() {
+	s.cacheControl = "max-age=10"
+	s.serve(http.MethodGet, "/a")
+	s.clock.Advance(11 * time.Second)
+	s.serve(http.MethodGet, "/a")
+	s.Equal(2, s.renders)
+}
+
+// TestInvalidate verifies that invalidated responses are rendered again
+func (s *HandlerTestSuite) TestInvalidate() {
+	s.serve(http.MethodGet, "/a")
+	s.serve(http.MethodGet, "/b")
+	s.handler.Invalidate("/a")
+	s.serve(http.MethodGet, "/a")
+	s.serve(http.MethodGet, "/b")
+	s.Equal(3, s.renders)
+
+	s.handler.InvalidateAll()
+	s.serve(http.MethodGet, "/b")
+	s.Equal(4, s.renders)
+}
+
+// TestCredentialedRequests verifies that requests carrying credentials are
+// neither served from nor stored in the cache
+func (s *HandlerTestSuite) TestCredentialedRequests() {
+	s.serve(http.MethodGet, "/a")
+	for _, header := range []string{"Authorization", "Cookie"} {
+		req := httptest.NewRequest(http.MethodGet, "/a", nil)
+		req.Header.Set(header, "secret")
+		s.handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	s.Equal(3, s.renders, "Credentialed requests reach the handler")
+
+	req := httptest.NewRequest(http.MethodGet, "/b", nil)
+	req.Header.Set("Cookie", "session=alice")
+	s.handler.ServeHTTP(httptest.NewRecorder(), req)
+	s.serve(http.MethodGet, "/b")
+	s.Equal(5, s.renders, "Their responses are not stored")
+}
+
+// TestPersonalizedResponses verifies that responses setting cookies or
+// sending Vary are not cached
+func (s *HandlerTestSuite) TestPersonalizedResponses() {
+	s.extra = http.Header{"Set-Cookie": {"session=alice"}}
+	s.serve(http.MethodGet, "/a")
+	resp := s.serve(http.MethodGet, "/a")
+	s.Equal(2, s.renders)
+	s.Equal("session=alice", resp.Header.Get("Set-Cookie"))
+
+	s.extra = http.Header{"Vary": {"Accept-Language"}}
+	s.serve(http.MethodGet, "/b")
+	s.serve(http.MethodGet, "/b")
+	s.Equal(4, s.renders)
+}
+
+// TestFrozenCache verifies that responses go through Set and respect a
+// frozen cache
+func (s *HandlerTestSuite) TestFrozenCache() {
+	s.handler.cache.Freeze()
+	s.serve(http.MethodGet, "/a")
+	s.serve(http.MethodGet, "/a")
+	s.Equal(2, s.renders)
+}
+
+// TestLargeResponses verifies that bodies over the maximum size are
+// passed through whole without being cached
+func (s *HandlerTestSuite) TestLargeResponses() {
+	s.handler.MaxResponseSize(len("page /a"))
+	s.Equal("page /a", s.body(s.serve(http.MethodGet, "/a")))
+	s.Equal("page /a", s.body(s.serve(http.MethodGet, "/a")))
+	s.Equal(1, s.renders, "A body of the maximum size is cached")
+
+	s.Equal("page /long", s.body(s.serve(http.MethodGet, "/long")))
+	s.Equal("page /long", s.body(s.serve(http.MethodGet, "/long")))
+	s.Equal(3, s.renders)
+}
+
+// TestWriteThrough verifies that responses which cannot be cached are not
+// copied while they are written
+func (s *HandlerTestSuite) TestWriteThrough() {
+	w := httptest.NewRecorder()
+	w.Header().Set("Cache-Control", "no-store")
+	rec := &responseRecorder{ResponseWriter: w, maxSize: DefaultMaxResponseSize}
+	_, _ = io.WriteString(rec, "secret")
+	s.Zero(rec.body.Len())
+	s.Equal("secret", w.Body.String())
+
+	w = httptest.NewRecorder()
+	w.Header().Set("Content-Length", "20")
+	rec = &responseRecorder{ResponseWriter: w, maxSize: 10}
+	_, _ = io.WriteString(rec, "page")
+	s.Zero(rec.body.Len(), "A declared length over the maximum stops the copy")
+
+	w = httptest.NewRecorder()
+	rec = &responseRecorder{ResponseWriter: w, maxSize: 10}
+	_, _ = io.WriteString(rec, "page")
+	_, _ = io.WriteString(rec, " /streamed")
+	s.Zero(rec.body.Len(), "The copy is dropped once the body outgrows the maximum")
+	s.Equal("page /streamed", w.Body.String())
+}
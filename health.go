@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Pinger is implemented by stores that offer a cheap reachability check.
+// HealthCheck uses Ping when available and a Get of a probe key otherwise.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Health is the result of a health check.
+type Health struct {
+	// Healthy reports whether every component passed its check.
+	Healthy bool
+	// Components holds the result for each checked component.
+	Components []ComponentHealth
+}
+
+// ComponentHealth is the health of one component of a cache, such as its
+// backing store or its janitor.
+type ComponentHealth struct {
+	// Name identifies the component: "store", "janitor" or "writeBehind".
+	Name string
+	// Healthy reports whether the component passed its check.
+	Healthy bool
+	// Err explains why the component is unhealthy.
+	Err error
+	// Latency is how long the check took, for checks doing I/O.
+	Latency time.Duration
+}
+
+// healthProbeKey is read from stores that do not implement Pinger.
+const healthProbeKey = "__cache_health__"
+
+// HealthCheck reports the health of the package-level cache's background
+// workers.
+func HealthCheck(ctx context.Context) Health {
+	return globalStore().healthCheck(ctx)
+}
+
+// HealthCheck verifies that the backing store is reachable within ctx and
+// that the janitor and write-behind worker, when configured, are running.
+// It is meant for readiness probes.
+func (c *Cache[K, V]) HealthCheck(ctx context.Context) Health {
+	return c.s.healthCheck(ctx)
+}
+
+func (s *store) healthCheck(ctx context.Context) Health {
+	var components []ComponentHealth
+	if s.remote != nil {
+		components = append(components, s.checkRemote(ctx))
+	}
+
+	s.workersMu.Lock()
+	j := s.janitor
+	s.workersMu.Unlock()
+	if j != nil {
+		components = append(components, workerHealth("janitor", j.check()))
+	}
+	if s.writeBehind != nil {
+		components = append(components, workerHealth("writeBehind", s.writeBehind.check()))
+	}
+
+	h := Health{Healthy: true, Components: components}
+	for _, c := range components {
+		if !c.Healthy {
+			h.Healthy = false
+		}
+	}
+	return h
+}
+
+// checkRemote pings the backing store.
+func (s *store) checkRemote(ctx context.Context) ComponentHealth {
+	start := time.Now()
+	var err error
+	if p, ok := s.remote.(Pinger); ok {
+		err = p.Ping(ctx)
+	} else {
+		_, _, err = s.remote.Get(ctx, s.keyPrefix+healthProbeKey)
+	}
+	return ComponentHealth{Name: "store", Healthy: err == nil, Err: err, Latency: time.Since(start)}
+}
+
+func workerHealth(name string, err error) ComponentHealth {
+	return ComponentHealth{Name: name, Healthy: err == nil, Err: err}
+}
+
+// check reports whether the janitor is running and sweeping on schedule.
+func (j *janitor) check() error {
+	select {
+	case <-j.done:
+		return ErrStopped
+	default:
+	}
+	if last := time.Unix(0, j.lastSweep.Load()); time.Since(last) > 3*j.interval {
+		return fmt.Errorf("no sweep since %v", last.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// check reports whether the worker is running.
+func (w *writeBehind) check() error {
+	select {
+	case <-w.done:
+		return ErrStopped
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// unreachableStore fails every read
+type unreachableStore struct {
+	*memoryStore
+}
+
+func (unreachableStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+// pingingStore implements Pinger
+type pingingStore struct {
+	*memoryStore
+	pings int
+}
+
+func (p *pingingStore) Ping(ctx context.Context) error {
+	p.pings++
+	return nil
+}
+
+type HealthTestSuite struct {
+	suite.Suite
+}
+
+func TestHealthSuite(t *testing.T) {
+	suite.Run(t, new(HealthTestSuite))
+}
+
+func (s *HealthTestSuite) component(h Health, name string) ComponentHealth {
+	for _, c := range h.Components {
+		if c.Name == name {
+			return c
+		}
+	}
+	s.Failf("missing component", "no %q in %+v", name, h.Components)
+	return ComponentHealth{}
+}
+
+// TestHealthyCache verifies that a running cache reports all components
+func (s *HealthTestSuite) TestHealthyCache() {
+	c := New[int, string](
+		WithStore(newMemoryStore()),
+		WithWriteBehind(WriteBehindConfig{}),
+		WithJanitor(time.Minute),
+	)
+	defer c.Close()
+
+	h := c.HealthCheck(context.Background())
+	s.True(h.Healthy)
+	s.Len(h.Components, 3)
+	s.True(s.component(h, "store").Healthy)
+	s.True(s.component(h, "janitor").Healthy)
+	s.True(s.component(h, "writeBehind").Healthy)
+}
+
+// TestUnreachableStore verifies that store errors make the cache unhealthy
+func (s *HealthTestSuite) TestUnreachableStore() {
+	c := New[int, string](WithStore(unreachableStore{newMemoryStore()}))
+
+	h := c.HealthCheck(context.Background())
+	s.False(h.Healthy)
+	s.EqualError(s.component(h, "store").Err, "connection refused")
+}
+
+// TestPingerIsPreferred verifies that Ping replaces the probe read
+func (s *HealthTestSuite) TestPingerIsPreferred() {
+	store := &pingingStore{memoryStore: newMemoryStore()}
+	c := New[int, string](WithStore(store))
+
+	s.True(c.HealthCheck(context.Background()).Healthy)
+	s.Equal(1, store.pings)
+}
+
+// TestShutdownCacheIsUnhealthy verifies that stopped workers are reported
+func (s *HealthTestSuite) TestShutdownCacheIsUnhealthy() {
+	c := New[int, string](
+		WithStore(newMemoryStore()),
+		WithWriteBehind(WriteBehindConfig{}),
+		WithJanitor(time.Minute),
+	)
+	s.NoError(c.Shutdown(context.Background()))
+
+	h := c.HealthCheck(context.Background())
+	s.False(h.Healthy)
+	s.ErrorIs(s.component(h, "janitor").Err, ErrStopped)
+	s.ErrorIs(s.component(h, "writeBehind").Err, ErrStopped)
+}
+
+// TestNothingToCheck verifies that a plain cache is healthy
+func (s *HealthTestSuite) TestNothingToCheck() {
+	h := New[int, string]().HealthCheck(context.Background())
+	s.True(h.Healthy)
+	s.Empty(h.Components)
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type IncrementTestSuite struct {
+	suite.Suite
+}
+
+func TestIncrementSuite(t *testing.T) {
+	suite.Run(t, new(IncrementTestSuite))
+}
+
+func (s *IncrementTestSuite) SetupTest() {
+	Scoped(s.T())
+}
+
+// TestConcurrentIncrements verifies that no increment is lost
+func (s *IncrementTestSuite) TestConcurrentIncrements() {
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = Increment("visits", int64(1))
+		}()
+	}
+	wg.Wait()
+
+	value, found := Peek[string, int64]("visits")
+	s.True(found)
+	s.Equal(int64(100), value)
+	value, err := Increment("visits", int64(-5))
+	s.NoError(err)
+	s.Equal(int64(95), value, "A negative delta decrements")
+}
+
+// TestIncrementKeepsExpiration verifies that a counter expires with the
+// window it was created in
+func (s *IncrementTestSuite) TestIncrementKeepsExpiration() {
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := New[string, float64](WithClock(clock), WithTTL(time.Minute))
+
+	value, err := c.Increment("budget", 1.5)
+	s.NoError(err)
+	s.Equal(1.5, value)
+	clock.Advance(30 * time.Second)
+	value, _ = c.Increment("budget", 2)
+	s.Equal(3.5, value)
+
+	clock.Advance(30 * time.Second)
+	value, _ = c.Increment("budget", 2)
+	s.Equal(2.0, value, "An expired counter starts over")
+}
+
+// TestIncrementNotNumeric verifies that Increment rejects non-numeric
+// caches
+func (s *IncrementTestSuite) TestIncrementNotNumeric() {
+	c := New[string, string]()
+	_, err := c.Increment("name", "x")
+	s.True(errors.Is(err, ErrNotNumeric))
+}
+
+// TestIncrementRejectsKeys verifies that the package-level Increment
+// checks keys like the method
+func (s *IncrementTestSuite) TestIncrementRejectsKeys() {
+	_, err := Increment(math.NaN(), int64(1))
+	s.ErrorIs(err, ErrNaNKey)
+
+	_, err = New[float64, int64]().Increment(math.NaN(), 1)
+	s.ErrorIs(err, ErrNaNKey)
+}
@@ -0,0 +1,37 @@
+package cache
+
+import "reflect"
+
+// CoalescingSink is implemented by MetricsSinks that also want to know how
+// much stampede protection saves. Coalesced is called once per coalesced
+// load with the number of callers that shared its result, the caller that
+// started it included.
+type CoalescingSink interface {
+	MetricsSink
+	Coalesced(valueType string, callers int)
+}
+
+// settleLoad records how many callers a shared load served once it is
+// done.
+func (s *store) settleLoad(valueType reflect.Type, callers int) {
+	if callers > 1 {
+		s.coalesced.Add(uint64(callers - 1))
+	}
+	if sink, ok := s.cfg().metrics.(CoalescingSink); ok {
+		sink.Coalesced(s.typeName(valueType), callers)
+	}
+}
+
+// beginGetter counts a getter execution starting and returns the function
+// counting its end.
+func (s *store) beginGetter() func() {
+	s.observeInFlight(s.inFlight.Add(1))
+	return func() { s.observeInFlight(s.inFlight.Add(-1)) }
+}
+
+// callGetter calls getter for key, counting it as in flight until it
+// returns or panics.
+func callGetter[K comparable, V any](s *store, getter weightedGetter[K, V], key K) (V, int64, error) {
+	defer s.beginGetter()()
+	return getter(key)
+}
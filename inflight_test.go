@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InFlightTestSuite struct {
+	suite.Suite
+}
+
+func TestInFlightSuite(t *testing.T) {
+	suite.Run(t, new(InFlightTestSuite))
+}
+
+// coalescingMetrics is a recordingMetrics also recording how many callers
+// shared each load.
+type coalescingMetrics struct {
+	*recordingMetrics
+}
+
+func (m coalescingMetrics) Coalesced(valueType string, callers int) {
+	m.add("coalesced"+strconv.Itoa(callers), valueType)
+}
+
+// TestCoalescingStats verifies that running getters and the callers they
+// serve are counted
+func (s *InFlightTestSuite) TestCoalescingStats() {
+	metrics := coalescingMetrics{newRecordingMetrics()}
+	release := make(chan struct{})
+	c := New[string, int](WithMetrics(metrics), WithLoader(func(string) (int, error) {
+		<-release
+		return 1, nil
+	}))
+
+	const callers = 5
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = c.Get("key")
+		}()
+	}
+	s.Eventually(func() bool {
+		group := groupFor[string](c.s, c.valueType)
+		group.mu.Lock()
+		defer group.mu.Unlock()
+		load, ok := group.calls[callKey[string]{key: "key"}]
+		return ok && load.callers == callers && c.Stats().InFlight == 1
+	}, time.Second, time.Millisecond, "One getter runs for all callers")
+
+	close(release)
+	wg.Wait()
+	st := c.Stats()
+	s.Equal(int64(0), st.InFlight)
+	s.Equal(uint64(callers-1), st.Coalesced)
+	s.Equal(1, metrics.snapshot()["coalesced5:int"])
+
+	_, _ = c.Get("key")
+	s.Equal(uint64(callers-1), c.Stats().Coalesced, "Hits are not loads")
+}
+
+// TestPanickingGetter verifies that a getter panicking is no longer
+// counted as running
+func (s *InFlightTestSuite) TestPanickingGetter() {
+	c := New[string, int](WithLoader(func(string) (int, error) {
+		panic("origin bug")
+	}))
+	s.Panics(func() { _, _ = c.Get("key") })
+	s.Equal(int64(0), c.Stats().InFlight)
+
+	s.Panics(func() {
+		_, _ = c.Do("key", func(string) (int, error) { panic("origin bug") })
+	})
+	s.Equal(int64(0), c.Stats().InFlight)
+}
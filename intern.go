@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"reflect"
+	"sync"
+)
+
+// internSlack is how many strings an interner may hold beyond twice the
+// number of cached entries before it drops those no entry uses anymore.
+const internSlack = 1024
+
+// WithStringInterning stores a single copy of equal strings: string keys,
+// and string values of at most maxValueLen bytes, are replaced with the
+// copy the cache already holds, if any. Caches holding many duplicates of
+// the same strings, such as IDs cached for several types or enum-like
+// values, then use much less memory, at the cost of a map lookup per write.
+// Strings no entry uses anymore are dropped from time to time.
+func WithStringInterning(maxValueLen int) Option {
+	return func(o *options) {
+		o.interning = true
+		o.internValueLen = maxValueLen
+	}
+}
+
+// interner holds the canonical copy of interned strings.
+type interner struct {
+	mu      sync.Mutex
+	strings map[string]string
+}
+
+func (in *interner) intern(s string) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if canonical, ok := in.strings[s]; ok {
+		return canonical
+	}
+	if in.strings == nil {
+		in.strings = make(map[string]string)
+	}
+	in.strings[s] = s
+	return s
+}
+
+// replace swaps the interned strings for strings.
+func (in *interner) replace(strings map[string]string) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.strings = strings
+}
+
+func (in *interner) len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.strings)
+}
+
+// internValue returns the canonical copy of value if it is a string to
+// intern, and value itself otherwise.
+func (s *store) internValue(value any) any {
+	cfg := s.cfg()
+	if !cfg.interning {
+		return value
+	}
+	if str, ok := value.(string); ok && len(str) <= cfg.internValueLen {
+		return s.interned.intern(str)
+	}
+	return value
+}
+
+// internKey returns the canonical copy of key if it is a string and
+// interning is enabled, and key itself otherwise.
+func (s *store) internKey(key any) any {
+	if !s.cfg().interning {
+		return key
+	}
+	if str, ok := key.(string); ok {
+		return s.interned.intern(str)
+	}
+	return key
+}
+
+// pruneInternedLocked rebuilds the interner from the cached entries once
+// it holds many more strings than they use. Must be called with s.mu held
+// for writing.
+func (s *store) pruneInternedLocked() {
+	if !s.cfg().interning || s.interned.len() <= 2*s.count+internSlack {
+		return
+	}
+	used := make(map[string]string, 2*s.count)
+	s.rangeAllLocked(func(_ reflect.Type, key any, e *entry) bool {
+		if str, ok := key.(string); ok {
+			used[str] = str
+		}
+		if e.spill == nil {
+			if value, _ := e.get(); value != nil {
+				if str, ok := value.(string); ok {
+					used[str] = str
+				}
+			}
+		}
+		return true
+	})
+	s.interned.replace(used)
+}
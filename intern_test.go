@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InternTestSuite struct {
+	suite.Suite
+}
+
+func TestInternSuite(t *testing.T) {
+	suite.Run(t, new(InternTestSuite))
+}
+
+// stringData returns the address of the bytes of s.
+func stringData(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+// dynamic returns a freshly allocated copy of s.
+func dynamic(s string) string {
+	return string([]byte(s))
+}
+
+// TestEqualStringsShareMemory verifies that equal keys and small values
+// are stored once
+func (s *InternTestSuite) TestEqualStringsShareMemory() {
+	c := New[string, string](WithStringInterning(16))
+	c.Set(dynamic("user:1"), dynamic("active"))
+	c.Set(dynamic("user:2"), dynamic("active"))
+	c.Set(dynamic("user:3"), dynamic("a value longer than sixteen bytes"))
+	c.Set(dynamic("user:4"), dynamic("a value longer than sixteen bytes"))
+
+	first, _ := c.Peek("user:1")
+	second, _ := c.Peek("user:2")
+	s.Equal(stringData(first), stringData(second), "Small values should be interned")
+	third, _ := c.Peek("user:3")
+	fourth, _ := c.Peek("user:4")
+	s.NotEqual(stringData(third), stringData(fourth), "Long values should not be interned")
+
+	// A key equal to a cached value shares its memory too
+	c.Set(dynamic("active"), dynamic("user:1"))
+	var key string
+	c.s.mu.RLock()
+	c.s.rangeAllLocked(func(_ reflect.Type, k any, _ *entry) bool {
+		if k == "active" {
+			key = k.(string)
+		}
+		return true
+	})
+	c.s.mu.RUnlock()
+	s.Equal(stringData(first), stringData(key), "Keys should be interned")
+}
+
+// TestUnusedStringsArePruned verifies that the interner does not grow
+// beyond the strings the cache uses
+func (s *InternTestSuite) TestUnusedStringsArePruned() {
+	c := New[int, string](WithStringInterning(16))
+	for i := 0; i < 10*internSlack; i++ {
+		c.Set(0, strconv.Itoa(i))
+	}
+	s.LessOrEqual(c.s.interned.len(), internSlack+2)
+}
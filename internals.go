@@ -0,0 +1,77 @@
+package cache
+
+import "time"
+
+// InternalStats describes the machinery of a cache rather than its
+// contents: how long operations queue for the store lock, how long
+// expiration sweeps take and how far write-behind lags. Rising numbers
+// tell that a cache needs splitting, a longer janitor interval or a faster
+// backing store before callers notice.
+type InternalStats struct {
+	// LockWaits is the distribution of the time operations waited for the
+	// store lock. Only contended acquisitions are timed, so Count tells
+	// how often operations had to wait at all.
+	LockWaits LatencyStats
+	// Sweeps is the distribution of the durations of the janitor's sweeps
+	// for expired entries.
+	Sweeps LatencyStats
+	// LastSweep is the duration of the janitor's latest sweep, or zero if
+	// none ran.
+	LastSweep time.Duration
+	// WriteBehindQueue is the number of writes queued for the backing
+	// store by WithWriteBehind and not yet written, retries included.
+	WriteBehindQueue int
+}
+
+// lock acquires s.mu for writing, timing the wait if it is held.
+func (s *store) lock() {
+	if s.mu.TryLock() {
+		return
+	}
+	start := time.Now()
+	s.mu.Lock()
+	s.lockWaits.observe(time.Since(start))
+}
+
+// rlock acquires s.mu for reading, timing the wait if it is held for
+// writing.
+func (s *store) rlock() {
+	if s.mu.TryRLock() {
+		return
+	}
+	start := time.Now()
+	s.mu.RLock()
+	s.lockWaits.observe(time.Since(start))
+}
+
+// recordSweep records a janitor sweep that took d.
+func (s *store) recordSweep(d time.Duration) {
+	s.sweeps.observe(d)
+	s.lastSweep.Store(int64(d))
+}
+
+// internals returns the InternalStats of s.
+func (s *store) internals() InternalStats {
+	st := InternalStats{
+		LockWaits: s.lockWaits.stats(),
+		Sweeps:    s.sweeps.stats(),
+		LastSweep: time.Duration(s.lastSweep.Load()),
+	}
+	if s.writeBehind != nil {
+		st.WriteBehindQueue = int(s.writeBehind.pending.Load())
+	}
+	return st
+}
+
+// resetInternals forgets the timings of the machinery.
+func (s *store) resetInternals() {
+	s.lockWaits.reset()
+	s.sweeps.reset()
+	s.lastSweep.Store(0)
+}
+
+func (h *latencyHistogram) reset() {
+	for i := range h.buckets {
+		h.buckets[i].Store(0)
+	}
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// blockedStore holds every write until release is closed
+type blockedStore struct {
+	*memoryStore
+	release chan struct{}
+}
+
+func (b *blockedStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	<-b.release
+	return b.memoryStore.Set(ctx, key, value, ttl)
+}
+
+type InternalsTestSuite struct {
+	suite.Suite
+}
+
+func TestInternalsSuite(t *testing.T) {
+	suite.Run(t, new(InternalsTestSuite))
+}
+
+// TestLockWaits verifies that contended lock acquisitions are timed and
+// uncontended ones are not
+func (s *InternalsTestSuite) TestLockWaits() {
+	c := New[string, int]()
+	s.Require().NoError(c.Set("a", 1))
+	s.Zero(c.Stats().Internals.LockWaits.Count)
+
+	c.s.mu.Lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = c.Set("b", 2)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	c.s.mu.Unlock()
+	<-done
+
+	waits := c.Stats().Internals.LockWaits
+	s.Equal(uint64(1), waits.Count)
+	s.GreaterOrEqual(waits.P99, 10*time.Millisecond)
+}
+
+// TestSweeps verifies that the janitor's sweeps are timed
+func (s *InternalsTestSuite) TestSweeps() {
+	c := New[string, int](WithJanitor(5 * time.Millisecond))
+	defer c.Close()
+	s.Eventually(func() bool {
+		internals := c.Stats().Internals
+		return internals.Sweeps.Count >= 2 && internals.LastSweep > 0
+	}, time.Second, time.Millisecond)
+}
+
+// TestWriteBehindQueue verifies that queued writes count until they reach
+// the store
+func (s *InternalsTestSuite) TestWriteBehindQueue() {
+	remote := &blockedStore{memoryStore: newMemoryStore(), release: make(chan struct{})}
+	c := New[int, string](
+		WithStore(remote),
+		WithWriteBehind(WriteBehindConfig{FlushInterval: time.Millisecond}),
+	)
+	defer c.Close()
+	for i := 0; i < 3; i++ {
+		s.Require().NoError(c.Set(i, "value"))
+	}
+	s.Equal(3, c.Stats().Internals.WriteBehindQueue)
+
+	close(remote.release)
+	s.Eventually(func() bool { return c.Stats().Internals.WriteBehindQueue == 0 }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// defaultRedeleteDelay is how long after an invalidation the local entry
+// is deleted again by default.
+const defaultRedeleteDelay = 500 * time.Millisecond
+
+// Broadcaster delivers invalidation messages to the other processes
+// sharing a backing store, for instance over Redis pub/sub. Each receiving
+// process hands the messages to ApplyInvalidation. Implementations must be
+// safe for concurrent use.
+type Broadcaster interface {
+	Broadcast(ctx context.Context, message []byte) error
+}
+
+// InvalidationConfig configures how DeleteThrough invalidates the other
+// tiers. Zero fields take their defaults.
+type InvalidationConfig struct {
+	// Broadcaster announces invalidations to the other processes; nil
+	// means they are not told.
+	Broadcaster Broadcaster
+	// RedeleteDelay is how long after an invalidation the local entry is
+	// deleted a second time, dropping a value that a load racing with
+	// the invalidation read from the store before it was deleted there.
+	// Default 500ms; negative disables the second delete.
+	RedeleteDelay time.Duration
+}
+
+// WithInvalidation configures the invalidations made by DeleteThrough and
+// applied by ApplyInvalidation.
+func WithInvalidation(cfg InvalidationConfig) Option {
+	return func(o *options) {
+		o.invalidation = &cfg
+	}
+}
+
+// invalidation is the message broadcast by DeleteThrough.
+type invalidation struct {
+	// StoreKey identifies the entry across processes, value type and
+	// namespace included
+	StoreKey string `json:"storeKey"`
+	// Key is the JSON encoding of the key, for the receivers to decode
+	Key json.RawMessage `json:"key"`
+}
+
+// DeleteThrough invalidates key in every tier, in an order that keeps a
+// read racing with it from putting the deleted value back: the local entry
+// is deleted, then the backing store entry, then the invalidation is
+// broadcast to the other processes, and finally the local entry is deleted
+// again after the redelete delay of WithInvalidation. Like Delete, it keeps
+// the results of loads already in progress out of the cache. WithReason is
+// the only option honored.
+//
+// DeleteThrough returns ErrNoStore if the cache has no backing store. If
+// the store delete fails, the error is returned and nothing is broadcast.
+func (c *Cache[K, V]) DeleteThrough(key K, opts ...Option) error {
+	s := c.s
+	if s.remote == nil {
+		return ErrNoStore
+	}
+	if err := s.checkKey(key); err != nil {
+		return err
+	}
+	s.audit(AuditDelete, c.valueType, key, nil, reasonOf(opts))
+
+	unlock := s.keyLocks.lock(entryKey{c.valueType, canonicalKey(key)})
+	defer unlock()
+
+	s.delete(c.valueType, key)
+	storeKey := s.remoteKey(c.valueType, key)
+	if err := s.remote.Delete(context.Background(), storeKey); err != nil {
+		return fmt.Errorf("cache: deleting key %v from store: %w", s.redactKey(s.typeName(c.valueType), key), err)
+	}
+	var err error
+	if s.invalidation.Broadcaster != nil {
+		err = s.broadcastInvalidation(storeKey, key)
+	}
+	s.redeleteLater(c.valueType, key)
+	if err != nil {
+		return fmt.Errorf("cache: broadcasting invalidation of key %v: %w", s.redactKey(s.typeName(c.valueType), key), err)
+	}
+	return nil
+}
+
+// ApplyInvalidation applies an invalidation broadcast by DeleteThrough in
+// another process: the local entry is deleted, and deleted again after the
+// redelete delay. Messages for other value types or namespaces are
+// ignored, so every message can be handed to every cache.
+func (c *Cache[K, V]) ApplyInvalidation(message []byte) error {
+	var inv invalidation
+	if err := json.Unmarshal(message, &inv); err != nil {
+		return fmt.Errorf("cache: decoding invalidation: %w", err)
+	}
+	var key K
+	if err := json.Unmarshal(inv.Key, &key); err != nil {
+		// A key of another type
+		return nil
+	}
+	if !c.s.usableKey(key) || c.s.remoteKey(c.valueType, key) != inv.StoreKey {
+		return nil
+	}
+	c.s.delete(c.valueType, key)
+	c.s.redeleteLater(c.valueType, key)
+	return nil
+}
+
+// broadcastInvalidation announces that the entry stored under storeKey
+// was invalidated.
+func (s *store) broadcastInvalidation(storeKey string, key any) error {
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	message, err := json.Marshal(invalidation{StoreKey: storeKey, Key: encoded})
+	if err != nil {
+		return err
+	}
+	return s.invalidation.Broadcaster.Broadcast(context.Background(), message)
+}
+
+// redeleteLater deletes key again once the redelete delay has passed.
+func (s *store) redeleteLater(valueType reflect.Type, key any) {
+	delay := s.invalidation.RedeleteDelay
+	if delay < 0 {
+		return
+	}
+	if delay == 0 {
+		delay = defaultRedeleteDelay
+	}
+	time.AfterFunc(delay, func() { s.delete(valueType, key) })
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithJanitor starts a background worker that removes expired entries
+// every interval, so memory is reclaimed even for keys that are never read
+// again. Without it, expired entries are only reclaimed when they are
+// overwritten or evicted. Shutdown stops the janitor.
+func WithJanitor(interval time.Duration) Option {
+	return func(o *options) {
+		o.janitorInterval = interval
+	}
+}
+
+type janitor struct {
+	s        *store
+	interval time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	// lastSweep is the UnixNano time the last sweep finished, or the
+	// janitor started
+	lastSweep atomic.Int64
+}
+
+// startJanitor replaces the store's janitor, if any, with one sweeping
+// every interval.
+func (s *store) startJanitor(interval time.Duration) {
+	j := &janitor{
+		s:        s,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	j.lastSweep.Store(time.Now().UnixNano())
+	s.workersMu.Lock()
+	prev := s.janitor
+	s.janitor = j
+	s.workersMu.Unlock()
+	if prev != nil {
+		_ = prev.shutdown(context.Background())
+	}
+	go j.run()
+}
+
+// stopJanitor stops the store's janitor, if any, waiting for it to exit
+// or for ctx to end. With detach the store forgets the janitor; otherwise
+// it stays visible to HealthCheck as stopped.
+func (s *store) stopJanitor(ctx context.Context, detach bool) error {
+	s.workersMu.Lock()
+	j := s.janitor
+	if detach {
+		s.janitor = nil
+	}
+	s.workersMu.Unlock()
+	if j == nil {
+		return nil
+	}
+	return j.shutdown(ctx)
+}
+
+func (j *janitor) run() {
+	defer close(j.done)
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			start := time.Now()
+			j.s.removeExpired()
+			j.s.recordSweep(time.Since(start))
+			j.lastSweep.Store(time.Now().UnixNano())
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+// shutdown stops the janitor and waits for it to exit or for ctx to end.
+// It is safe to call more than once.
+func (j *janitor) shutdown(ctx context.Context) error {
+	j.stopOnce.Do(func() { close(j.stop) })
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// removeExpired deletes every expired entry and returns how many it found.
+func (s *store) removeExpired() int {
+	s.lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var expired []entryKey
+	s.rangeAllLocked(func(valueType reflect.Type, key any, e *entry) bool {
+		if e.expired(now) {
+			expired = append(expired, entryKey{valueType, key})
+		}
+		return true
+	})
+	for _, k := range expired {
+		s.removeLocked(k.valueType, k.key, RemovalExpired)
+	}
+	return len(expired)
+}
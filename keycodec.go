@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+)
+
+// KeyCodec renders keys as the strings identifying them in a backing
+// store. Encodings must be deterministic across processes, so that
+// replicas sharing a store agree on the keys of their entries, and should
+// be distinct for distinct keys.
+type KeyCodec interface {
+	EncodeKey(key any) string
+}
+
+// DefaultKeyCodec is the KeyCodec used unless WithKeyCodec sets another.
+// Strings are used as is, keys implementing encoding.TextMarshaler are
+// rendered with MarshalText and keys implementing encoding.BinaryMarshaler
+// with MarshalBinary, base64 encoded. Other keys are formatted with fmt's
+// %v verb, which is stable for numbers, booleans and structs of them but
+// not for pointers.
+type DefaultKeyCodec struct{}
+
+// EncodeKey renders key as described on DefaultKeyCodec.
+func (DefaultKeyCodec) EncodeKey(key any) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case encoding.TextMarshaler:
+		if text, err := k.MarshalText(); err == nil {
+			return string(text)
+		}
+	case encoding.BinaryMarshaler:
+		if data, err := k.MarshalBinary(); err == nil {
+			return base64.RawURLEncoding.EncodeToString(data)
+		}
+	}
+	return fmt.Sprint(key)
+}
+
+// WithKeyCodec sets how keys are rendered in backing store keys, including
+// those of refresh locks. The default is DefaultKeyCodec. Replicas sharing
+// a store must use the same codec.
+func WithKeyCodec(codec KeyCodec) Option {
+	return func(o *options) {
+		o.keyCodec = codec
+	}
+}
+
+// remoteKey returns the backing store key for an entry.
+func (s *store) remoteKey(valueType reflect.Type, key any) string {
+	return s.keyPrefix + valueType.String() + ":" + s.cfg().keyCodec.EncodeKey(key)
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// entryKey identifies an entry across all type partitions of a store.
+type entryKey struct {
+	valueType reflect.Type
+	key       any
+}
+
+// keyLocker hands out one mutex per key, creating them on demand and
+// dropping them once nobody holds or waits for them.
+type keyLocker struct {
+	mu    sync.Mutex
+	locks map[any]*keyLock
+}
+
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock acquires the mutex for key and returns the function releasing it.
+func (l *keyLocker) lock(key any) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[any]*keyLock)
+	}
+	kl, ok := l.locks[key]
+	if !ok {
+		kl = &keyLock{}
+		l.locks[key] = kl
+	}
+	kl.refs++
+	l.mu.Unlock()
+
+	kl.mu.Lock()
+	return func() { l.unlock(key) }
+}
+
+// unlock releases the mutex for key acquired with lock. It panics if key
+// is not locked.
+func (l *keyLocker) unlock(key any) {
+	l.mu.Lock()
+	kl, ok := l.locks[key]
+	if !ok {
+		l.mu.Unlock()
+		panic(fmt.Sprintf("cache: unlock of unlocked key %v", key))
+	}
+	kl.refs--
+	if kl.refs == 0 {
+		delete(l.locks, key)
+	}
+	l.mu.Unlock()
+
+	kl.mu.Unlock()
+}
+
+// LockKey acquires the lock the cache serializes the writes of key with,
+// such as SetThrough, waiting until it is available. Code updating the
+// origin and invalidating the cache can hold it to keep those writes out.
+// Each successful LockKey must be followed by UnlockKey; the lock is not
+// reentrant. It returns an error for keys the cache cannot hold.
+//
+//	if err := users.LockKey(id); err != nil {
+//		return err
+//	}
+//	defer users.UnlockKey(id)
+//	if err := db.UpdateUser(id, changes); err != nil {
+//		return err
+//	}
+//	users.Delete(id)
+func (c *Cache[K, V]) LockKey(key K) error {
+	if err := c.s.checkKey(key); err != nil {
+		return err
+	}
+	c.s.keyLocks.lock(entryKey{c.valueType, canonicalKey(key)})
+	return nil
+}
+
+// UnlockKey releases the lock on key taken with LockKey. It panics if key
+// is not locked.
+func (c *Cache[K, V]) UnlockKey(key K) {
+	c.s.keyLocks.unlock(entryKey{c.valueType, canonicalKey(key)})
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// NaNKeyPolicy decides how keys containing a floating-point NaN are
+// handled. NaN is not equal to itself, so without a policy every NaN key
+// would miss and add an entry that could never be read or removed.
+type NaNKeyPolicy int
+
+const (
+	// RejectNaNKeys makes Get, Set and the other calls returning an error
+	// fail with ErrNaNKey, Peek report a miss and Delete do nothing. It
+	// is the default.
+	RejectNaNKeys NaNKeyPolicy = iota
+	// CanonicalNaNKeys makes every NaN key of a floating-point key type
+	// identify the same entry. Other keys containing NaN, such as structs
+	// with a NaN field, are still rejected.
+	CanonicalNaNKeys
+)
+
+// String returns a human-readable name for the policy.
+func (p NaNKeyPolicy) String() string {
+	switch p {
+	case RejectNaNKeys:
+		return "reject"
+	case CanonicalNaNKeys:
+		return "canonical"
+	default:
+		return "unknown"
+	}
+}
+
+// WithNaNKeys sets how float keys holding NaN are handled. The default is
+// RejectNaNKeys.
+func WithNaNKeys(policy NaNKeyPolicy) Option {
+	return func(o *options) {
+		o.nanKeys = policy
+	}
+}
+
+// nanKey stands in for the NaN keys of the float type typ in storage, so
+// that they can be found again.
+type nanKey struct {
+	typ reflect.Type
+}
+
+// String renders the key like the NaN it stands for.
+func (nanKey) String() string {
+	return "NaN"
+}
+
+// canonicalKey returns the key under which key is stored: a nanKey for NaN
+// keys of floating-point types, key itself otherwise.
+func canonicalKey(key any) any {
+	switch k := key.(type) {
+	case string, int, int64, uint64, nanKey:
+		return key
+	case float64:
+		if math.IsNaN(k) {
+			return nanKey{reflect.TypeOf(k)}
+		}
+		return key
+	}
+	v := reflect.ValueOf(key)
+	if kind := v.Kind(); (kind == reflect.Float64 || kind == reflect.Float32) && math.IsNaN(v.Float()) {
+		return nanKey{v.Type()}
+	}
+	return key
+}
+
+// plainKey converts a stored key back to the key it was stored for.
+func plainKey(key any) any {
+	if nan, ok := key.(nanKey); ok {
+		return reflect.ValueOf(math.NaN()).Convert(nan.typ).Interface()
+	}
+	return key
+}
+
+// typedKey converts a stored key back to a K.
+func typedKey[K comparable](key any) (K, bool) {
+	typed, ok := plainKey(key).(K)
+	return typed, ok
+}
+
+// selfEqual reports whether key is equal to itself, which is false for
+// keys containing NaN, and whether it can be compared at all. Keys of
+// interface type, or structs and arrays containing interfaces, may hold
+// slices, maps or functions, which cannot.
+func selfEqual(key any) (equal, comparable bool) {
+	defer func() {
+		if recover() != nil {
+			equal, comparable = false, false
+		}
+	}()
+	// Comparing a value holding an uncomparable type panics, exactly like
+	// hashing it would
+	return key == key, true
+}
+
+// checkKey returns an error wrapping ErrUnhashableKey if key cannot be
+// used as a map key, and one wrapping ErrNaNKey if it contains a NaN the
+// NaN key policy does not accept.
+func (s *store) checkKey(key any) error {
+	equal, comparable := selfEqual(key)
+	switch {
+	case !comparable:
+		return fmt.Errorf("%w: %T", ErrUnhashableKey, key)
+	case !equal:
+		if _, nan := canonicalKey(key).(nanKey); !nan || s.cfg().nanKeys != CanonicalNaNKeys {
+			return fmt.Errorf("%w: %v", ErrNaNKey, key)
+		}
+	}
+	return nil
+}
+
+// usableKey reports whether checkKey accepts key.
+func (s *store) usableKey(key any) bool {
+	return s.checkKey(key) == nil
+}
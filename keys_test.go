@@ -0,0 +1,93 @@
+//go:build go1.21
+
+package cache
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KeysTestSuite struct {
+	suite.Suite
+}
+
+func TestKeysSuite(t *testing.T) {
+	suite.Run(t, new(KeysTestSuite))
+}
+
+func (s *KeysTestSuite) SetupTest() {
+	Scoped(s.T())
+}
+
+// TestUnhashableKeys verifies that keys holding slices fail instead of
+// crashing the process
+func (s *KeysTestSuite) TestUnhashableKeys() {
+	c := New[any, string](WithLoader(func(key any) (string, error) {
+		return "value", nil
+	}))
+
+	_, err := c.Get([]int{1, 2})
+	s.True(errors.Is(err, ErrUnhashableKey))
+	s.True(errors.Is(c.Set(map[string]int{}, "value"), ErrUnhashableKey))
+	_, found := c.Peek([]int{1})
+	s.False(found)
+	c.Delete([]int{1})
+
+	getter := func(any) (string, error) { return "", nil }
+	_, err = Get[any, string](struct{ id int }{1}, getter)
+	s.NoError(err, "Comparable values are fine")
+	_, err = Get[any, string](struct{ tags []string }{}, getter)
+	s.True(errors.Is(err, ErrUnhashableKey))
+}
+
+// TestNaNKeys verifies that NaN keys are rejected by default and share one
+// entry under CanonicalNaNKeys
+func (s *KeysTestSuite) TestNaNKeys() {
+	nan := math.NaN()
+	rejecting := New[float64, string]()
+	s.True(errors.Is(rejecting.Set(nan, "value"), ErrNaNKey))
+	_, found := rejecting.Peek(nan)
+	s.False(found)
+	_, err := Get(nan, func(float64) (string, error) { return "value", nil })
+	s.True(errors.Is(err, ErrNaNKey))
+
+	c := New[float64, string](WithNaNKeys(CanonicalNaNKeys))
+	s.Require().NoError(c.Set(nan, "first"))
+	s.Require().NoError(c.Set(-nan, "second"))
+	value, found := c.Peek(math.Float64frombits(0x7ff8000000000001))
+	s.True(found)
+	s.Equal("second", value, "All NaNs are the same key")
+
+	var keys []float64
+	c.Snapshot().Range(func(key float64, _ string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	s.Require().Len(keys, 1)
+	s.True(math.IsNaN(keys[0]))
+
+	c.Delete(nan)
+	_, found = c.Peek(nan)
+	s.False(found)
+
+	type point struct{ x, y float64 }
+	structs := New[point, string](WithNaNKeys(CanonicalNaNKeys))
+	s.True(errors.Is(structs.Set(point{nan, 0}, "value"), ErrNaNKey),
+		"Only float keys are canonicalized")
+}
+
+// TestDoMixesKeyTypes verifies that Do keeps the calls of interface and
+// concrete key types apart
+func (s *KeysTestSuite) TestDoMixesKeyTypes() {
+	fn := func(key any) (string, error) { return "any", nil }
+	value, err := Do[any, string](1, fn)
+	s.NoError(err)
+	s.Equal("any", value)
+
+	value, err = Do(1, func(int) (string, error) { return "int", nil })
+	s.NoError(err)
+	s.Equal("int", value)
+}
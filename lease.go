@@ -0,0 +1,73 @@
+package cache
+
+// Acquire returns the value for key like Get and leases it: until a
+// matching Release, the entry is never evicted, and with WithCloseValues
+// the values removed from key are not closed, so a caller can use a
+// resource without it being closed underneath. Leases are counted; the
+// entry is released with the last of them, at which point deferred
+// evictions and closes take place.
+//
+// Replacing, deleting or expiring a leased entry still takes effect
+// immediately for other readers.
+func (c *Cache[K, V]) Acquire(key K) (V, error) {
+	if err := c.s.checkKey(key); err != nil {
+		var zero V
+		return zero, err
+	}
+	k := entryKey{c.valueType, canonicalKey(key)}
+	c.s.lock()
+	if c.s.leases == nil {
+		c.s.leases = make(map[entryKey]int)
+	}
+	c.s.leases[k]++
+	c.s.mu.Unlock()
+
+	value, err := c.Get(key)
+	if err != nil {
+		c.Release(key)
+	}
+	return value, err
+}
+
+// Release ends a lease taken with Acquire. It does nothing if key is not
+// leased.
+func (c *Cache[K, V]) Release(key K) {
+	if !c.s.usableKey(key) {
+		return
+	}
+	k := entryKey{c.valueType, canonicalKey(key)}
+	s := c.s
+	s.lock()
+	defer s.mu.Unlock()
+	n, ok := s.leases[k]
+	if !ok {
+		return
+	}
+	if n > 1 {
+		s.leases[k] = n - 1
+		return
+	}
+	delete(s.leases, k)
+
+	deferred := s.deferredCloses[k]
+	delete(s.deferredCloses, k)
+	for _, value := range deferred {
+		s.retireLocked(k.valueType, k.key, value)
+	}
+	// Catch up on the evictions the lease held back
+	s.evictLocked(entryKey{valueType: k.valueType})
+}
+
+// leasedLocked reports whether k is leased. Must be called with s.mu held.
+func (s *store) leasedLocked(k entryKey) bool {
+	return s.leases[k] > 0
+}
+
+// deferCloseLocked holds back the closing of value, removed from the leased
+// key k, until the lease ends. Must be called with s.mu held for writing.
+func (s *store) deferCloseLocked(k entryKey, value any) {
+	if s.deferredCloses == nil {
+		s.deferredCloses = make(map[entryKey][]any)
+	}
+	s.deferredCloses[k] = append(s.deferredCloses[k], value)
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LeaseTestSuite struct {
+	suite.Suite
+}
+
+func TestLeaseSuite(t *testing.T) {
+	suite.Run(t, new(LeaseTestSuite))
+}
+
+// TestLeasedEntriesAreNotEvicted verifies that eviction passes over leased
+// entries and catches up once they are released
+func (s *LeaseTestSuite) TestLeasedEntriesAreNotEvicted() {
+	c := New[string, int](WithMaxEntries(2))
+	c.Set("a", 1)
+	value, err := c.Acquire("a")
+	s.NoError(err)
+	s.Equal(1, value)
+
+	c.Set("b", 2)
+	c.Set("c", 3)
+	_, found := c.Peek("a")
+	s.True(found, "A leased entry must not be evicted")
+	_, found = c.Peek("b")
+	s.False(found)
+
+	c.Release("a")
+	c.Set("d", 4)
+	_, found = c.Peek("a")
+	s.False(found, "A released entry is evicted again")
+}
+
+// TestEvictionIsDeferredUntilLastRelease verifies that leases are counted
+// and that the cache shrinks back to its limit on the last release
+func (s *LeaseTestSuite) TestEvictionIsDeferredUntilLastRelease() {
+	c := New[string, int](WithMaxEntries(1))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	for _, key := range []string{"b", "b"} {
+		_, err := c.Acquire(key)
+		s.NoError(err)
+	}
+	c.Set("c", 3)
+	s.Equal(2, c.Stats().Entries, "The leased entry overflows the limit")
+
+	c.Release("b")
+	s.Equal(2, c.Stats().Entries)
+	c.Release("b")
+	s.Equal(1, c.Stats().Entries, "The last release evicts")
+}
+
+// TestLeasedValuesAreClosedOnRelease verifies that values removed while
+// leased are only closed after the last release
+func (s *LeaseTestSuite) TestLeasedValuesAreClosedOnRelease() {
+	c := New[string, *resource](WithCloseValues(0))
+	r := &resource{}
+	c.Set("a", r)
+	_, err := c.Acquire("a")
+	s.NoError(err)
+
+	c.Set("a", &resource{})
+	c.Delete("a")
+	time.Sleep(10 * time.Millisecond)
+	s.False(r.isClosed(), "A leased value must not be closed")
+
+	c.Release("a")
+	s.Eventually(r.isClosed, time.Second, time.Millisecond)
+}
+
+// TestFailedAcquireHoldsNoLease verifies that a miss does not leave a lease
+// behind
+func (s *LeaseTestSuite) TestFailedAcquireHoldsNoLease() {
+	c := New[string, int]()
+	_, err := c.Acquire("a")
+	s.True(errors.Is(err, ErrNotCached))
+
+	c.s.mu.RLock()
+	defer c.s.mu.RUnlock()
+	s.Empty(c.s.leases)
+}
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// MemoryPressureConfig configures memory-pressure-aware eviction.
+type MemoryPressureConfig struct {
+	// HighWatermark is the heap size in bytes above which entries are
+	// evicted. Eviction is disabled while it is zero.
+	HighWatermark uint64
+	// LowWatermark is the heap size eviction aims for. Default 90% of
+	// HighWatermark.
+	LowWatermark uint64
+	// Interval is how often the heap size is checked. Default 1s.
+	Interval time.Duration
+}
+
+// WithMemoryPressure starts a background monitor that protects the process
+// from running out of memory under load spikes. Whenever the heap grows
+// beyond cfg.HighWatermark, the monitor evicts the least recently used
+// entries in proportion to the excess over cfg.LowWatermark: a heap 20%
+// over the low watermark loses about 20% of the entries. The heap size is
+// read from runtime/metrics, which does not stop the world. Evicted
+// entries only leave the heap at the next garbage collection, so after
+// evicting, the monitor waits for a collection to complete before it
+// looks at the heap again. Evictions are
+// counted in Statistics.Evictions and reported as EventMemoryPressure.
+// Shutdown stops the monitor.
+func WithMemoryPressure(cfg MemoryPressureConfig) Option {
+	return func(o *options) {
+		o.memoryPressure = &cfg
+	}
+}
+
+// The runtime metrics holding the bytes occupied by live and not yet swept
+// heap objects, and the number of completed garbage collections.
+const (
+	heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+	gcCyclesMetric    = "/gc/cycles/total:gc-cycles"
+)
+
+// heapSample is a reading of the heap.
+type heapSample struct {
+	// bytes is the heap size
+	bytes uint64
+	// gcs is the number of garbage collections completed so far
+	gcs uint64
+}
+
+// readHeap returns the current heap size and garbage collection count.
+func readHeap() heapSample {
+	samples := []metrics.Sample{{Name: heapObjectsMetric}, {Name: gcCyclesMetric}}
+	metrics.Read(samples)
+	var heap heapSample
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		heap.bytes = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		heap.gcs = samples[1].Value.Uint64()
+	}
+	return heap
+}
+
+type memoryMonitor struct {
+	s        *store
+	cfg      MemoryPressureConfig
+	readHeap func() heapSample
+	// evictedAt is the garbage collection count of the last eviction;
+	// evicted tells whether there was one
+	evictedAt uint64
+	evicted   bool
+	stop      chan struct{}
+	stopOnce  sync.Once
+	done      chan struct{}
+}
+
+// startMemoryMonitor replaces the store's memory monitor, if any, with one
+// configured by cfg and reading the heap with read.
+func (s *store) startMemoryMonitor(cfg MemoryPressureConfig, read func() heapSample) {
+	if cfg.HighWatermark == 0 {
+		return
+	}
+	if cfg.LowWatermark == 0 || cfg.LowWatermark > cfg.HighWatermark {
+		cfg.LowWatermark = cfg.HighWatermark / 10 * 9
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	s.memoryLimited.Store(true)
+	m := &memoryMonitor{
+		s:        s,
+		cfg:      cfg,
+		readHeap: read,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	s.workersMu.Lock()
+	prev := s.memoryMonitor
+	s.memoryMonitor = m
+	s.workersMu.Unlock()
+	if prev != nil {
+		_ = prev.shutdown(context.Background())
+	}
+	go m.run()
+}
+
+// stopMemoryMonitor stops the store's memory monitor, if any, waiting for
+// it to exit or for ctx to end.
+func (s *store) stopMemoryMonitor(ctx context.Context) error {
+	s.workersMu.Lock()
+	m := s.memoryMonitor
+	s.memoryMonitor = nil
+	s.workersMu.Unlock()
+	if m == nil {
+		return nil
+	}
+	return m.shutdown(ctx)
+}
+
+func (m *memoryMonitor) run() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.check()
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// check evicts entries if the heap is over the high watermark and returns
+// how many it evicted. Until a garbage collection completes after an
+// eviction, the heap still holds the evicted entries and check does
+// nothing.
+func (m *memoryMonitor) check() int {
+	heap := m.readHeap()
+	if m.evicted && heap.gcs == m.evictedAt {
+		return 0
+	}
+	if heap.bytes <= m.cfg.HighWatermark {
+		return 0
+	}
+	fraction := float64(heap.bytes-m.cfg.LowWatermark) / float64(heap.bytes)
+	evicted := m.s.evictFraction(fraction)
+	m.evicted, m.evictedAt = true, heap.gcs
+	m.s.emit(Event{
+		Kind: EventMemoryPressure,
+		Err: fmt.Errorf("heap of %d bytes over the high watermark of %d: evicted %d entries",
+			heap.bytes, m.cfg.HighWatermark, evicted),
+	})
+	return evicted
+}
+
+// shutdown stops the monitor and waits for it to exit or for ctx to end.
+// It is safe to call more than once.
+func (m *memoryMonitor) shutdown(ctx context.Context) error {
+	m.stopOnce.Do(func() { close(m.stop) })
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// evictFraction evicts about fraction of the entries, least recently used
+// first, and returns how many it evicted.
+func (s *store) evictFraction(fraction float64) int {
+	s.lock()
+	defer s.mu.Unlock()
+	n := int(float64(s.count)*fraction + 0.5)
+	if n == 0 && s.count > 0 {
+		n = 1
+	}
+	evicted := 0
+	for ; evicted < n; evicted++ {
+		victim, ok := s.victimLocked(entryKey{}, nil)
+		if !ok {
+			break
+		}
+		s.removeLocked(victim.valueType, victim.key, RemovalCapacity)
+		s.evictions.Add(1)
+		s.cfg().metrics.Eviction(s.typeName(victim.valueType))
+	}
+	return evicted
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MemoryTestSuite struct {
+	suite.Suite
+	heap   atomic.Uint64
+	gcs    atomic.Uint64
+	mu     sync.Mutex
+	events []Event
+	cache  *Cache[int, int]
+}
+
+func TestMemorySuite(t *testing.T) {
+	suite.Run(t, new(MemoryTestSuite))
+}
+
+func (s *MemoryTestSuite) SetupTest() {
+	s.heap.Store(0)
+	s.gcs.Store(0)
+	s.events = nil
+	s.cache = New[int, int](WithEventHandler(func(ev Event) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.events = append(s.events, ev)
+	}))
+}
+
+func (s *MemoryTestSuite) TearDownTest() {
+	s.NoError(s.cache.Close())
+}
+
+func (s *MemoryTestSuite) startMonitor(interval time.Duration) {
+	s.cache.s.startMemoryMonitor(MemoryPressureConfig{
+		HighWatermark: 1000,
+		LowWatermark:  800,
+		Interval:      interval,
+	}, func() heapSample {
+		return heapSample{bytes: s.heap.Load(), gcs: s.gcs.Load()}
+	})
+	for i := 0; i < 100; i++ {
+		s.cache.Set(i, i)
+	}
+}
+
+// TestProportionalEviction verifies that the excess over the low watermark
+// decides how many entries are evicted
+func (s *MemoryTestSuite) TestProportionalEviction() {
+	s.startMonitor(time.Hour)
+	m := s.cache.s.memoryMonitor
+
+	s.heap.Store(1000)
+	s.Equal(0, m.check(), "Nothing happens up to the high watermark")
+
+	s.heap.Store(1600)
+	s.Equal(50, m.check())
+	st := s.cache.Stats()
+	s.Equal(50, st.Entries)
+	s.Equal(uint64(50), st.Evictions)
+
+	_, ok := s.cache.Peek(99)
+	s.True(ok, "Recently used entries survive")
+	s.Require().Len(s.events, 1)
+	s.Equal(EventMemoryPressure, s.events[0].Kind)
+}
+
+// TestWaitForCollection verifies that the monitor does not evict again
+// before a garbage collection has freed the entries it evicted
+func (s *MemoryTestSuite) TestWaitForCollection() {
+	s.startMonitor(time.Hour)
+	m := s.cache.s.memoryMonitor
+
+	s.heap.Store(1600)
+	s.Equal(50, m.check())
+	s.Equal(0, m.check(), "The heap is stale until the next collection")
+	s.Equal(50, s.cache.Stats().Entries)
+
+	s.gcs.Add(1)
+	s.Equal(25, m.check())
+	s.Equal(25, s.cache.Stats().Entries)
+}
+
+// TestMonitorRuns verifies that the monitor checks the heap periodically
+// and stops on shutdown
+func (s *MemoryTestSuite) TestMonitorRuns() {
+	s.startMonitor(time.Millisecond)
+	s.heap.Store(2000)
+	s.Eventually(func() bool {
+		s.gcs.Add(1)
+		return s.cache.Stats().Entries == 0
+	}, time.Second, time.Millisecond)
+
+	s.NoError(s.cache.Shutdown(context.Background()))
+	s.Nil(s.cache.s.memoryMonitor)
+}
+
+// TestReadHeap verifies that the heap size and collection count are read
+// from the runtime
+func (s *MemoryTestSuite) TestReadHeap() {
+	heap := readHeap()
+	s.Greater(heap.bytes, uint64(0))
+	runtime.GC()
+	s.Greater(readHeap().gcs, heap.gcs)
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// MetricsSink receives cache metrics as they happen, labeled with the name
+// of the value type involved. Implementations must be safe for concurrent
+// use and should return quickly, as they are called on the hot path.
+type MetricsSink interface {
+	// Hit is called when a lookup is served from the cache.
+	Hit(valueType string)
+	// Miss is called when a lookup is not served from the cache.
+	Miss(valueType string)
+	// Load is called after a getter or loader returns.
+	Load(valueType string, duration time.Duration, err error)
+	// Eviction is called when an entry is evicted to respect a limit.
+	Eviction(valueType string)
+}
+
+// WithMetrics sets the sink receiving the cache's metrics.
+func WithMetrics(sink MetricsSink) Option {
+	return func(o *options) {
+		o.metrics = sink
+	}
+}
+
+// noopMetrics is the MetricsSink used when none is configured.
+type noopMetrics struct{}
+
+func (noopMetrics) Hit(string)                        {}
+func (noopMetrics) Miss(string)                       {}
+func (noopMetrics) Load(string, time.Duration, error) {}
+func (noopMetrics) Eviction(string)                   {}
+
+// TypeNamer renders a value type for metrics labels, statistics and logs,
+// for example to shorten generic instantiations or to give types aliases.
+type TypeNamer func(valueType reflect.Type) string
+
+// WithTypeNamer sets how value types are named in metrics, Stats().Types,
+// audit records, dumps and errors. Names should be few and stable, since
+// every distinct name becomes a label value. The default names types with
+// reflect.Type.String, such as "main.Page[github.com/acme/shop.Product]";
+// ShortTypeName drops the import paths.
+//
+//	cache.SetDefaults(cache.WithTypeNamer(cache.ShortTypeName))
+func WithTypeNamer(namer TypeNamer) Option {
+	return func(o *options) {
+		o.typeNamer = namer
+	}
+}
+
+// ShortTypeName is a TypeNamer naming types without the import paths of
+// the packages they and their type arguments come from, such as
+// "main.Page[shop.Product]".
+func ShortTypeName(valueType reflect.Type) string {
+	name := valueType.String()
+	var b strings.Builder
+	start := 0
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '/':
+			start = i + 1
+		case '[', ']', ',', '*', ' ':
+			b.WriteString(name[start : i+1])
+			start = i + 1
+		}
+	}
+	b.WriteString(name[start:])
+	return b.String()
+}
+
+// typeName renders valueType for metrics labels.
+func (s *store) typeName(valueType reflect.Type) string {
+	if namer := s.cfg().typeNamer; namer != nil {
+		return namer(valueType)
+	}
+	return valueType.String()
+}
+
+// recordHit counts a lookup of key served from the cache.
+func (s *store) recordHit(valueType reflect.Type, key any) {
+	s.hits.Add(1)
+	s.countersFor(valueType).hits.Add(1)
+	s.cfg().metrics.Hit(s.typeName(valueType))
+	s.recordAccess(valueType, key)
+	s.observeLookup(false)
+	s.tuneSegments(true)
+}
+
+// recordMiss counts a lookup of key not served from the cache.
+func (s *store) recordMiss(valueType reflect.Type, key any) {
+	s.misses.Add(1)
+	s.countersFor(valueType).misses.Add(1)
+	s.cfg().metrics.Miss(s.typeName(valueType))
+	s.recordAccess(valueType, key)
+	s.observeLookup(true)
+	s.tuneSegments(false)
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+)
+
+// namespaceEscaper escapes the separator of nested namespaces in names, so
+// that the namespace "a/b" and the namespace "b" of "a" have distinct
+// backing store keys.
+var namespaceEscaper = strings.NewReplacer("%", "%25", "/", "%2F")
+
+// Namespace returns the child cache for name, creating it on first use.
+// A namespace has its own storage, statistics and limits, so entries of
+// different namespaces (for example tenants) never collide or evict one
+// another. It inherits the parent's configuration, including its loader;
+// opts are applied on top of it when the namespace is created and ignored
+// afterwards. Namespaces are limited by the parent's WithNamespaceQuota
+// unless opts say otherwise. Backing store keys are prefixed with the
+// namespace name and a "/", with "/" and "%" in the name escaped as in
+// URLs.
+func (c *Cache[K, V]) Namespace(name string, opts ...Option) *Cache[K, V] {
+	c.nsMu.Lock()
+	defer c.nsMu.Unlock()
+	if ns, ok := c.namespaces[name]; ok {
+		return ns
+	}
+
+	o := c.opts
+	o.keyPrefix = c.opts.keyPrefix + namespaceEscaper.Replace(name) + "/"
+	if q := c.opts.nsQuota; q != nil {
+		o.maxEntries, o.maxBytes = q.MaxEntries, q.MaxBytes
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ns := newCache[K, V](o)
+	if c.namespaces == nil {
+		c.namespaces = make(map[string]*Cache[K, V])
+	}
+	c.namespaces[name] = ns
+	return ns
+}
+
+// DropNamespace invalidates every entry of the namespace name in one call
+// and releases its resources. A later call to Namespace with the same name
+// returns a new, empty namespace.
+func (c *Cache[K, V]) DropNamespace(name string) {
+	c.nsMu.Lock()
+	ns, ok := c.namespaces[name]
+	delete(c.namespaces, name)
+	c.nsMu.Unlock()
+
+	if ok {
+		ns.Clear()
+		ns.Close()
+	}
+}
+
+// Namespaces returns the sorted names of the namespaces in use.
+func (c *Cache[K, V]) Namespaces() []string {
+	c.nsMu.Lock()
+	defer c.nsMu.Unlock()
+	names := make([]string, 0, len(c.namespaces))
+	for name := range c.namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Cache[K, V]) namespaceList() []*Cache[K, V] {
+	c.nsMu.Lock()
+	defer c.nsMu.Unlock()
+	list := make([]*Cache[K, V], 0, len(c.namespaces))
+	for _, ns := range c.namespaces {
+		list = append(list, ns)
+	}
+	return list
+}
+
+// NamespaceStats returns the statistics of every namespace in use, keyed by
+// namespace name.
+func (c *Cache[K, V]) NamespaceStats() map[string]Statistics {
+	c.nsMu.Lock()
+	defer c.nsMu.Unlock()
+	stats := make(map[string]Statistics, len(c.namespaces))
+	for name, ns := range c.namespaces {
+		stats[name] = ns.Stats()
+	}
+	return stats
+}
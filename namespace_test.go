@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NamespaceTestSuite struct {
+	suite.Suite
+	loads atomic.Int32
+	cache *Cache[int, string]
+}
+
+func TestNamespaceSuite(t *testing.T) {
+	suite.Run(t, new(NamespaceTestSuite))
+}
+
+// SetupTest runs before each test
+func (s *NamespaceTestSuite) SetupTest() {
+	s.loads.Store(0)
+	s.cache = New[int, string](WithLoader(func(id int) (string, error) {
+		s.loads.Add(1)
+		return fmt.Sprintf("user-%d", id), nil
+	}))
+}
+
+// TestNamespacesAreIsolated verifies that namespaces don't share entries
+func (s *NamespaceTestSuite) TestNamespacesAreIsolated() {
+	tenantA := s.cache.Namespace("tenant-a")
+	tenantB := s.cache.Namespace("tenant-b")
+	s.Same(tenantA, s.cache.Namespace("tenant-a"), "Namespace should return the existing child")
+
+	tenantA.Set(1, "alice")
+	_, found := tenantB.Peek(1)
+	s.False(found)
+	_, found = s.cache.Peek(1)
+	s.False(found)
+
+	// Namespaces inherit the loader
+	value, err := tenantB.Get(1)
+	s.NoError(err)
+	s.Equal("user-1", value)
+	s.Equal(int32(1), s.loads.Load())
+
+	s.Equal([]string{"tenant-a", "tenant-b"}, s.cache.Namespaces())
+}
+
+// TestDropNamespaceInvalidatesTenant verifies invalidating an entire tenant in one call
+func (s *NamespaceTestSuite) TestDropNamespaceInvalidatesTenant() {
+	tenant := s.cache.Namespace("tenant-42")
+	for i := 0; i < 10; i++ {
+		_, err := tenant.Get(i)
+		s.NoError(err)
+	}
+	other := s.cache.Namespace("other")
+	other.Set(1, "kept")
+
+	s.cache.DropNamespace("tenant-42")
+	s.Equal(0, tenant.Stats().Entries)
+	s.Equal([]string{"other"}, s.cache.Namespaces())
+
+	fresh := s.cache.Namespace("tenant-42")
+	s.Equal(0, fresh.Stats().Entries)
+	value, found := other.Peek(1)
+	s.True(found)
+	s.Equal("kept", value)
+}
+
+// TestNamespaceLimitsAreEnforcedPerNamespace verifies per-namespace limits
+func (s *NamespaceTestSuite) TestNamespaceLimitsAreEnforcedPerNamespace() {
+	small := s.cache.Namespace("small", WithMaxEntries(2))
+	large := s.cache.Namespace("large")
+
+	for i := 0; i < 5; i++ {
+		small.Set(i, "x")
+		large.Set(i, "x")
+	}
+	s.Equal(2, small.Stats().Entries)
+	s.Equal(5, large.Stats().Entries)
+}
+
+// TestNamespacesPrefixStoreKeys verifies that namespaces don't collide in the backing store
+func (s *NamespaceTestSuite) TestNamespacesPrefixStoreKeys() {
+	remote := newMemoryStore()
+	c := New[int, string](WithStore(remote))
+
+	s.NoError(c.Namespace("a").SetThrough(1, "from a"))
+	s.NoError(c.Namespace("b").SetThrough(1, "from b"))
+
+	data, found, err := remote.Get(context.Background(), "a/"+keyString(c.valueType, 1))
+	s.NoError(err)
+	s.True(found)
+	s.Equal(`"from a"`, string(data))
+	data, _, _ = remote.Get(context.Background(), "b/"+keyString(c.valueType, 1))
+	s.Equal(`"from b"`, string(data))
+}
+
+// TestNamespaceNamesAreEscaped verifies that a name holding the separator
+// does not collide with a nested namespace in the backing store
+func (s *NamespaceTestSuite) TestNamespaceNamesAreEscaped() {
+	remote := newMemoryStore()
+	c := New[int, string](WithStore(remote))
+
+	s.NoError(c.Namespace("a/b").SetThrough(1, "flat"))
+	s.NoError(c.Namespace("a").Namespace("b").SetThrough(1, "nested"))
+
+	data, _, _ := remote.Get(context.Background(), "a%2Fb/"+keyString(c.valueType, 1))
+	s.Equal(`"flat"`, string(data))
+	data, _, _ = remote.Get(context.Background(), "a/b/"+keyString(c.valueType, 1))
+	s.Equal(`"nested"`, string(data))
+}
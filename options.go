@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures a Cache instance.
+type Option func(*options)
+
+type options struct {
+	// loader is a func(K) (V, error) matching the cache's type parameters
+	loader any
+	// parent is a *Cache[K, V] matching the cache's type parameters
+	parent       any
+	parentPolicy ParentPolicy
+	skipNil      bool
+	onEvent      func(Event)
+	onEvict      func(Removal)
+	remote       Store
+	codec        Codec
+	keyCodec     KeyCodec
+	nanKeys      NaNKeyPolicy
+	metrics      MetricsSink
+	clock        Clock
+
+	ttl        time.Duration
+	ttlSet     bool
+	maxEntries int
+	// maxEntriesPerType is the default entry limit of each value type
+	maxEntriesPerType int
+	maxBytes          int64
+	sizeOf            func(any) int64
+	// nsQuota limits the namespaces created from the cache
+	nsQuota *Quota
+	// keyPrefix is prepended to backing store keys of namespaces
+	keyPrefix string
+
+	writeBehind *WriteBehindConfig
+	// remoteWrites is how loads populate remote
+	remoteWrites RemoteWritePolicy
+	invalidation *InvalidationConfig
+	frequencies  *PersistentFrequencies
+
+	janitorInterval    time.Duration
+	memoryPressure     *MemoryPressureConfig
+	backpressure       *BackpressureConfig
+	adaptive           *AdaptiveConfig
+	persistOnShutdown  bool
+	warmup             int
+	trackFrequency     bool
+	expireSamples      int
+	closeValues        bool
+	closeGrace         time.Duration
+	weakValues         bool
+	spillover          *SpilloverConfig
+	hotFraction        float64
+	coalesceWindow     time.Duration
+	minRefreshInterval time.Duration
+	zeroValueTTL       time.Duration
+	bytesMode          BytesMode
+	typeNamer          TypeNamer
+	corruption         CorruptionPolicy
+	strict             bool
+	shadow             *ShadowConfig
+	faults             *FaultConfig
+	auditSize          int
+	interning          bool
+	internValueLen     int
+
+	// per-call options of Get
+	forceRefresh bool
+	skipCache    bool
+	tags         []string
+	priority     Priority
+	timeout      time.Duration
+	reason       string
+	expireAt     time.Time
+	// refresh marks the background loads of refresh-ahead
+	refresh bool
+	// ctx is the context of GetCtx, if any
+	ctx context.Context
+
+	refreshAhead float64
+	refreshLock  Locker
+	admission    AdmissionPolicy
+	rateLimit    *RateLimit
+	redactor     Redactor
+	absentFilter *AbsentFilterConfig
+	newBackend   func() Backend
+
+	initialCapacity int
+}
+
+// WithLoader registers the function used to load missing keys, switching
+// the cache to read-through mode. Its type parameters must match those of
+// the cache it is passed to.
+func WithLoader[K comparable, V any](fn func(K) (V, error)) Option {
+	return func(o *options) {
+		o.loader = fn
+	}
+}
+
+// WithNilCaching controls whether nil results from the loader are cached.
+// It is enabled by default.
+func WithNilCaching(enabled bool) Option {
+	return func(o *options) {
+		o.skipNil = !enabled
+	}
+}
+
+// WithEventHandler registers fn to receive diagnostic events from the cache.
+// The handler is called synchronously, outside of any cache lock.
+func WithEventHandler(fn func(Event)) Option {
+	return func(o *options) {
+		o.onEvent = fn
+	}
+}
@@ -0,0 +1,75 @@
+package cache
+
+import "fmt"
+
+// ParentPolicy decides which writes to a child cache reach its parent.
+type ParentPolicy int
+
+const (
+	// WriteLocal keeps the writes of a child to itself: its entries
+	// overlay those of the parent, and deleting one exposes the parent's
+	// again.
+	WriteLocal ParentPolicy = iota
+	// WriteThrough applies Set and Delete to the parent as well.
+	WriteThrough
+)
+
+// String returns a human-readable name for the policy.
+func (p ParentPolicy) String() string {
+	switch p {
+	case WriteLocal:
+		return "write-local"
+	case WriteThrough:
+		return "write-through"
+	default:
+		return "unknown"
+	}
+}
+
+// WithParent layers the cache over parent, typically a cache of data
+// shared by every tenant with one child per tenant: Get and Peek serve the
+// parent's entry on a local miss before calling the loader or reporting
+// ErrNotCached, and loaded values are stored in the child. Writes stay in
+// the child unless WithParentPolicy says otherwise. Its type parameters
+// must match those of the cache it is passed to.
+func WithParent[K comparable, V any](parent *Cache[K, V]) Option {
+	return func(o *options) {
+		o.parent = parent
+	}
+}
+
+// WithParentPolicy sets which writes of a cache created with WithParent
+// reach the parent. The default is WriteLocal.
+func WithParentPolicy(policy ParentPolicy) Option {
+	return func(o *options) {
+		o.parentPolicy = policy
+	}
+}
+
+// setParent installs the parent passed to WithParent, panicking if it does
+// not match the cache's type parameters.
+func (c *Cache[K, V]) setParent(parent any) {
+	p, ok := parent.(*Cache[K, V])
+	if !ok {
+		panic(fmt.Sprintf("cache: parent of type %T does not match Cache[%v, %v]", parent, getTypeOf(*new(K)), c.valueType))
+	}
+	c.parent = p
+}
+
+// writesThrough reports whether writes must also be applied to the parent.
+func (c *Cache[K, V]) writesThrough() bool {
+	return c.parent != nil && c.opts.parentPolicy == WriteThrough
+}
+
+// fromParent returns the value cached for key by the nearest ancestor of
+// c holding one, counting a hit there.
+func (c *Cache[K, V]) fromParent(key K) (V, bool) {
+	for p := c.parent; p != nil; p = p.parent {
+		if value, ok := cached[K, V](p.s, key, true); ok {
+			p.s.recordHit(c.valueType, key)
+			return value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
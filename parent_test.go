@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ParentTestSuite struct {
+	suite.Suite
+	calls atomic.Int32
+}
+
+func TestParentSuite(t *testing.T) {
+	suite.Run(t, new(ParentTestSuite))
+}
+
+func (s *ParentTestSuite) SetupTest() {
+	s.calls.Store(0)
+}
+
+func (s *ParentTestSuite) loader(key string) (string, error) {
+	s.calls.Add(1)
+	return "tenant " + key, nil
+}
+
+// TestOverlay verifies that a write-local child overlays its parent
+func (s *ParentTestSuite) TestOverlay() {
+	shared := New[string, string]()
+	shared.Set("theme", "light")
+	shared.Set("logo", "default.png")
+	tenant := New[string, string](WithParent(shared), WithLoader(s.loader))
+	tenant.Set("theme", "dark")
+
+	value, err := tenant.Get("theme")
+	s.NoError(err)
+	s.Equal("dark", value)
+	value, err = tenant.Get("logo")
+	s.NoError(err)
+	s.Equal("default.png", value, "Local misses must be served by the parent")
+
+	value, err = tenant.Get("locale")
+	s.NoError(err)
+	s.Equal("tenant locale", value)
+	s.Equal(int32(1), s.calls.Load())
+	_, found := shared.Peek("locale")
+	s.False(found, "Loads must stay in the child")
+
+	value, _ = shared.Peek("theme")
+	s.Equal("light", value, "Writes must stay in the child")
+	tenant.Delete("theme")
+	value, _ = tenant.Peek("theme")
+	s.Equal("light", value, "Deleting an overlay must expose the parent's entry")
+}
+
+// TestWriteThrough verifies that Set and Delete reach the parent with the
+// WriteThrough policy
+func (s *ParentTestSuite) TestWriteThrough() {
+	root := New[string, string]()
+	middle := New[string, string](WithParent(root))
+	leaf := New[string, string](WithParent(middle), WithParentPolicy(WriteThrough))
+
+	root.Set("region", "eu")
+	value, err := leaf.Get("region")
+	s.NoError(err)
+	s.Equal("eu", value, "Misses must fall through every ancestor")
+
+	leaf.Set("plan", "pro")
+	value, _ = middle.Peek("plan")
+	s.Equal("pro", value)
+	leaf.Delete("plan")
+	_, err = leaf.Get("plan")
+	s.True(errors.Is(err, ErrNotCached))
+}
+
+// TestParentTypeMismatch verifies that New rejects a parent of other types
+func (s *ParentTestSuite) TestParentTypeMismatch() {
+	parent := New[string, int]()
+	s.Panics(func() {
+		New[string, string](WithParent(parent))
+	})
+}
@@ -0,0 +1,56 @@
+package cache
+
+// Priority ranks entries for capacity eviction: when the cache is full,
+// lower-priority entries are evicted before higher-priority ones, and
+// entries of equal priority in least recently used order.
+type Priority int8
+
+const (
+	// PriorityLow marks entries that are cheap to recompute or
+	// speculative, such as prefetches.
+	PriorityLow Priority = -1
+	// PriorityNormal is the priority of entries by default.
+	PriorityNormal Priority = 0
+	// PriorityHigh marks entries that are expensive to recompute or
+	// critical to users.
+	PriorityHigh Priority = 1
+)
+
+// String returns a human-readable name for the priority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// WithPriority sets the eviction priority of entries. Passed to Get it
+// applies to the entry the call stores, to Configure to every entry of the
+// type, and to New or SetDefaults to every other entry.
+//
+//	// Prefetched entries give way first when the cache is full
+//	cache.Get(id, loadProduct, cache.WithPriority(cache.PriorityLow))
+func WithPriority(p Priority) Option {
+	return func(o *options) {
+		o.priority = p
+	}
+}
+
+// evictsBefore reports whether e should be evicted before other, which
+// was accessed at otherAccess: lower priorities go first, then entries of
+// the cold segment, then the least recently used.
+func (e *entry) evictsBefore(access uint64, other *entry, otherAccess uint64) bool {
+	if e.priority != other.priority {
+		return e.priority < other.priority
+	}
+	if hot, otherHot := e.hot.Load(), other.hot.Load(); hot != otherHot {
+		return otherHot
+	}
+	return access < otherAccess
+}
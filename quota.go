@@ -0,0 +1,57 @@
+package cache
+
+import "reflect"
+
+// Quota limits the size of a cache. Zero fields mean unlimited.
+type Quota struct {
+	// MaxEntries is the maximum number of entries.
+	MaxEntries int
+	// MaxBytes is the maximum estimated size of the cached values. Sizes
+	// are estimated with the function set by WithSizeEstimator, or by
+	// reflection if none is set.
+	MaxBytes int64
+}
+
+// WithQuota limits the cache it is applied to. When a write exceeds either
+// limit, the least recently used entries are evicted; a single value larger
+// than MaxBytes is not cached at all.
+func WithQuota(q Quota) Option {
+	return func(o *options) {
+		o.maxEntries = q.MaxEntries
+		o.maxBytes = q.MaxBytes
+	}
+}
+
+// WithMaxBytes sets a memory budget for the whole cache, across all value
+// types. Value sizes are estimated with the function set by
+// WithSizeEstimator, or by reflection if none is set; when a write takes
+// the cache over budget the least recently used entries are evicted,
+// whatever their type. Zero means unbounded.
+func WithMaxBytes(n int64) Option {
+	return func(o *options) {
+		o.maxBytes = n
+	}
+}
+
+// measureLocked estimates the size of every entry, for entries stored
+// while sizes were not tracked. Must be called with s.mu held for writing.
+func (s *store) measureLocked() {
+	sizeOf := s.cfg().sizeOf
+	s.bytes = 0
+	s.rangeAllLocked(func(_ reflect.Type, _ any, e *entry) bool {
+		if value, live := e.get(); live {
+			e.size = sizeOf(value)
+		}
+		s.bytes += e.size
+		return true
+	})
+}
+
+// WithNamespaceQuota sets the quota of every namespace created from the
+// cache, so one busy namespace can never grow at the expense of the
+// others. Options passed to Namespace take precedence.
+func WithNamespaceQuota(q Quota) Option {
+	return func(o *options) {
+		o.nsQuota = &q
+	}
+}
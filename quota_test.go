@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QuotaTestSuite struct {
+	suite.Suite
+}
+
+func TestQuotaSuite(t *testing.T) {
+	suite.Run(t, new(QuotaTestSuite))
+}
+
+// byteLen sizes strings by their length to make byte limits predictable
+func byteLen(value any) int64 {
+	return int64(len(value.(string)))
+}
+
+// TestMaxBytesEvictsLeastRecentlyUsed verifies byte-based eviction
+func (s *QuotaTestSuite) TestMaxBytesEvictsLeastRecentlyUsed() {
+	c := New[int, string](WithQuota(Quota{MaxBytes: 10}), WithSizeEstimator(byteLen))
+
+	c.Set(1, "aaaa")
+	c.Set(2, "bbbb")
+	s.Equal(int64(8), c.Stats().Bytes)
+
+	c.Set(3, "cccc")
+	_, found := c.Peek(1)
+	s.False(found, "Oldest entry should be evicted to make room")
+	s.Equal(int64(8), c.Stats().Bytes)
+
+	// Replacing an entry accounts for the size difference
+	c.Set(3, "cc")
+	s.Equal(int64(6), c.Stats().Bytes)
+}
+
+// TestOversizedValueIsNotCached verifies that a value larger than the quota is dropped
+func (s *QuotaTestSuite) TestOversizedValueIsNotCached() {
+	c := New[int, string](WithQuota(Quota{MaxBytes: 10}), WithSizeEstimator(byteLen))
+	c.Set(1, "small")
+	c.Set(2, strings.Repeat("x", 11))
+
+	_, found := c.Peek(2)
+	s.False(found)
+	_, found = c.Peek(1)
+	s.False(found, "Entries are evicted before the oversized value is dropped")
+	s.Equal(int64(0), c.Stats().Bytes)
+}
+
+// TestNamespaceQuotaProtectsOtherTenants verifies fairness between namespaces
+func (s *QuotaTestSuite) TestNamespaceQuotaProtectsOtherTenants() {
+	c := New[int, string](WithNamespaceQuota(Quota{MaxEntries: 3}))
+
+	quiet := c.Namespace("quiet")
+	noisy := c.Namespace("noisy")
+	quiet.Set(1, "important")
+
+	for i := 0; i < 100; i++ {
+		noisy.Set(i, "spam")
+	}
+
+	value, found := quiet.Peek(1)
+	s.True(found, "A noisy tenant must not evict other tenants' entries")
+	s.Equal("important", value)
+
+	stats := c.NamespaceStats()
+	s.Equal(1, stats["quiet"].Entries)
+	s.Equal(3, stats["noisy"].Entries)
+	s.Equal(uint64(97), stats["noisy"].Evictions)
+	s.Equal(uint64(0), stats["quiet"].Evictions)
+}
+
+// TestNamespaceOptionsOverrideQuota verifies per-namespace overrides
+func (s *QuotaTestSuite) TestNamespaceOptionsOverrideQuota() {
+	c := New[int, string](WithNamespaceQuota(Quota{MaxEntries: 1}))
+	vip := c.Namespace("vip", WithMaxEntries(5))
+
+	for i := 0; i < 5; i++ {
+		vip.Set(i, "x")
+	}
+	s.Equal(5, vip.Stats().Entries)
+}
+
+// TestEstimateSize verifies the default reflection-based size estimator
+func (s *QuotaTestSuite) TestEstimateSize() {
+	type user struct {
+		Name  string
+		Tags  []string
+		Attrs map[string]string
+	}
+
+	small := estimateSize(&user{Name: "a"})
+	large := estimateSize(&user{
+		Name:  strings.Repeat("a", 1000),
+		Tags:  []string{"x", "y"},
+		Attrs: map[string]string{"k": "v"},
+	})
+	s.Greater(small, int64(0))
+	s.Greater(large, small+1000)
+	s.Equal(int64(0), estimateSize(nil))
+
+	// Shared memory is counted once
+	shared := strings.Repeat("b", 100)
+	p := &shared
+	s.Less(estimateSize([]*string{p, p}), estimateSize([]*string{p, &[]string{shared}[0]}))
+}
@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// RateLimitMode decides what a load does when the origin rate limit is
+// reached.
+type RateLimitMode int
+
+const (
+	// RateLimitWait waits for the limiter to allow the getter call. Callers
+	// using WithTimeout stop waiting when it runs out.
+	RateLimitWait RateLimitMode = iota
+	// RateLimitFailFast fails the load with ErrRateLimited.
+	RateLimitFailFast
+	// RateLimitServeStale serves the expired entry of the key if it is
+	// still held, without caching it again, and fails with ErrRateLimited
+	// otherwise.
+	RateLimitServeStale
+)
+
+// String returns a human-readable name for the mode.
+func (m RateLimitMode) String() string {
+	switch m {
+	case RateLimitWait:
+		return "wait"
+	case RateLimitFailFast:
+		return "fail-fast"
+	case RateLimitServeStale:
+		return "serve-stale"
+	default:
+		return "unknown"
+	}
+}
+
+// Limiter allows getter calls. NewLimiter returns one, and a *rate.Limiter
+// from golang.org/x/time/rate satisfies Limiter as it is.
+type Limiter interface {
+	// Allow reports whether a call may happen now.
+	Allow() bool
+	// Wait blocks until a call may happen or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// RateLimit bounds how often getters are called, protecting the origin
+// from a cold cache or a flood of distinct keys.
+type RateLimit struct {
+	// Limiter allows getter calls; hits and coalesced loads are not
+	// limited.
+	Limiter Limiter
+	// Mode is what loads do when Limiter does not allow them.
+	Mode RateLimitMode
+}
+
+// WithRateLimit limits the getter calls of the cache, or, passed to
+// Configure, those of one value type. A limit set for a type takes
+// precedence over the cache-wide one. Background refreshes are limited
+// too.
+//
+//	cache.Configure[*Quote](cache.WithRateLimit(cache.RateLimit{
+//		Limiter: cache.NewLimiter(100*time.Millisecond, 5),
+//		Mode:    cache.RateLimitServeStale,
+//	}))
+func WithRateLimit(limit RateLimit) Option {
+	return func(o *options) {
+		o.rateLimit = &limit
+	}
+}
+
+// rateLimitFor returns the rate limit of the getter calls for valueType,
+// or nil if there is none.
+func (s *store) rateLimitFor(valueType reflect.Type) *RateLimit {
+	if limit := s.configFor(valueType).rateLimit; limit != nil {
+		return limit
+	}
+	return s.cfg().rateLimit
+}
+
+// allowLoad waits for or checks the rate limit of valueType before the
+// getter call of a load made with call. Waits end with the context of
+// GetCtx and after WithTimeout. When the call is not allowed, serveStale
+// reports whether an expired entry may be served instead.
+func (s *store) allowLoad(call options, valueType reflect.Type) (serveStale bool, err error) {
+	limit := s.rateLimitFor(valueType)
+	if limit == nil || limit.Limiter == nil {
+		return false, nil
+	}
+	if limit.Mode == RateLimitWait {
+		ctx := call.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if call.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, call.timeout)
+			defer cancel()
+		}
+		if err := limit.Limiter.Wait(ctx); err != nil {
+			return false, fmt.Errorf("%w: %v", ErrRateLimited, err)
+		}
+		return false, nil
+	}
+	if limit.Limiter.Allow() {
+		return false, nil
+	}
+	return limit.Mode == RateLimitServeStale, ErrRateLimited
+}
+
+// staleValue returns the value of the entry stored for key, even if it
+// has expired.
+func (s *store) staleValue(valueType reflect.Type, key any) (any, bool) {
+	s.rlock()
+	e, ok := s.entryLocked(valueType, key)
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return e.get()
+}
+
+// NewLimiter returns a token bucket Limiter allowing one call per interval
+// on average and bursts of up to burst calls. It starts full. A zero or
+// negative interval allows every call.
+func NewLimiter(interval time.Duration, burst int) Limiter {
+	return &tokenBucket{
+		interval: interval,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// tokenBucket is the Limiter returned by NewLimiter.
+type tokenBucket struct {
+	interval time.Duration
+	burst    float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// take takes a token if one is available. Otherwise it returns how long
+// until one is.
+func (b *tokenBucket) take() (bool, time.Duration) {
+	if b.interval <= 0 {
+		return true, 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) * float64(b.interval))
+}
+
+// Allow reports whether a call may happen now, taking a token if so.
+func (b *tokenBucket) Allow() bool {
+	ok, _ := b.take()
+	return ok
+}
+
+// Wait blocks until a token is available and takes it, or until ctx is
+// done. It fails at once if the bucket can never hold a token.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	if b.burst < 1 && b.interval > 0 {
+		return errors.New("limiter burst is below 1")
+	}
+	for {
+		ok, wait := b.take()
+		if ok {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
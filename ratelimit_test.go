@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/time/rate"
+)
+
+type RateLimitTestSuite struct {
+	suite.Suite
+	calls atomic.Int32
+}
+
+func TestRateLimitSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitTestSuite))
+}
+
+func (s *RateLimitTestSuite) SetupTest() {
+	s.calls.Store(0)
+}
+
+func (s *RateLimitTestSuite) getter(key string) (string, error) {
+	s.calls.Add(1)
+	return "value of " + key, nil
+}
+
+// TestFailFast verifies that loads over the limit fail without calling the
+// getter
+func (s *RateLimitTestSuite) TestFailFast() {
+	c := New[string, string](WithLoader(s.getter), WithRateLimit(RateLimit{
+		Limiter: NewLimiter(time.Hour, 1),
+		Mode:    RateLimitFailFast,
+	}))
+
+	_, err := c.Get("a")
+	s.NoError(err)
+	_, err = c.Get("a")
+	s.NoError(err, "Hits are not limited")
+	_, err = c.Get("b")
+	s.True(errors.Is(err, ErrRateLimited))
+	s.Equal(int32(1), s.calls.Load())
+}
+
+// TestServeStale verifies that expired entries are served while the limit
+// is reached
+func (s *RateLimitTestSuite) TestServeStale() {
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := New[string, string](WithLoader(s.getter), WithClock(clock), WithTTL(time.Minute), WithRateLimit(RateLimit{
+		Limiter: NewLimiter(time.Hour, 1),
+		Mode:    RateLimitServeStale,
+	}))
+
+	_, err := c.Get("a")
+	s.NoError(err)
+	clock.Advance(time.Minute)
+
+	value, err := c.Get("a")
+	s.NoError(err)
+	s.Equal("value of a", value)
+	s.Equal(int32(1), s.calls.Load())
+
+	_, err = c.Get("b")
+	s.True(errors.Is(err, ErrRateLimited), "Keys without a stale entry must fail")
+}
+
+// TestWait verifies that loads over the limit wait for it
+func (s *RateLimitTestSuite) TestWait() {
+	c := New[string, string](WithLoader(s.getter), WithRateLimit(RateLimit{
+		Limiter: NewLimiter(50*time.Millisecond, 1),
+	}))
+
+	start := time.Now()
+	for _, key := range []string{"a", "b", "c"} {
+		_, err := c.Get(key)
+		s.NoError(err)
+	}
+	s.GreaterOrEqual(time.Since(start), 90*time.Millisecond)
+	s.Equal(int32(3), s.calls.Load())
+}
+
+// TestLimiter verifies that NewLimiter allows bursts and refills over time
+func (s *RateLimitTestSuite) TestLimiter() {
+	limiter := NewLimiter(20*time.Millisecond, 2)
+	s.True(limiter.Allow())
+	s.True(limiter.Allow())
+	s.False(limiter.Allow())
+
+	start := time.Now()
+	s.NoError(limiter.Wait(context.Background()))
+	s.GreaterOrEqual(time.Since(start), 10*time.Millisecond)
+
+	slow := NewLimiter(time.Hour, 1)
+	s.True(slow.Allow())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	s.ErrorIs(slow.Wait(ctx), context.DeadlineExceeded)
+	s.Error(NewLimiter(time.Hour, 0).Wait(context.Background()), "An empty bucket must not block forever")
+}
+
+// TestUnlimitedInterval verifies that a zero interval allows every call
+// instead of spinning
+func (s *RateLimitTestSuite) TestUnlimitedInterval() {
+	c := New[string, string](WithLoader(s.getter), WithRateLimit(RateLimit{Limiter: NewLimiter(0, 2)}))
+	for _, key := range []string{"a", "b", "c"} {
+		_, err := c.Get(key)
+		s.NoError(err)
+	}
+	s.Equal(int32(3), s.calls.Load())
+}
+
+// TestWaitEndsWithTheCaller verifies that waiting for the limit ends with
+// the context of GetCtx and with WithTimeout
+func (s *RateLimitTestSuite) TestWaitEndsWithTheCaller() {
+	Scoped(s.T())
+	SetDefaults(WithRateLimit(RateLimit{Limiter: NewLimiter(time.Hour, 1)}))
+	_, err := GetCtx(context.Background(), "a", s.getter)
+	s.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = GetCtx(ctx, "b", s.getter)
+	s.Error(err)
+	_, err = Get("c", s.getter, WithTimeout(10*time.Millisecond))
+	s.Error(err)
+	s.Equal(int32(1), s.calls.Load())
+}
+
+// TestRateLimiter verifies that a *rate.Limiter satisfies Limiter
+func (s *RateLimitTestSuite) TestRateLimiter() {
+	var limiter Limiter = rate.NewLimiter(rate.Every(time.Hour), 1)
+	c := New[string, string](WithLoader(s.getter), WithRateLimit(RateLimit{
+		Limiter: limiter,
+		Mode:    RateLimitFailFast,
+	}))
+	_, err := c.Get("a")
+	s.NoError(err)
+	_, err = c.Get("b")
+	s.ErrorIs(err, ErrRateLimited)
+}
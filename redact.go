@@ -0,0 +1,49 @@
+package cache
+
+import "reflect"
+
+// Redactor masks keys and values before the cache lets them out for
+// observability: in the Key of events, in error messages, in the hot keys
+// of Stats and in DumpJSON. It is how personal data such as e-mail
+// addresses used as keys stays out of logs. valueType is the name of the
+// value type, as in Statistics.Types.
+type Redactor interface {
+	RedactKey(valueType string, key any) string
+	RedactValue(valueType string, value any) any
+}
+
+// WithRedactor applies r to every key and value the cache reports. The
+// keys returned in errors, such as LoadError.Key, are left intact for
+// callers; only their messages are redacted.
+func WithRedactor(r Redactor) Option {
+	return func(o *options) {
+		o.redactor = r
+	}
+}
+
+// redactKey returns key as it may be shown for valueType: the key itself
+// without a redactor.
+func (s *store) redactKey(valueTypeName string, key any) any {
+	if r := s.cfg().redactor; r != nil {
+		return r.RedactKey(valueTypeName, key)
+	}
+	return key
+}
+
+// loadError returns the LoadError of a failed load of key, with a redacted
+// message if needed.
+func (s *store) loadError(valueType reflect.Type, key any, err error) *LoadError {
+	le := &LoadError{Key: key, Err: err}
+	if s.cfg().redactor != nil {
+		le.shownKey = s.redactKey(s.typeName(valueType), key)
+	}
+	return le
+}
+
+// redactValue returns value as it may be shown for valueType.
+func (s *store) redactValue(valueTypeName string, value any) any {
+	if r := s.cfg().redactor; r != nil {
+		return r.RedactValue(valueTypeName, value)
+	}
+	return value
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RedactTestSuite struct {
+	suite.Suite
+}
+
+func TestRedactSuite(t *testing.T) {
+	suite.Run(t, new(RedactTestSuite))
+}
+
+// maskingRedactor hides every key and value.
+type maskingRedactor struct{}
+
+func (maskingRedactor) RedactKey(valueType string, key any) string {
+	return valueType + ":***"
+}
+
+func (maskingRedactor) RedactValue(valueType string, value any) any {
+	return "***"
+}
+
+// TestErrorsAreRedacted verifies that error messages hide the key while
+// LoadError keeps it for callers
+func (s *RedactTestSuite) TestErrorsAreRedacted() {
+	c := New[string, int](
+		WithRedactor(maskingRedactor{}),
+		WithLoader(func(key string) (int, error) { return 0, errors.New("boom") }),
+	)
+	_, err := c.Get("alice@example.com")
+
+	var loadErr *LoadError
+	s.Require().ErrorAs(err, &loadErr)
+	s.Equal("alice@example.com", loadErr.Key)
+	s.NotContains(err.Error(), "alice")
+	s.Contains(err.Error(), "int:***")
+}
+
+// TestEventsAreRedacted verifies that event handlers only see redacted keys
+func (s *RedactTestSuite) TestEventsAreRedacted() {
+	var events []Event
+	c := New[string, *resource](
+		WithRedactor(maskingRedactor{}),
+		WithEventHandler(func(ev Event) { events = append(events, ev) }),
+	)
+	c.s.emit(Event{Kind: EventStoreError, Type: c.valueType, Key: "alice@example.com"})
+
+	s.Require().Len(events, 1)
+	s.Equal("*cache.resource:***", events[0].Key)
+}
+
+// TestDumpsAndStatsAreRedacted verifies that dumps and hot keys hide keys
+// and values
+func (s *RedactTestSuite) TestDumpsAndStatsAreRedacted() {
+	c := New[string, string](WithRedactor(maskingRedactor{}), WithFrequencyTracking())
+	c.Set("alice@example.com", "Alice Liddell")
+	_, err := c.Get("alice@example.com")
+	s.NoError(err)
+
+	var buf bytes.Buffer
+	s.NoError(c.DumpJSON(&buf, DumpOptions{IncludeValues: true}))
+	s.NotContains(buf.String(), "alice")
+	s.NotContains(buf.String(), "Alice")
+	s.Contains(buf.String(), `"***"`)
+
+	report := c.Stats().String()
+	s.NotContains(report, "alice")
+	s.True(strings.Contains(report, "string:***"))
+}
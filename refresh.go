@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// refreshLockPrefix starts the names of refresh-ahead locks.
+const refreshLockPrefix = "__refresh__:"
+
+// errRefreshSkipped is returned by a refresh-ahead load that another
+// replica holds the lock for.
+var errRefreshSkipped = errors.New("cache: refresh held by another replica")
+
+// WithRefreshAhead reloads entries in the background once fraction of
+// their time to live has elapsed and they are read again, so hot keys are
+// replaced before they expire and readers never wait for the getter. The
+// read that triggers the refresh is served from the cache. fraction must
+// be between 0 and 1; entries without a TTL are never refreshed ahead.
+//
+// Refresh-ahead applies to Get with a getter and to read-through
+// instances.
+func WithRefreshAhead(fraction float64) Option {
+	return func(o *options) {
+		o.refreshAhead = fraction
+	}
+}
+
+// Locker is a lock shared by the replicas of a service, typically
+// implemented with a Redis SET NX PX command or a database row. With
+// WithRefreshLock, a replica only refreshes an entry ahead of expiry while
+// holding its lock.
+type Locker interface {
+	// TryLock acquires the lock named key for at most ttl without
+	// waiting, and reports whether it did.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Unlock releases a lock acquired with TryLock.
+	Unlock(ctx context.Context, key string) error
+}
+
+// WithRefreshLock coordinates refresh-ahead across replicas sharing a
+// backing store: an entry is only refreshed by the replica that acquires
+// its lock, which then writes the new value to the store for the others to
+// pick up. Replicas that do not get the lock keep serving their entry
+// until it expires. If the locker fails, the entry is refreshed anyway.
+func WithRefreshLock(locker Locker) Option {
+	return func(o *options) {
+		o.refreshLock = locker
+	}
+}
+
+// setExpiry makes e expire ttl from now and, with refresh-ahead, become due
+// for refresh once the configured fraction of ttl has elapsed.
+func (s *store) setExpiry(e *entry, ttl time.Duration) {
+	e.ttl = ttl
+	e.expiresAt = s.expiry(ttl)
+	if fraction := s.cfg().refreshAhead; fraction > 0 && fraction < 1 && ttl > 0 {
+		e.refreshAt = s.now().Add(time.Duration(float64(ttl) * fraction))
+	}
+}
+
+// dueForRefresh reports whether e should be refreshed ahead of expiry.
+func (e *entry) dueForRefresh(now time.Time) bool {
+	return !e.refreshAt.IsZero() && !now.Before(e.refreshAt) && !e.refreshing.Load()
+}
+
+// refreshAhead reloads key in the background unless a refresh of e is
+// already running or the store is shutting down.
+func refreshAhead[K comparable, V any](s *store, e *entry, key K, getterFunc weightedGetter[K, V]) {
+	if !e.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	s.goBackground(func() {
+		_, err := load(s, key, getterFunc, options{
+			refresh:  true,
+			ttl:      e.ttl,
+			ttlSet:   true,
+			tags:     e.tags,
+			priority: e.priority,
+		})
+		if err != nil && !errors.Is(err, errRefreshSkipped) {
+			// Let a later read try again
+			e.refreshing.Store(false)
+		}
+	})
+}
+
+// goBackground runs fn on a goroutine that shutdown waits for, unless
+// shutdown has begun.
+func (s *store) goBackground(fn func()) {
+	s.refreshMu.Lock()
+	if s.refreshStopped {
+		s.refreshMu.Unlock()
+		return
+	}
+	s.refreshes.Add(1)
+	s.refreshMu.Unlock()
+
+	go func() {
+		defer s.refreshes.Done()
+		fn()
+	}()
+}
+
+// lockRefresh acquires the refresh lock of key, if one is configured, and
+// returns the function releasing it. It returns errRefreshSkipped if
+// another replica holds the lock.
+func (s *store) lockRefresh(valueType reflect.Type, key any, ttl time.Duration) (unlock func(), err error) {
+	locker := s.cfg().refreshLock
+	if locker == nil {
+		return func() {}, nil
+	}
+	name := refreshLockPrefix + s.remoteKey(valueType, key)
+	ctx := context.Background()
+	acquired, err := locker.TryLock(ctx, name, ttl)
+	if err != nil {
+		s.emit(Event{Kind: EventStoreError, Type: valueType, Key: key, Err: fmt.Errorf("acquiring refresh lock: %w", err)})
+		return func() {}, nil
+	}
+	if !acquired {
+		return nil, errRefreshSkipped
+	}
+	return func() {
+		if err := locker.Unlock(ctx, name); err != nil {
+			s.emit(Event{Kind: EventStoreError, Type: valueType, Key: key, Err: fmt.Errorf("releasing refresh lock: %w", err)})
+		}
+	}, nil
+}
+
+// stopRefreshes prevents new refreshes and waits for running ones to
+// finish or for ctx to end.
+func (s *store) stopRefreshes(ctx context.Context) error {
+	s.refreshMu.Lock()
+	s.refreshStopped = true
+	s.refreshMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.refreshes.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
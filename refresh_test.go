@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// memoryLocker is an in-process Locker
+type memoryLocker struct {
+	mu       sync.Mutex
+	held     map[string]bool
+	acquired []string
+}
+
+func newMemoryLocker() *memoryLocker {
+	return &memoryLocker{held: make(map[string]bool)}
+}
+
+func (l *memoryLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[key] {
+		return false, nil
+	}
+	l.held[key] = true
+	l.acquired = append(l.acquired, key)
+	return true, nil
+}
+
+func (l *memoryLocker) Unlock(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, key)
+	return nil
+}
+
+type RefreshTestSuite struct {
+	suite.Suite
+	clock *FakeClock
+	calls atomic.Int32
+}
+
+func TestRefreshSuite(t *testing.T) {
+	suite.Run(t, new(RefreshTestSuite))
+}
+
+// SetupTest runs before each test
+func (s *RefreshTestSuite) SetupTest() {
+	s.clock = NewFakeClock(time.Now())
+	s.calls.Store(0)
+}
+
+func (s *RefreshTestSuite) loader(key int) (int32, error) {
+	return s.calls.Add(1), nil
+}
+
+func (s *RefreshTestSuite) newCache(opts ...Option) *Cache[int, int32] {
+	opts = append([]Option{
+		WithClock(s.clock),
+		WithTTL(time.Minute),
+		WithRefreshAhead(0.5),
+		WithLoader(s.loader),
+	}, opts...)
+	return New[int, int32](opts...)
+}
+
+// TestRefreshAheadReloadsInBackground verifies that a late read is served
+// from the cache while the entry is replaced
+func (s *RefreshTestSuite) TestRefreshAheadReloadsInBackground() {
+	c := s.newCache()
+
+	_, err := c.Get(1)
+	s.NoError(err)
+	s.clock.Advance(30 * time.Second)
+
+	value, err := c.Get(1)
+	s.NoError(err)
+	s.Equal(int32(1), value, "The triggering read should be served from the cache")
+	s.NoError(c.Shutdown(context.Background()))
+
+	value, _ = c.Peek(1)
+	s.Equal(int32(2), value)
+	stats := c.Stats()
+	s.Equal(uint64(1), stats.Hits)
+	s.Equal(uint64(1), stats.Misses, "Refreshes should not count as lookups")
+}
+
+// TestNoRefreshBeforeThreshold verifies that fresh entries are left alone
+func (s *RefreshTestSuite) TestNoRefreshBeforeThreshold() {
+	c := s.newCache()
+
+	_, _ = c.Get(1)
+	s.clock.Advance(29 * time.Second)
+	_, _ = c.Get(1)
+	s.NoError(c.Shutdown(context.Background()))
+
+	s.Equal(int32(1), s.calls.Load())
+}
+
+// TestRefreshLockSkipsHeldKeys verifies that only the lock holder refreshes
+func (s *RefreshTestSuite) TestRefreshLockSkipsHeldKeys() {
+	locker := newMemoryLocker()
+	c := s.newCache(WithRefreshLock(locker))
+	_, _ = c.Get(1)
+
+	// Another replica is refreshing key 1
+	held, _ := locker.TryLock(context.Background(), refreshLockPrefix+c.s.remoteKey(c.valueType, 1), time.Minute)
+	s.True(held)
+
+	s.clock.Advance(30 * time.Second)
+	_, _ = c.Get(1)
+	_, _ = c.Get(1)
+	s.NoError(c.Shutdown(context.Background()))
+
+	s.Equal(int32(1), s.calls.Load(), "The key should not be recomputed while another replica holds its lock")
+}
+
+// TestRefreshLockIsReleased verifies that a replica releases its lock
+func (s *RefreshTestSuite) TestRefreshLockIsReleased() {
+	locker := newMemoryLocker()
+	c := s.newCache(WithRefreshLock(locker))
+	_, _ = c.Get(1)
+
+	s.clock.Advance(30 * time.Second)
+	_, _ = c.Get(1)
+	s.NoError(c.Shutdown(context.Background()))
+
+	s.Equal(int32(2), s.calls.Load())
+	s.Len(locker.acquired, 1)
+	s.Empty(locker.held)
+}
+
+// TestPackageLevelRefreshAhead verifies SetDefaults(WithRefreshAhead)
+func (s *RefreshTestSuite) TestPackageLevelRefreshAhead() {
+	Scoped(s.T())
+	SetDefaults(WithClock(s.clock), WithTTL(time.Minute), WithRefreshAhead(0.5))
+
+	_, _ = Get(1, s.loader)
+	s.clock.Advance(45 * time.Second)
+	_, _ = Get(1, s.loader)
+	s.NoError(Shutdown(context.Background()))
+
+	value, _ := Peek[int, int32](1)
+	s.Equal(int32(2), value)
+}
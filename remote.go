@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Store is a remote or persistent backing store, such as Redis or a disk
+// cache, that holds encoded values under string keys. Implementations must
+// be safe for concurrent use.
+type Store interface {
+	// Get returns the value stored under key. found is false when the key
+	// does not exist.
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	// Set stores value under key. A zero ttl means the value does not
+	// expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Codec converts values to and from the bytes kept in a Store.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec is the default Codec, based on encoding/json.
+type JSONCodec struct{}
+
+// Marshal encodes v as JSON.
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes JSON data into v.
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// WithStore configures a backing store. Misses are looked up in the store
+// before calling the loader, loaded values are written to it as set by
+// WithRemoteWrites, and SetThrough becomes available. Set and Delete only
+// affect local memory.
+func WithStore(st Store) Option {
+	return func(o *options) {
+		o.remote = st
+	}
+}
+
+// RemoteWritePolicy decides how values loaded by a cache with a backing
+// store populate the store.
+type RemoteWritePolicy int
+
+const (
+	// RemoteWriteThrough writes loaded values to the store before Get
+	// returns. It is the default.
+	RemoteWriteThrough RemoteWritePolicy = iota
+	// RemoteWriteBehind queues loaded values on the write-behind worker,
+	// so that loads do not wait for the store. Without WithWriteBehind it
+	// behaves like RemoteWriteThrough.
+	RemoteWriteBehind
+	// RemoteLocalOnly keeps the values in local memory only, for values
+	// too large or too sensitive for the store: the store is neither read
+	// nor written, and SetThrough returns ErrNoStore.
+	RemoteLocalOnly
+)
+
+// String returns a human-readable name for the policy.
+func (p RemoteWritePolicy) String() string {
+	switch p {
+	case RemoteWriteThrough:
+		return "write-through"
+	case RemoteWriteBehind:
+		return "write-behind"
+	case RemoteLocalOnly:
+		return "local-only"
+	default:
+		return "unknown"
+	}
+}
+
+// WithRemoteWrites sets how a cache populates its backing store. A cache
+// holds a single value type, so caches of types with different needs,
+// such as large reports next to small profiles, each get their own
+// policy. The default is RemoteWriteThrough; it has no effect without
+// WithStore.
+//
+//	tokens := cache.New[string, *Token](
+//		cache.WithStore(redisStore),
+//		cache.WithRemoteWrites(cache.RemoteLocalOnly),
+//	)
+func WithRemoteWrites(policy RemoteWritePolicy) Option {
+	return func(o *options) {
+		o.remoteWrites = policy
+	}
+}
+
+// WithCodec sets the Codec used to encode values for the backing store.
+// The default is JSONCodec.
+func WithCodec(codec Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// keyString renders the identity of an entry as a string, used for key
+// hashes.
+func keyString(valueType reflect.Type, key any) string {
+	return fmt.Sprintf("%v:%v", valueType, key)
+}
+
+// TieredSink is implemented by MetricsSinks of caches with a backing store
+// that want to tell apart the tiers serving lookups. Hit reports lookups
+// served from local memory and Load the getter calls reaching the origin;
+// RemoteHit reports the misses served by the backing store instead.
+type TieredSink interface {
+	MetricsSink
+	RemoteHit(valueType string)
+}
+
+// recordRemoteHit counts a local miss served by the backing store.
+func (s *store) recordRemoteHit(valueType reflect.Type) {
+	s.remoteHits.Add(1)
+	if sink, ok := s.cfg().metrics.(TieredSink); ok {
+		sink.RemoteHit(s.typeName(valueType))
+	}
+}
+
+// loadRemote looks key up in the backing store and decodes it into a V.
+// Store and decoding failures are reported as events and treated as misses.
+func loadRemote[V any](ctx context.Context, s *store, valueType reflect.Type, key any) (V, bool) {
+	var value V
+	data, found, err := s.remote.Get(ctx, s.remoteKey(valueType, key))
+	if err != nil {
+		s.emit(Event{Kind: EventStoreError, Type: valueType, Key: key, Err: err})
+		return value, false
+	}
+	if !found {
+		return value, false
+	}
+	if err := s.codecFor(valueType).Unmarshal(data, &value); err != nil {
+		s.emit(Event{Kind: EventStoreError, Type: valueType, Key: key, Err: fmt.Errorf("decoding stored value: %w", err)})
+		return value, false
+	}
+	return value, true
+}
+
+// storeRemote writes a freshly loaded value to the backing store with the
+// given time to live. Failures are reported as events; the value stays
+// cached locally.
+func storeRemote(s *store, valueType reflect.Type, key, value any, ttl time.Duration) {
+	data, err := s.codecFor(valueType).Marshal(value)
+	if err == nil {
+		err = s.remote.Set(context.Background(), s.remoteKey(valueType, key), data, ttl)
+	}
+	if err != nil {
+		s.emit(Event{Kind: EventStoreError, Type: valueType, Key: key, Err: err})
+	}
+}
+
+// SetThrough stores value for key in the backing store and in local memory
+// as one operation: if the backing store write fails, the local entry is
+// rolled back to its previous state and the error is returned. Concurrent
+// SetThrough calls for the same key are serialized.
+//
+// SetThrough returns ErrNoStore if the cache has no backing store.
+func (c *Cache[K, V]) SetThrough(key K, value V) error {
+	s := c.s
+	if s.remote == nil {
+		return ErrNoStore
+	}
+	if err := c.s.checkKey(key); err != nil {
+		return err
+	}
+	data, err := s.codecFor(c.valueType).Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cache: encoding value for key %v: %w", s.redactKey(s.typeName(c.valueType), key), err)
+	}
+
+	unlock := s.keyLocks.lock(entryKey{c.valueType, canonicalKey(key)})
+	defer unlock()
+
+	e := s.newEntry(c.valueType, value)
+	prev := s.swap(c.valueType, key, e)
+	if err := s.remote.Set(context.Background(), s.remoteKey(c.valueType, key), data, s.ttlFor(c.valueType)); err != nil {
+		// Roll back unless someone else already replaced our entry
+		s.restore(c.valueType, key, e, prev)
+		return fmt.Errorf("cache: writing key %v to store: %w", s.redactKey(s.typeName(c.valueType), key), err)
+	}
+	s.clearTombstone(c.valueType, key)
+	return nil
+}
@@ -0,0 +1,344 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// memoryStore is an in-memory Store used to test backing store integration
+type memoryStore struct {
+	mu      sync.Mutex
+	data    map[string][]byte
+	ttls    map[string]time.Duration
+	failSet error
+	sets    atomic.Int32
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{data: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (m *memoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.data[key]
+	return value, ok, nil
+}
+
+func (m *memoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.sets.Add(1)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failSet != nil {
+		return m.failSet
+	}
+	m.data[key] = value
+	m.ttls[key] = ttl
+	return nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memoryStore) setFailure(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failSet = err
+}
+
+type RemoteTestSuite struct {
+	suite.Suite
+	remote *memoryStore
+}
+
+func TestRemoteSuite(t *testing.T) {
+	suite.Run(t, new(RemoteTestSuite))
+}
+
+// SetupTest runs before each test
+func (s *RemoteTestSuite) SetupTest() {
+	s.remote = newMemoryStore()
+}
+
+// TestSetThroughWritesBothTiers verifies that SetThrough updates local memory and the store
+func (s *RemoteTestSuite) TestSetThroughWritesBothTiers() {
+	c := New[int, string](WithStore(s.remote))
+
+	s.NoError(c.SetThrough(1, "value"))
+
+	local, found := c.Peek(1)
+	s.True(found)
+	s.Equal("value", local)
+
+	data, found, err := s.remote.Get(context.Background(), keyString(c.valueType, 1))
+	s.NoError(err)
+	s.True(found)
+	s.JSONEq(`"value"`, string(data))
+}
+
+// TestSetThroughRollsBackOnStoreFailure verifies the local entry is restored when the store fails
+func (s *RemoteTestSuite) TestSetThroughRollsBackOnStoreFailure() {
+	c := New[int, string](WithStore(s.remote))
+	errDown := errors.New("store down")
+
+	// Previous value is restored
+	c.Set(1, "old")
+	s.remote.setFailure(errDown)
+	err := c.SetThrough(1, "new")
+	s.ErrorIs(err, errDown)
+	local, found := c.Peek(1)
+	s.True(found)
+	s.Equal("old", local, "Local entry should have been rolled back")
+
+	// Absent entry stays absent
+	err = c.SetThrough(2, "new")
+	s.ErrorIs(err, errDown)
+	_, found = c.Peek(2)
+	s.False(found, "Rolled back entry should not exist")
+}
+
+// TestSetThroughWithoutStore verifies ErrNoStore
+func (s *RemoteTestSuite) TestSetThroughWithoutStore() {
+	c := New[int, string]()
+	s.ErrorIs(c.SetThrough(1, "value"), ErrNoStore)
+	_, found := c.Peek(1)
+	s.False(found)
+}
+
+// TestMissIsServedFromStore verifies that misses consult the store before the loader
+func (s *RemoteTestSuite) TestMissIsServedFromStore() {
+	var loads atomic.Int32
+	loader := func(id int) (string, error) {
+		loads.Add(1)
+		return "from loader", nil
+	}
+
+	// A first instance populates the store through its loader
+	first := New[int, string](WithStore(s.remote), WithLoader(loader))
+	result, err := first.Get(1)
+	s.NoError(err)
+	s.Equal("from loader", result)
+	s.Equal(int32(1), loads.Load())
+
+	// A second instance sharing the store finds the value there
+	second := New[int, string](WithStore(s.remote), WithLoader(loader))
+	result, err = second.Get(1)
+	s.NoError(err)
+	s.Equal("from loader", result)
+	s.Equal(int32(1), loads.Load(), "Value should come from the backing store")
+}
+
+// TestStoreErrorsDoNotFailLoads verifies that store failures are reported as events
+func (s *RemoteTestSuite) TestStoreErrorsDoNotFailLoads() {
+	var events []Event
+	s.remote.setFailure(errors.New("store down"))
+	c := New[int, string](
+		WithStore(s.remote),
+		WithLoader(func(id int) (string, error) { return "value", nil }),
+		WithEventHandler(func(ev Event) { events = append(events, ev) }),
+	)
+
+	result, err := c.Get(1)
+	s.NoError(err)
+	s.Equal("value", result)
+	s.Require().Len(events, 1)
+	s.Equal(EventStoreError, events[0].Kind)
+}
+
+// tenantKey is a composite key rendered through encoding.TextMarshaler
+type tenantKey struct {
+	Tenant string
+	ID     int
+}
+
+func (k tenantKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%s/%d", k.Tenant, k.ID)), nil
+}
+
+// upperKeys is a KeyCodec upper-casing string keys
+type upperKeys struct{}
+
+func (upperKeys) EncodeKey(key any) string {
+	return strings.ToUpper(fmt.Sprint(key))
+}
+
+// TestKeyEncoding verifies how keys are rendered in backing store keys
+func (s *RemoteTestSuite) TestKeyEncoding() {
+	c := New[tenantKey, string](WithStore(s.remote))
+	s.NoError(c.SetThrough(tenantKey{"acme", 7}, "value"))
+	_, found, _ := s.remote.Get(context.Background(), "string:acme/7")
+	s.True(found, "TextMarshaler keys must be rendered with MarshalText")
+
+	custom := New[string, string](WithStore(s.remote), WithKeyCodec(upperKeys{}))
+	s.NoError(custom.SetThrough("user", "value"))
+	_, found, _ = s.remote.Get(context.Background(), "string:USER")
+	s.True(found, "WithKeyCodec must replace the default encoding")
+
+	s.Equal("AQI", DefaultKeyCodec{}.EncodeKey(binaryKey{1, 2}))
+	s.Equal("42", DefaultKeyCodec{}.EncodeKey(42))
+}
+
+// binaryKey is a key rendered through encoding.BinaryMarshaler
+type binaryKey [2]byte
+
+func (k binaryKey) MarshalBinary() ([]byte, error) {
+	return k[:], nil
+}
+
+// gatedStore is a memoryStore whose Get counts its calls and waits for
+// release.
+type gatedStore struct {
+	*memoryStore
+	gets    atomic.Int32
+	release chan struct{}
+}
+
+func (g *gatedStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	g.gets.Add(1)
+	<-g.release
+	return g.memoryStore.Get(ctx, key)
+}
+
+// tieredMetrics is a recordingMetrics also recording backing store hits.
+type tieredMetrics struct {
+	*recordingMetrics
+}
+
+func (m tieredMetrics) RemoteHit(valueType string) { m.add("remoteHit", valueType) }
+
+// TestConcurrentMissesShareStoreRead verifies that concurrent misses of a
+// key make one backing store request, and that each tier's lookups are
+// counted apart
+func (s *RemoteTestSuite) TestConcurrentMissesShareStoreRead() {
+	s.Require().NoError(New[int, string](WithStore(s.remote)).SetThrough(1, "stored"))
+	store := &gatedStore{memoryStore: s.remote, release: make(chan struct{})}
+	metrics := tieredMetrics{newRecordingMetrics()}
+	c := New[int, string](WithStore(store), WithMetrics(metrics), WithLoader(func(id int) (string, error) {
+		return "loaded", nil
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := c.Get(1)
+			s.NoError(err)
+			s.Equal("stored", value)
+		}()
+	}
+	s.Eventually(func() bool { return store.gets.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(store.release)
+	wg.Wait()
+	s.Equal(int32(1), store.gets.Load(), "Concurrent misses share the store read")
+
+	_, err := c.Get(1)
+	s.NoError(err)
+	_, err = c.Get(2)
+	s.NoError(err)
+	st := c.Stats()
+	s.Equal(uint64(1), st.RemoteHits)
+	s.Equal(uint64(1), st.OriginLoads)
+	s.Equal(1, metrics.snapshot()["remoteHit:string"])
+	s.Equal(1, metrics.snapshot()["load:string"])
+	s.Contains(st.String(), "remote hits 1, origin loads 1")
+}
+
+// TestRemoteWritePolicies verifies how each policy populates the backing
+// store with loaded values
+func (s *RemoteTestSuite) TestRemoteWritePolicies() {
+	loader := WithLoader(func(id int) (string, error) { return "loaded", nil })
+	stored := func(id int) bool {
+		_, found, _ := s.remote.Get(context.Background(), "string:"+strconv.Itoa(id))
+		return found
+	}
+
+	through := New[int, string](WithStore(s.remote), loader)
+	_, err := through.Get(1)
+	s.NoError(err)
+	s.True(stored(1), "Write-through stores before Get returns")
+
+	behind := New[int, string](WithStore(s.remote), loader,
+		WithRemoteWrites(RemoteWriteBehind), WithWriteBehind(WriteBehindConfig{FlushInterval: time.Millisecond}))
+	_, err = behind.Get(2)
+	s.NoError(err)
+	s.Eventually(func() bool { return stored(2) }, time.Second, time.Millisecond, "Write-behind stores eventually")
+	s.NoError(behind.Close())
+
+	s.Require().NoError(s.remote.Set(context.Background(), "string:3", []byte(`"remote"`), 0))
+	local := New[int, string](WithStore(s.remote), loader, WithRemoteWrites(RemoteLocalOnly))
+	value, err := local.Get(3)
+	s.NoError(err)
+	s.Equal("loaded", value, "Local-only caches do not read the store")
+	_, err = local.Get(4)
+	s.NoError(err)
+	s.False(stored(4), "Local-only caches do not write the store")
+	s.ErrorIs(local.SetThrough(5, "value"), ErrNoStore)
+	s.Equal("local-only", RemoteLocalOnly.String())
+}
+
+// recordingBroadcaster is a Broadcaster keeping the messages it is given.
+type recordingBroadcaster struct {
+	mu       sync.Mutex
+	messages [][]byte
+}
+
+func (b *recordingBroadcaster) Broadcast(ctx context.Context, message []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, message)
+	return nil
+}
+
+// TestDeleteThrough verifies that an invalidation reaches every tier and
+// that the delayed second delete drops a value a racing read put back
+func (s *RemoteTestSuite) TestDeleteThrough() {
+	broadcaster := &recordingBroadcaster{}
+	newCache := func() *Cache[int, string] {
+		return New[int, string](WithStore(s.remote), WithInvalidation(InvalidationConfig{
+			Broadcaster:   broadcaster,
+			RedeleteDelay: 10 * time.Millisecond,
+		}))
+	}
+	first, second := newCache(), newCache()
+	others := New[int, int]()
+	s.Require().NoError(first.SetThrough(1, "value"))
+	s.Require().NoError(second.Set(1, "value"))
+	s.Require().NoError(others.Set(1, 1))
+
+	s.NoError(first.DeleteThrough(1))
+	_, found, _ := s.remote.Get(context.Background(), "string:1")
+	s.False(found)
+	s.Require().Len(broadcaster.messages, 1)
+
+	s.NoError(second.ApplyInvalidation(broadcaster.messages[0]))
+	_, ok := second.Peek(1)
+	s.False(ok)
+	s.NoError(others.ApplyInvalidation(broadcaster.messages[0]))
+	_, ok = others.Peek(1)
+	s.True(ok, "Invalidations of other value types are ignored")
+	s.Error(others.ApplyInvalidation([]byte("{")))
+
+	// A read that fetched the old value before the store delete
+	s.Require().NoError(first.Set(1, "stale"))
+	s.Eventually(func() bool {
+		_, ok := first.Peek(1)
+		return !ok
+	}, time.Second, time.Millisecond)
+
+	s.ErrorIs(New[int, string]().DeleteThrough(1), ErrNoStore)
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"reflect"
+	"sync"
+)
+
+// RemovalReason says why an entry left the cache.
+type RemovalReason int
+
+const (
+	// RemovalExpired is the removal of an entry past its expiration,
+	// including one overwritten when it is loaded again.
+	RemovalExpired RemovalReason = iota + 1
+	// RemovalCapacity is the eviction of an entry to respect an entry,
+	// byte, spill or memory limit.
+	RemovalCapacity
+	// RemovalReplaced is the removal of a live entry overwritten by a
+	// newer value for its key.
+	RemovalReplaced
+	// RemovalDeleted is the removal of an entry by Delete, Clear,
+	// InvalidateTags, a transaction or a rolled back SetThrough.
+	RemovalDeleted
+	// RemovalCorrupted is the removal of an entry holding a value of the
+	// wrong type.
+	RemovalCorrupted
+	// RemovalShutdown is the removal of an entry by Shutdown.
+	RemovalShutdown
+)
+
+// removalReasons is the number of RemovalReason values, plus one.
+const removalReasons = int(RemovalShutdown) + 1
+
+// String returns a human-readable name for the reason.
+func (r RemovalReason) String() string {
+	switch r {
+	case RemovalExpired:
+		return "expired"
+	case RemovalCapacity:
+		return "capacity"
+	case RemovalReplaced:
+		return "replaced"
+	case RemovalDeleted:
+		return "deleted"
+	case RemovalCorrupted:
+		return "corrupted"
+	case RemovalShutdown:
+		return "shutdown"
+	default:
+		return "unknown"
+	}
+}
+
+// Removal describes an entry that left the cache.
+type Removal struct {
+	// Type is the value type of the entry.
+	Type reflect.Type
+	Key  any
+	// Value is the value the entry held. It is nil for values spilled to
+	// disk and for weak values already reclaimed.
+	Value  any
+	Reason RemovalReason
+}
+
+// WithOnEvict registers fn to be told about every entry leaving the cache
+// and why. Calls are made in the order of the removals, one at a time, from
+// a goroutine of the cache, so fn may use the cache; a slow fn delays the
+// next calls but not the cache.
+func WithOnEvict(fn func(Removal)) Option {
+	return func(o *options) {
+		o.onEvict = fn
+	}
+}
+
+// removedLocked counts the removal of e from key in the valueType partition
+// and queues it for the WithOnEvict handler. Must be called with s.mu held
+// for writing.
+func (s *store) removedLocked(valueType reflect.Type, key any, e *entry, reason RemovalReason) {
+	s.removals[reason].Add(1)
+	fn := s.cfg().onEvict
+	if fn == nil {
+		return
+	}
+	r := Removal{Type: valueType, Key: plainKey(key), Reason: reason}
+	if e.spill == nil {
+		r.Value, _ = e.get()
+	}
+	s.removalQueue.push(fn, r)
+}
+
+// removalCounts returns the number of removals per reason, or nil if there
+// were none.
+func (s *store) removalCounts() map[RemovalReason]uint64 {
+	var counts map[RemovalReason]uint64
+	for reason := RemovalExpired; int(reason) < removalReasons; reason++ {
+		if n := s.removals[reason].Load(); n > 0 {
+			if counts == nil {
+				counts = make(map[RemovalReason]uint64)
+			}
+			counts[reason] = n
+		}
+	}
+	return counts
+}
+
+// removalQueue delivers removals to the WithOnEvict handler outside of the
+// store lock. A goroutine drains it while it is not empty.
+type removalQueue struct {
+	mu       sync.Mutex
+	pending  []pendingRemoval
+	draining bool
+}
+
+type pendingRemoval struct {
+	fn func(Removal)
+	r  Removal
+}
+
+func (q *removalQueue) push(fn func(Removal), r Removal) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, pendingRemoval{fn, r})
+	if !q.draining {
+		q.draining = true
+		go q.drain()
+	}
+}
+
+func (q *removalQueue) drain() {
+	for {
+		q.mu.Lock()
+		batch := q.pending
+		q.pending = nil
+		if len(batch) == 0 {
+			q.draining = false
+			q.mu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+
+		for _, p := range batch {
+			p.fn(p.r)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RemovalTestSuite struct {
+	suite.Suite
+}
+
+func TestRemovalSuite(t *testing.T) {
+	suite.Run(t, new(RemovalTestSuite))
+}
+
+// TestRemovalReasons verifies that removals are counted and reported to the
+// handler with their reason
+func (s *RemovalTestSuite) TestRemovalReasons() {
+	var mu sync.Mutex
+	var removals []Removal
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := New[string, int](WithClock(clock), WithMaxEntries(2), WithOnEvict(func(r Removal) {
+		mu.Lock()
+		defer mu.Unlock()
+		removals = append(removals, r)
+	}))
+
+	s.Require().NoError(c.Set("a", 1))
+	s.Require().NoError(c.Set("a", 2))
+	s.Require().NoError(c.Set("b", 3))
+	s.Require().NoError(c.Set("c", 4))
+	c.Delete("b")
+	s.Require().NoError(c.Set("d", 5, WithTTL(time.Second)))
+	clock.Advance(time.Second)
+	s.Equal(1, c.s.removeExpired())
+
+	want := []Removal{
+		{Type: c.valueType, Key: "a", Value: 1, Reason: RemovalReplaced},
+		{Type: c.valueType, Key: "a", Value: 2, Reason: RemovalCapacity},
+		{Type: c.valueType, Key: "b", Value: 3, Reason: RemovalDeleted},
+		{Type: c.valueType, Key: "d", Value: 5, Reason: RemovalExpired},
+	}
+	s.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(removals) == len(want)
+	}, time.Second, time.Millisecond)
+	s.Equal(want, removals, "Removals are delivered in order")
+	s.Equal(map[RemovalReason]uint64{
+		RemovalReplaced: 1,
+		RemovalCapacity: 1,
+		RemovalDeleted:  1,
+		RemovalExpired:  1,
+	}, c.Stats().Removals)
+}
+
+// TestReloadedEntriesExpire verifies that an expired entry replaced by a
+// reload counts as expired, not replaced
+func (s *RemovalTestSuite) TestReloadedEntriesExpire() {
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := New[string, int](WithClock(clock), WithTTL(time.Second), WithLoader(func(string) (int, error) {
+		return 1, nil
+	}))
+
+	_, err := c.Get("a")
+	s.Require().NoError(err)
+	clock.Advance(time.Second)
+	_, err = c.Get("a")
+	s.Require().NoError(err)
+	s.Equal(map[RemovalReason]uint64{RemovalExpired: 1}, c.Stats().Removals)
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// statsHotKeys is how many hot keys per type Stats reports.
+const statsHotKeys = 5
+
+// String returns a compact multi-line report of the statistics: totals
+// first, then one line per value type with its size, hit ratio, load
+// latencies and hottest keys. It is meant for humans, for instance dumped
+// on SIGUSR1 or served by an admin endpoint while debugging an incident;
+// its format may change.
+func (st Statistics) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "entries %d (%d nil), %s, hit ratio %s (%d hits, %d misses)\n",
+		st.Entries, st.NilEntries, formatBytes(st.Bytes), hitRatio(st.Hits, st.Misses), st.Hits, st.Misses)
+	fmt.Fprintf(&b, "evictions %d, rejections %d, in flight %d, coalesced %d\n",
+		st.Evictions, st.Rejections, st.InFlight, st.Coalesced)
+	if st.RemoteHits > 0 {
+		fmt.Fprintf(&b, "remote hits %d, origin loads %d\n", st.RemoteHits, st.OriginLoads)
+	}
+	if len(st.Removals) > 0 {
+		b.WriteString("removed")
+		for reason := RemovalExpired; int(reason) < removalReasons; reason++ {
+			if n := st.Removals[reason]; n > 0 {
+				fmt.Fprintf(&b, " %v:%d", reason, n)
+			}
+		}
+		b.WriteByte('\n')
+	}
+
+	b.WriteString("ttl")
+	for i, bound := range ttlBounds {
+		fmt.Fprintf(&b, " <=%v:%d", bound, st.TTL.Expiring[i])
+	}
+	fmt.Fprintf(&b, " later:%d never:%d\n", st.TTL.Later, st.TTL.NoExpiry)
+
+	names := make([]string, 0, len(st.Types))
+	for name := range st.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ts := st.Types[name]
+		fmt.Fprintf(&b, "%s: entries %d, %s, hit ratio %s (%d hits, %d misses)",
+			name, ts.Entries, formatBytes(ts.Bytes), hitRatio(ts.Hits, ts.Misses), ts.Hits, ts.Misses)
+		if ts.Loads.Count > 0 {
+			fmt.Fprintf(&b, ", loads %d p50 %v p90 %v p99 %v", ts.Loads.Count, ts.Loads.P50, ts.Loads.P90, ts.Loads.P99)
+		}
+		if len(ts.HotKeys) > 0 {
+			fmt.Fprintf(&b, ", hot [%s]", strings.Join(ts.HotKeys, " "))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// hitRatio formats the share of hits among lookups as a percentage.
+func hitRatio(hits, misses uint64) string {
+	if hits+misses == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(hits)/float64(hits+misses))
+}
+
+// formatBytes formats n bytes with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
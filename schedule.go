@@ -0,0 +1,208 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// refreshSchedule tells when a scheduled refresh runs next.
+type refreshSchedule interface {
+	next(after time.Time) time.Time
+}
+
+// every is the schedule of refreshes a fixed interval apart.
+type every time.Duration
+
+func (d every) next(after time.Time) time.Time {
+	return after.Add(time.Duration(d))
+}
+
+// ScheduledRefresh keeps an entry fresh on a schedule until it is stopped.
+type ScheduledRefresh struct {
+	s    *store
+	stop chan struct{}
+	once sync.Once
+	done chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// ScheduleRefresh loads key into the package-level cache through getter
+// right away and again every interval, whether the entry is read or not,
+// until the returned ScheduledRefresh is stopped or the cache is shut
+// down. It suits configuration and feature flags, which must be fresh when
+// read however rarely they are. Refreshes run in the background and
+// replace the entry only once the getter succeeds, so a failing origin
+// leaves the last value in place until it expires: give entries a TTL of a
+// few intervals, or none.
+//
+// The options of Get that apply to stored entries are honored: WithTTL,
+// WithExpireAt, WithTags and WithPriority. It returns ErrNilGetter for a
+// nil getter and an error if interval is not positive or the key is not
+// usable.
+//
+//	flags, err := cache.ScheduleRefresh("flags", 30*time.Second, loadFlags, cache.WithTTL(5*time.Minute))
+//	...
+//	defer flags.Stop()
+func ScheduleRefresh[K comparable, V any](key K, interval time.Duration, getter func(K) (V, error), opts ...Option) (*ScheduledRefresh, error) {
+	if interval <= 0 {
+		return nil, errors.New("cache: refresh interval must be positive")
+	}
+	return scheduleRefresh(globalStore(), key, every(interval), unweighted(getter), opts)
+}
+
+// ScheduleRefresh is the package-level ScheduleRefresh for the entries of
+// a cache instance. A nil getter stands for the loader of a read-through
+// cache.
+func (c *Cache[K, V]) ScheduleRefresh(key K, interval time.Duration, getter func(K) (V, error), opts ...Option) (*ScheduledRefresh, error) {
+	if interval <= 0 {
+		return nil, errors.New("cache: refresh interval must be positive")
+	}
+	return scheduleRefresh(c.s, key, every(interval), c.scheduledGetter(getter), opts)
+}
+
+// ScheduleRefreshCron is ScheduleRefresh with refreshes at the times
+// matched by a cron expression instead of on an interval, for entries tied
+// to business events such as exchange rates published at 16:05 on
+// weekdays. The expression has the standard five fields, minute, hour, day
+// of month, month and day of week, each a "*", a value, a range "a-b" or a
+// comma-separated list of those, optionally stepped with "/n". Months and
+// days of week may be named ("jan", "mon") and Sunday is 0 or 7. When both
+// day fields are restricted, either may match. "@hourly", "@daily",
+// "@weekly", "@monthly" and "@yearly" stand for the usual expressions.
+// Times are local unless the expression starts with "CRON_TZ=" and a time
+// zone name. The key is still loaded right away, so it is cached before
+// the first matching time.
+//
+// It returns an error if the expression is invalid.
+//
+//	rates, err := cache.ScheduleRefreshCron("EUR", "CRON_TZ=Europe/Berlin 5 16 * * mon-fri", loadRate)
+func ScheduleRefreshCron[K comparable, V any](key K, spec string, getter func(K) (V, error), opts ...Option) (*ScheduledRefresh, error) {
+	schedule, err := parseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	return scheduleRefresh(globalStore(), key, schedule, unweighted(getter), opts)
+}
+
+// ScheduleRefreshCron is the package-level ScheduleRefreshCron for the
+// entries of a cache instance. A nil getter stands for the loader of a
+// read-through cache.
+func (c *Cache[K, V]) ScheduleRefreshCron(key K, spec string, getter func(K) (V, error), opts ...Option) (*ScheduledRefresh, error) {
+	schedule, err := parseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	return scheduleRefresh(c.s, key, schedule, c.scheduledGetter(getter), opts)
+}
+
+// scheduledGetter returns getter, or the cache's loader if getter is nil.
+func (c *Cache[K, V]) scheduledGetter(getter func(K) (V, error)) weightedGetter[K, V] {
+	if getter == nil {
+		return c.loader
+	}
+	return unweighted(getter)
+}
+
+func scheduleRefresh[K comparable, V any](s *store, key K, schedule refreshSchedule, getterFunc weightedGetter[K, V], opts []Option) (*ScheduledRefresh, error) {
+	if getterFunc == nil {
+		return nil, ErrNilGetter
+	}
+	if err := s.checkKey(key); err != nil {
+		return nil, err
+	}
+	call := options{refresh: true}
+	for _, opt := range opts {
+		opt(&call)
+	}
+	r := &ScheduledRefresh{
+		s:    s,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	s.workersMu.Lock()
+	if s.schedules == nil {
+		s.schedules = make(map[*ScheduledRefresh]struct{})
+	}
+	s.schedules[r] = struct{}{}
+	s.workersMu.Unlock()
+
+	go r.run(schedule, func() error {
+		_, err := load(s, key, getterFunc, call)
+		return err
+	})
+	return r, nil
+}
+
+func (r *ScheduledRefresh) run(schedule refreshSchedule, refresh func() error) {
+	defer close(r.done)
+	for {
+		err := refresh()
+		if errors.Is(err, errRefreshSkipped) {
+			// Another replica refreshed the entry
+			err = nil
+		}
+		r.mu.Lock()
+		r.err = err
+		r.mu.Unlock()
+
+		now := r.s.now()
+		next := schedule.next(now)
+		if next.IsZero() {
+			// The schedule never comes around again
+			<-r.stop
+			return
+		}
+		wake, release := r.s.after(next.Sub(now))
+		select {
+		case <-wake:
+		case <-r.stop:
+			release()
+			return
+		}
+	}
+}
+
+// Err returns the error of the latest refresh, or nil if it succeeded.
+func (r *ScheduledRefresh) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Stop ends the schedule, waiting for a refresh in progress to finish. The
+// entry stays cached until it expires. It is safe to call more than once.
+func (r *ScheduledRefresh) Stop() {
+	r.s.workersMu.Lock()
+	delete(r.s.schedules, r)
+	r.s.workersMu.Unlock()
+	_ = r.shutdown(context.Background())
+}
+
+// shutdown ends the schedule and waits for it to exit or for ctx to end.
+func (r *ScheduledRefresh) shutdown(ctx context.Context) error {
+	r.once.Do(func() { close(r.stop) })
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stopSchedules ends every scheduled refresh of the store.
+func (s *store) stopSchedules(ctx context.Context) error {
+	s.workersMu.Lock()
+	schedules := s.schedules
+	s.schedules = nil
+	s.workersMu.Unlock()
+	for r := range schedules {
+		if err := r.shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
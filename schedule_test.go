@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ScheduleTestSuite struct {
+	suite.Suite
+	clock   *FakeClock
+	version atomic.Int32
+}
+
+func TestScheduleSuite(t *testing.T) {
+	suite.Run(t, new(ScheduleTestSuite))
+}
+
+func (s *ScheduleTestSuite) SetupTest() {
+	Reset()
+	s.clock = NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	SetDefaults(WithClock(s.clock))
+	s.version.Store(0)
+}
+
+func (s *ScheduleTestSuite) TearDownTest() {
+	Reset()
+}
+
+func (s *ScheduleTestSuite) loadFlags(string) (int32, error) {
+	return s.version.Add(1), nil
+}
+
+// waiting reports whether a scheduled refresh waits on the clock.
+func (s *ScheduleTestSuite) waiting() bool {
+	s.clock.mu.Lock()
+	defer s.clock.mu.Unlock()
+	return len(s.clock.waiters) > 0
+}
+
+// tick waits for the next scheduled refresh to wait on the clock and
+// advances the clock by d.
+func (s *ScheduleTestSuite) tick(d time.Duration) {
+	s.Require().Eventually(s.waiting, time.Second, time.Millisecond)
+	s.clock.Advance(d)
+}
+
+// TestEntryIsKeptFresh verifies that scheduled refreshes replace the entry
+// without reads and end with Stop
+func (s *ScheduleTestSuite) TestEntryIsKeptFresh() {
+	r, err := ScheduleRefresh("flags", time.Minute, s.loadFlags)
+	s.Require().NoError(err)
+	s.tick(59 * time.Second)
+	s.Equal(int32(1), s.version.Load(), "The entry is loaded at once")
+	s.clock.Advance(time.Second)
+	s.tick(time.Minute)
+	s.Eventually(func() bool {
+		value, ok := Peek[string, int32]("flags")
+		return ok && value == 3
+	}, time.Second, time.Millisecond)
+	s.NoError(r.Err())
+	s.Zero(Stats().Misses, "Refreshes are not lookups")
+
+	s.Require().Eventually(s.waiting, time.Second, time.Millisecond)
+	r.Stop()
+	r.Stop()
+	<-r.done
+	s.clock.Advance(time.Hour)
+	s.Equal(int32(3), s.version.Load(), "No refresh runs after Stop")
+	value, ok := Peek[string, int32]("flags")
+	s.True(ok, "The entry stays cached")
+	s.Equal(int32(3), value)
+}
+
+// TestFailuresKeepTheLastValue verifies that a failing refresh leaves the
+// entry in place and is reported by Err
+func (s *ScheduleTestSuite) TestFailuresKeepTheLastValue() {
+	failing := errors.New("origin down")
+	var fail atomic.Bool
+	c := New[string, int32](WithClock(s.clock), WithLoader(func(key string) (int32, error) {
+		if fail.Load() {
+			return 0, failing
+		}
+		return s.loadFlags(key)
+	}))
+	r, err := c.ScheduleRefresh("flags", time.Minute, nil, WithTTL(time.Hour))
+	s.Require().NoError(err)
+	s.Eventually(func() bool { _, ok := c.Peek("flags"); return ok }, time.Second, time.Millisecond)
+
+	fail.Store(true)
+	s.tick(time.Minute)
+	s.Eventually(func() bool { return errors.Is(r.Err(), failing) }, time.Second, time.Millisecond)
+	_, ok := c.Peek("flags")
+	s.True(ok)
+
+	s.Require().NoError(c.Shutdown(context.Background()))
+	select {
+	case <-r.done:
+	default:
+		s.Fail("Shutdown stops scheduled refreshes")
+	}
+}
+
+// TestInvalidSchedules verifies the errors of ScheduleRefresh
+func (s *ScheduleTestSuite) TestInvalidSchedules() {
+	_, err := ScheduleRefresh[string, int]("key", time.Second, nil)
+	s.ErrorIs(err, ErrNilGetter)
+	_, err = ScheduleRefresh("key", 0, s.loadFlags)
+	s.Error(err)
+	_, err = New[string, int]().ScheduleRefresh("key", time.Second, nil)
+	s.ErrorIs(err, ErrNilGetter, "A cache without loader needs a getter")
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// scopedMu is held while a Scoped store is installed.
+var scopedMu sync.Mutex
+
+// Scoped gives the calling test a fresh package-level cache: Get, Peek,
+// SetDefaults, Configure and the other package-level functions use an
+// empty store with default settings until the test finishes, when the
+// previous store is put back.
+//
+// Tests calling Scoped run one after the other, even when marked with
+// t.Parallel, so they never observe each other's entries or settings.
+// Call t.Parallel before Scoped, call Scoped at most once per test, and
+// not from a subtest of a test that called it.
+//
+//	func TestUsers(t *testing.T) {
+//		t.Parallel()
+//		cache.Scoped(t)
+//		...
+//	}
+func Scoped(t interface{ Cleanup(func()) }) {
+	scopedMu.Lock()
+	prev := global.Swap(newStore())
+	t.Cleanup(func() {
+		scoped := global.Swap(prev)
+		_ = scoped.stopJanitor(context.Background(), true)
+		_ = scoped.stopSchedules(context.Background())
+		_ = scoped.stopMemoryMonitor(context.Background())
+		scopedMu.Unlock()
+	})
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ScopedTestSuite struct {
+	suite.Suite
+}
+
+func TestScopedSuite(t *testing.T) {
+	suite.Run(t, new(ScopedTestSuite))
+}
+
+// TestScopedRestoresStore verifies that the previous store comes back
+func (s *ScopedTestSuite) TestScopedRestoresStore() {
+	outer := globalStore()
+
+	s.Run("scoped", func() {
+		Scoped(s.T())
+		s.NotSame(outer, globalStore())
+
+		SetDefaults(WithMaxEntries(1))
+		_, err := Get("scoped", func(key string) (int, error) { return 1, nil })
+		s.NoError(err)
+	})
+
+	s.Same(outer, globalStore())
+	_, found := Peek[string, int]("scoped")
+	s.False(found, "Entries of a scoped store should not leak")
+	s.Zero(globalStore().cfg().maxEntries, "Settings of a scoped store should not leak")
+}
+
+// TestScopedStopsSchedules verifies that the scheduled refreshes of a
+// scoped store end with the test
+func (s *ScopedTestSuite) TestScopedStopsSchedules() {
+	var refresh *ScheduledRefresh
+	s.Run("scoped", func() {
+		Scoped(s.T())
+		var err error
+		refresh, err = ScheduleRefresh("flags", time.Hour, func(key string) (int, error) { return 1, nil })
+		s.Require().NoError(err)
+	})
+
+	select {
+	case <-refresh.done:
+	default:
+		s.Fail("The scheduled refresh should have stopped")
+	}
+}
+
+// TestScopedParallel verifies that parallel scoped tests are isolated
+func (s *ScopedTestSuite) TestScopedParallel() {
+	t := s.T()
+	for i := 0; i < 4; i++ {
+		i := i
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+			Scoped(t)
+
+			_, err := Get("key", func(key string) (int, error) { return i, nil })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value, _ := Peek[string, int]("key"); value != i {
+				t.Errorf("got %d from another test, want %d", value, i)
+			}
+			if misses := Stats().Misses; misses != 1 {
+				t.Errorf("misses = %d, want 1", misses)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package cache
+
+import "time"
+
+// settings holds the tunable configuration of a store. A published
+// settings value is never modified; updates publish a new copy so the hot
+// path can read it without locking.
+type settings struct {
+	ttl        time.Duration
+	maxEntries int
+	// maxEntriesPerType is the entry limit of types without their own
+	maxEntriesPerType int
+	maxBytes          int64
+	sizeOf            func(any) int64
+	skipNil           bool
+	onEvent           func(Event)
+	metrics           MetricsSink
+	codec             Codec
+	keyCodec          KeyCodec
+	nanKeys           NaNKeyPolicy
+	onEvict           func(Removal)
+	zeroValueTTL      time.Duration
+	bytesMode         BytesMode
+	typeNamer         TypeNamer
+	corruption        CorruptionPolicy
+	strict            bool
+	shadow            *ShadowConfig
+	faults            *FaultConfig
+	clock             Clock
+
+	refreshAhead float64
+	refreshLock  Locker
+	admission    AdmissionPolicy
+	rateLimit    *RateLimit
+	priority     Priority
+	redactor     Redactor
+
+	// expireSamples is how many entries writes inspect for expiry
+	expireSamples int
+	// closeValues enables closing removed io.Closer values after
+	// closeGrace
+	closeValues bool
+	closeGrace  time.Duration
+	// hotFraction is the share of entries in the hot segment of an SLRU
+	hotFraction float64
+	// coalesceWindow is how long getter results are shared with later
+	// loads of the same key
+	coalesceWindow time.Duration
+	// minRefreshInterval is how long after being stored entries ignore
+	// forced refreshes
+	minRefreshInterval time.Duration
+	// weakValues holds pointer values weakly
+	weakValues bool
+	// interning stores one copy of equal string keys and of string
+	// values of at most internValueLen bytes
+	interning      bool
+	internValueLen int
+
+	// userSizeOf is the estimator set with WithSizeEstimator, if any
+	userSizeOf func(any) int64
+}
+
+// newSettings derives store settings from options, filling in defaults.
+func newSettings(o options) *settings {
+	st := &settings{
+		ttl:        o.ttl,
+		maxEntries: o.maxEntries,
+
+		maxEntriesPerType: o.maxEntriesPerType,
+		maxBytes:          o.maxBytes,
+		sizeOf:            o.sizeOf,
+		skipNil:           o.skipNil,
+		onEvent:           o.onEvent,
+		metrics:           o.metrics,
+		codec:             o.codec,
+		keyCodec:          o.keyCodec,
+		nanKeys:           o.nanKeys,
+		onEvict:           o.onEvict,
+		zeroValueTTL:      o.zeroValueTTL,
+		bytesMode:         o.bytesMode,
+		typeNamer:         o.typeNamer,
+		corruption:        o.corruption,
+		strict:            o.strict,
+		shadow:            o.shadow,
+		faults:            o.faults,
+		clock:             o.clock,
+
+		refreshAhead: o.refreshAhead,
+		refreshLock:  o.refreshLock,
+		admission:    o.admission,
+		rateLimit:    o.rateLimit,
+		priority:     o.priority,
+		redactor:     o.redactor,
+
+		expireSamples: o.expireSamples,
+		closeValues:   o.closeValues,
+		closeGrace:    o.closeGrace,
+		weakValues:    o.weakValues,
+		hotFraction:   o.hotFraction,
+
+		coalesceWindow:     o.coalesceWindow,
+		minRefreshInterval: o.minRefreshInterval,
+
+		interning:      o.interning,
+		internValueLen: o.internValueLen,
+
+		userSizeOf: o.sizeOf,
+	}
+	if st.sizeOf == nil && st.maxBytes > 0 {
+		st.sizeOf = estimateSize
+	}
+	if st.metrics == nil {
+		st.metrics = noopMetrics{}
+	}
+	if st.codec == nil {
+		st.codec = JSONCodec{}
+	}
+	if st.keyCodec == nil {
+		st.keyCodec = DefaultKeyCodec{}
+	}
+	if st.clock == nil {
+		st.clock = RealClock{}
+	}
+	return st
+}
+
+// options returns the options the settings were derived from.
+func (st *settings) options() options {
+	o := options{
+		ttl:        st.ttl,
+		ttlSet:     st.ttl != 0,
+		maxEntries: st.maxEntries,
+
+		maxEntriesPerType: st.maxEntriesPerType,
+		maxBytes:          st.maxBytes,
+		sizeOf:            st.userSizeOf,
+		skipNil:           st.skipNil,
+		onEvent:           st.onEvent,
+		codec:             st.codec,
+		keyCodec:          st.keyCodec,
+		nanKeys:           st.nanKeys,
+		onEvict:           st.onEvict,
+		zeroValueTTL:      st.zeroValueTTL,
+		bytesMode:         st.bytesMode,
+		typeNamer:         st.typeNamer,
+		corruption:        st.corruption,
+		strict:            st.strict,
+		shadow:            st.shadow,
+		faults:            st.faults,
+		clock:             st.clock,
+
+		refreshAhead: st.refreshAhead,
+		refreshLock:  st.refreshLock,
+		admission:    st.admission,
+		rateLimit:    st.rateLimit,
+		priority:     st.priority,
+		redactor:     st.redactor,
+
+		expireSamples: st.expireSamples,
+		closeValues:   st.closeValues,
+		closeGrace:    st.closeGrace,
+		weakValues:    st.weakValues,
+		hotFraction:   st.hotFraction,
+
+		coalesceWindow:     st.coalesceWindow,
+		minRefreshInterval: st.minRefreshInterval,
+
+		interning:      st.interning,
+		internValueLen: st.internValueLen,
+	}
+	if _, noop := st.metrics.(noopMetrics); !noop {
+		o.metrics = st.metrics
+	}
+	return o
+}
+
+// cfg returns the current settings of the store.
+func (s *store) cfg() *settings {
+	return s.settings.Load()
+}
+
+// updateSettings applies opts on top of the current settings.
+func (s *store) updateSettings(opts ...Option) {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+	o := s.cfg().options()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	s.settings.Store(newSettings(o))
+}
+
+// SetDefaults applies opts to the package-level cache used by Get, on top
+// of the defaults set so far. It honors the options tuning how entries are
+// stored, expired, evicted, refreshed, loaded and observed. Options giving
+// a Cache its own loader, parent, backend, backing store or spillover, and
+// those only New acts on, such as WithInitialCapacity, WithWarmup and
+// WithPersistentFrequencies, are ignored. Per-type settings registered
+// with Configure take precedence. Lowered limits are enforced immediately.
+//
+//	cache.SetDefaults(
+//		cache.WithTTL(10*time.Minute),
+//		cache.WithMaxEntries(100_000),
+//		cache.WithMetrics(sink),
+//	)
+func SetDefaults(opts ...Option) {
+	s := globalStore()
+	sized := s.cfg().sizeOf != nil
+	s.updateSettings(opts...)
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.janitorInterval > 0 {
+		s.startJanitor(o.janitorInterval)
+	}
+	if o.memoryPressure != nil {
+		s.startMemoryMonitor(*o.memoryPressure, readHeap)
+	}
+	if o.absentFilter != nil {
+		s.absent.Store(newAbsentFilter(*o.absentFilter, s.now()))
+	}
+	if o.auditSize > 0 {
+		s.auditLog.Store(newAuditLog(o.auditSize))
+	}
+	if o.backpressure != nil {
+		s.pressure.Store(newPressureMonitor(*o.backpressure, s.now()))
+	}
+	if o.adaptive != nil {
+		s.tuner.Store(newSegmentTuner(*o.adaptive, o.hotFraction))
+	}
+	if o.trackFrequency {
+		s.trackFrequency(s.cfg().maxEntries)
+	}
+
+	s.lock()
+	defer s.mu.Unlock()
+	if !sized && s.cfg().sizeOf != nil {
+		// Entries stored so far were not measured
+		s.measureLocked()
+	}
+	s.evictLocked(entryKey{})
+	for valueType := range s.data {
+		s.evictLocked(entryKey{valueType: valueType})
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// ShadowConfig configures shadow reads, which check cached values against
+// the origin.
+type ShadowConfig struct {
+	// SampleRate is the fraction of hits, between 0 and 1, that also call
+	// the getter to compare its result with the cached value.
+	SampleRate float64
+	// OnDivergence, if set, is called for every compared hit whose cached
+	// value differs from the origin's. It is called from a background
+	// goroutine and must be safe for concurrent use.
+	OnDivergence func(Divergence)
+}
+
+// Divergence describes a cached value found to differ from the origin by a
+// shadow read.
+type Divergence struct {
+	// Type is the value type of the entry.
+	Type reflect.Type
+	Key  any
+	// Cached is the value that was served from the cache and Origin the
+	// value the getter returned afterwards.
+	Cached any
+	Origin any
+	// Age is how long the cached value had been stored.
+	Age time.Duration
+}
+
+// WithShadowReads makes a sample of the hits of Get with a getter, and of
+// read-through instances, call the getter in the background and compare
+// its result with the cached value. Hits are still served from the cache
+// without waiting. Stats counts the comparisons and the divergences, which
+// tell whether longer TTLs would serve stale data before they are rolled
+// out. Values are compared with reflect.DeepEqual; getter errors are not
+// counted as divergences. Shadow getter calls are neither coalesced nor
+// stored and do not count as loads.
+//
+//	cache.SetDefaults(cache.WithShadowReads(cache.ShadowConfig{
+//		SampleRate: 0.01,
+//		OnDivergence: func(d cache.Divergence) {
+//			log.Printf("stale %v %v after %v", d.Type, d.Key, d.Age)
+//		},
+//	}))
+func WithShadowReads(cfg ShadowConfig) Option {
+	return func(o *options) {
+		o.shadow = &cfg
+	}
+}
+
+// sampled reports whether a hit should be compared with the origin.
+func (cfg *ShadowConfig) sampled() bool {
+	return cfg.SampleRate >= 1 || (cfg.SampleRate > 0 && rand.Float64() < cfg.SampleRate)
+}
+
+// shadowRead calls getterFunc for key in the background and compares its
+// result with cached, served from e.
+func shadowRead[K comparable, V any](s *store, cfg *ShadowConfig, e *entry, key K, getterFunc weightedGetter[K, V], cached V) {
+	age := s.now().Sub(e.storedAt)
+	s.goBackground(func() {
+		origin, _, err := getterFunc(key)
+		if err != nil {
+			return
+		}
+		s.shadowReads.Add(1)
+		if reflect.DeepEqual(cached, origin) {
+			return
+		}
+		s.shadowDivergences.Add(1)
+		if cfg.OnDivergence != nil {
+			var zero V
+			cfg.OnDivergence(Divergence{
+				Type:   getTypeOf(zero),
+				Key:    key,
+				Cached: cached,
+				Origin: origin,
+				Age:    age,
+			})
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ShadowTestSuite struct {
+	suite.Suite
+}
+
+func TestShadowSuite(t *testing.T) {
+	suite.Run(t, new(ShadowTestSuite))
+}
+
+// TestDivergenceIsReported verifies that sampled hits compare the cached
+// value with the origin's and report the difference
+func (s *ShadowTestSuite) TestDivergenceIsReported() {
+	var version atomic.Int32
+	var mu sync.Mutex
+	var divergences []Divergence
+	c := New[string, int32](
+		WithTTL(time.Hour),
+		WithLoader(func(string) (int32, error) { return version.Load(), nil }),
+		WithShadowReads(ShadowConfig{
+			SampleRate: 1,
+			OnDivergence: func(d Divergence) {
+				mu.Lock()
+				defer mu.Unlock()
+				divergences = append(divergences, d)
+			},
+		}),
+	)
+	defer c.Close()
+
+	_, err := c.Get("config")
+	s.Require().NoError(err)
+	value, err := c.Get("config")
+	s.Require().NoError(err)
+	s.Equal(int32(0), value)
+	s.Eventually(func() bool { return c.Stats().ShadowReads == 1 }, time.Second, time.Millisecond)
+	s.Zero(c.Stats().ShadowDivergences, "Matching values do not diverge")
+
+	version.Store(1)
+	value, err = c.Get("config")
+	s.Require().NoError(err)
+	s.Equal(int32(0), value, "The hit is served from the cache")
+	s.Eventually(func() bool { return c.Stats().ShadowDivergences == 1 }, time.Second, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	s.Require().Len(divergences, 1)
+	s.Equal("config", divergences[0].Key)
+	s.Equal(int32(0), divergences[0].Cached)
+	s.Equal(int32(1), divergences[0].Origin)
+	s.Equal(getTypeOf(int32(0)), divergences[0].Type)
+	cached, _ := c.Peek("config")
+	s.Equal(int32(0), cached, "Shadow results are not stored")
+}
+
+// TestUnsampledHits verifies that a zero sample rate never calls the origin
+func (s *ShadowTestSuite) TestUnsampledHits() {
+	var calls atomic.Int32
+	c := New[string, int](
+		WithLoader(func(string) (int, error) { calls.Add(1); return 1, nil }),
+		WithShadowReads(ShadowConfig{SampleRate: 0}),
+	)
+	for i := 0; i < 10; i++ {
+		_, err := c.Get("key")
+		s.Require().NoError(err)
+	}
+	s.Equal(int32(1), calls.Load())
+	s.Zero(c.Stats().ShadowReads)
+}
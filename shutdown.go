@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// WithPersistOnShutdown makes Shutdown write every live entry to the
+// backing store, with its remaining time to live, so a restarted process
+// can pick them up. It has no effect without WithStore.
+func WithPersistOnShutdown() Option {
+	return func(o *options) {
+		o.persistOnShutdown = true
+	}
+}
+
+// Shutdown stops the background work of the package-level cache, such as
+// a janitor, memory monitor or refresh-ahead enabled through SetDefaults
+// and the refreshes started by ScheduleRefresh.
+// It returns ctx.Err() if ctx ends before the workers have stopped.
+func Shutdown(ctx context.Context) error {
+	return globalStore().shutdown(ctx)
+}
+
+// Shutdown stops the cache's background work, including that of its
+// namespaces: the janitor, memory monitor, scheduled refreshes and
+// refresh-ahead are stopped, pending write-behind writes are flushed,
+// access frequencies are saved for WithPersistentFrequencies, hot keys are
+// recorded for WithWarmup, with WithPersistOnShutdown, live entries are
+// written to the backing store and values spilled to disk are dropped. If
+// ctx ends first, Shutdown returns ctx.Err() and the remaining flushes
+// continue in the background.
+//
+// The cache remains usable for local operations afterwards. Shutdown is
+// safe to call more than once.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) error {
+	var firstErr error
+	for _, ns := range c.namespaceList() {
+		if err := ns.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := c.s.shutdown(ctx); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// shutdown stops the store's workers and persists its entries if
+// configured to.
+func (s *store) shutdown(ctx context.Context) error {
+	if err := s.stopJanitor(ctx, false); err != nil {
+		return err
+	}
+	if err := s.stopMemoryMonitor(ctx); err != nil {
+		return err
+	}
+	if err := s.stopSchedules(ctx); err != nil {
+		return err
+	}
+	if err := s.stopRefreshes(ctx); err != nil {
+		return err
+	}
+	if s.writeBehind != nil {
+		if err := s.writeBehind.shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if err := s.stopSketchSaver(ctx); err != nil {
+		return err
+	}
+	if s.warmup > 0 && s.remote != nil {
+		if err := s.saveHotKeys(ctx); err != nil {
+			return err
+		}
+	}
+	if s.persistOnShutdown && s.remote != nil {
+		if err := s.persist(ctx); err != nil {
+			return err
+		}
+	}
+	return s.dropSpilled()
+}
+
+// persist writes every live entry to the backing store.
+func (s *store) persist(ctx context.Context) error {
+	type liveEntry struct {
+		valueType reflect.Type
+		key       any
+		e         *entry
+	}
+	now := s.now()
+	s.rlock()
+	live := make([]liveEntry, 0, s.count)
+	s.rangeAllLocked(func(valueType reflect.Type, key any, e *entry) bool {
+		if !e.expired(now) {
+			live = append(live, liveEntry{valueType, key, e})
+		}
+		return true
+	})
+	s.mu.RUnlock()
+
+	var failed int
+	var firstErr error
+	fail := func(err error) {
+		failed++
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	items := make([]StoreItem, 0, len(live))
+	for _, l := range live {
+		value, live := l.e.get()
+		if !live {
+			continue
+		}
+		data, err := s.codecFor(l.valueType).Marshal(value)
+		if err != nil {
+			fail(fmt.Errorf("encoding key %v: %w", s.redactKey(s.typeName(l.valueType), l.key), err))
+			continue
+		}
+		var ttl time.Duration
+		if !l.e.expiresAt.IsZero() {
+			ttl = l.e.expiresAt.Sub(now)
+		}
+		items = append(items, StoreItem{Key: s.remoteKey(l.valueType, l.key), Value: data, TTL: ttl})
+	}
+
+	if bs, ok := s.remote.(BatchStore); ok && len(items) > 0 {
+		if err := bs.SetMany(ctx, items); err != nil {
+			failed += len(items)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	} else {
+		for _, item := range items {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.remote.Set(ctx, item.Key, item.Value, item.TTL); err != nil {
+				fail(err)
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return fmt.Errorf("cache: persisting %d of %d entries failed: %w", failed, len(live), firstErr)
+	}
+	return nil
+}
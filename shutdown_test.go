@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// blockingStore holds every write until released
+type blockingStore struct {
+	*memoryStore
+	release chan struct{}
+}
+
+func (b *blockingStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	<-b.release
+	return b.memoryStore.Set(ctx, key, value, ttl)
+}
+
+type ShutdownTestSuite struct {
+	suite.Suite
+	remote *memoryStore
+}
+
+func TestShutdownSuite(t *testing.T) {
+	suite.Run(t, new(ShutdownTestSuite))
+}
+
+// SetupTest runs before each test
+func (s *ShutdownTestSuite) SetupTest() {
+	s.remote = newMemoryStore()
+}
+
+// TestJanitorRemovesExpiredEntries verifies that expired entries are reclaimed
+func (s *ShutdownTestSuite) TestJanitorRemovesExpiredEntries() {
+	clock := NewFakeClock(time.Now())
+	c := New[int, string](WithClock(clock), WithTTL(time.Minute), WithJanitor(time.Millisecond))
+	defer c.Close()
+
+	c.Set(1, "expiring")
+	clock.Advance(time.Minute)
+
+	s.Eventually(func() bool {
+		c.s.mu.RLock()
+		defer c.s.mu.RUnlock()
+		return c.s.count == 0
+	}, time.Second, time.Millisecond, "The janitor should remove the expired entry")
+}
+
+// TestShutdownStopsJanitor verifies that no sweeps happen after Shutdown
+func (s *ShutdownTestSuite) TestShutdownStopsJanitor() {
+	clock := NewFakeClock(time.Now())
+	c := New[int, string](WithClock(clock), WithTTL(time.Minute), WithJanitor(time.Millisecond))
+
+	s.NoError(c.Shutdown(context.Background()))
+	c.Set(1, "expiring")
+	clock.Advance(time.Minute)
+	time.Sleep(10 * time.Millisecond)
+
+	c.s.mu.RLock()
+	defer c.s.mu.RUnlock()
+	s.Equal(1, c.s.count, "A stopped janitor should not sweep")
+}
+
+// TestShutdownDrainsWriteBehind verifies that queued writes are flushed
+func (s *ShutdownTestSuite) TestShutdownDrainsWriteBehind() {
+	c := New[int, string](WithStore(s.remote), WithWriteBehind(WriteBehindConfig{FlushInterval: time.Hour}))
+	c.Set(1, "queued")
+
+	s.NoError(c.Shutdown(context.Background()))
+
+	_, found, _ := s.remote.Get(context.Background(), c.s.remoteKey(c.valueType, 1))
+	s.True(found)
+}
+
+// TestShutdownRespectsDeadline verifies that Shutdown gives up when ctx ends
+func (s *ShutdownTestSuite) TestShutdownRespectsDeadline() {
+	blocking := &blockingStore{memoryStore: s.remote, release: make(chan struct{})}
+	c := New[int, string](WithStore(blocking), WithWriteBehind(WriteBehindConfig{FlushInterval: time.Hour}))
+	c.Set(1, "stuck")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	s.ErrorIs(c.Shutdown(ctx), context.DeadlineExceeded)
+
+	// The flush completes in the background once the store recovers
+	close(blocking.release)
+	s.NoError(c.Shutdown(context.Background()))
+	s.Equal(int32(1), s.remote.sets.Load())
+}
+
+// TestShutdownPersistsEntries verifies WithPersistOnShutdown
+func (s *ShutdownTestSuite) TestShutdownPersistsEntries() {
+	clock := NewFakeClock(time.Now())
+	c := New[int, string](WithStore(s.remote), WithPersistOnShutdown(), WithClock(clock), WithTTL(time.Hour))
+	c.Set(1, "live")
+	c.Set(2, "deleted")
+	c.Delete(2)
+	clock.Advance(30 * time.Minute)
+
+	s.NoError(c.Shutdown(context.Background()))
+
+	key := c.s.remoteKey(c.valueType, 1)
+	data, found, _ := s.remote.Get(context.Background(), key)
+	s.True(found)
+	s.Equal(`"live"`, string(data))
+	s.Equal(30*time.Minute, s.remote.ttls[key], "Entries keep their remaining time to live")
+	s.Equal(int32(1), s.remote.sets.Load())
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// WithSizeEstimator sets the function used to estimate the size in bytes
+// of cached values, used by byte limits and statistics. By default a
+// reflection-based estimate of the memory reachable from the value is used.
+func WithSizeEstimator(fn func(value any) int64) Option {
+	return func(o *options) {
+		o.sizeOf = fn
+	}
+}
+
+// estimateSize approximates the number of bytes of memory held by v,
+// following pointers, slices, maps and interfaces. Memory shared by several
+// references is counted once.
+func estimateSize(v any) int64 {
+	if v == nil {
+		return 0
+	}
+	rv := reflect.ValueOf(v)
+	seen := make(map[uintptr]bool)
+	return int64(rv.Type().Size()) + indirectSize(rv, seen)
+}
+
+// indirectSize returns the bytes referenced by v, excluding v itself.
+func indirectSize(v reflect.Value, seen map[uintptr]bool) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Pointer:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + indirectSize(elem, seen)
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + indirectSize(elem, seen)
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += indirectSize(v.Index(i), seen)
+		}
+		return size
+	case reflect.Array:
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += indirectSize(v.Index(i), seen)
+		}
+		return size
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		// Rough per-entry bucket overhead on top of keys and values
+		perEntry := int64(v.Type().Key().Size()+v.Type().Elem().Size()) + int64(unsafe.Sizeof(uintptr(0)))
+		size := int64(v.Len()) * perEntry
+		iter := v.MapRange()
+		for iter.Next() {
+			size += indirectSize(iter.Key(), seen) + indirectSize(iter.Value(), seen)
+		}
+		return size
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += indirectSize(v.Field(i), seen)
+		}
+		return size
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,243 @@
+package cache
+
+import (
+	"hash/maphash"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// sketchDepth is the number of hash rows of a sketch.
+const sketchDepth = 4
+
+// FrequencyStats describes the access frequency tracker of a cache.
+type FrequencyStats struct {
+	// Enabled reports whether access frequencies are tracked.
+	Enabled bool
+	// Width and Depth are the dimensions of the count-min sketch.
+	Width, Depth int
+	// Samples is the number of accesses recorded since the last aging.
+	Samples int
+	// Agings counts how often all counters were halved so estimates
+	// follow recent traffic.
+	Agings uint64
+	// Restored reports whether the counters were loaded from a previous
+	// process by WithPersistentFrequencies.
+	Restored bool
+}
+
+// WithFrequencyTracking makes the cache estimate how often each key is
+// accessed with a count-min sketch, at the cost of a few hashes per
+// lookup. The estimates rank keys for HotKeys and WithWarmup; Stats
+// reports the state of the sketch.
+func WithFrequencyTracking() Option {
+	return func(o *options) {
+		o.trackFrequency = true
+	}
+}
+
+// HotKeys returns up to n keys with values of type V in the package-level
+// cache, most frequently accessed first. It returns nil unless frequency
+// tracking was enabled with SetDefaults.
+func HotKeys[K comparable, V any](n int) []K {
+	var zero V
+	return typedKeys[K](globalStore().hotKeys(getTypeOf(zero), n))
+}
+
+// HotKeys returns up to n cached keys, most frequently accessed first. It
+// returns nil unless frequency tracking is enabled.
+func (c *Cache[K, V]) HotKeys(n int) []K {
+	return typedKeys[K](c.s.hotKeys(c.valueType, n))
+}
+
+func typedKeys[K comparable](keys []any) []K {
+	if keys == nil {
+		return nil
+	}
+	typed := make([]K, len(keys))
+	for i, key := range keys {
+		typed[i], _ = typedKey[K](key)
+	}
+	return typed
+}
+
+// sketch is a count-min sketch estimating how often keys are accessed.
+// Counters are halved periodically so that estimates follow recent
+// traffic rather than all-time totals.
+type sketch struct {
+	seed maphash.Seed
+	// portable sketches hash keys with FNV-1a salted with salt instead,
+	// so their counters stay valid in another process
+	portable bool
+	salt     uint64
+	// restored is set once counters saved by a previous process were
+	// merged in
+	restored bool
+
+	mu   sync.Mutex
+	rows [sketchDepth][]uint32
+	mask uint64
+	// additions counts increments since the last halving
+	additions int
+	// resetAt is how many additions trigger a halving
+	resetAt int
+	agings  uint64
+}
+
+// newSketch returns a sketch sized for about n distinct hot keys.
+func newSketch(n int) *sketch {
+	width := 1024
+	for width < n*8 {
+		width *= 2
+	}
+	sk := &sketch{
+		seed:    maphash.MakeSeed(),
+		mask:    uint64(width - 1),
+		resetAt: width * 10,
+	}
+	for i := range sk.rows {
+		sk.rows[i] = make([]uint32, width)
+	}
+	return sk
+}
+
+// hash returns the sketch hash of an entry.
+func (sk *sketch) hash(valueType reflect.Type, key any) uint64 {
+	if sk.portable {
+		return portableHash(sk.salt, keyString(valueType, key))
+	}
+	var h maphash.Hash
+	h.SetSeed(sk.seed)
+	h.WriteString(keyString(valueType, key))
+	return h.Sum64()
+}
+
+// index returns the counter of row i for hash h, using double hashing.
+func (sk *sketch) index(h uint64, i int) uint64 {
+	return (h + uint64(i)*(h>>32|1)) & sk.mask
+}
+
+// increment records one access for hash h.
+func (sk *sketch) increment(h uint64) {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	for i := range sk.rows {
+		if c := &sk.rows[i][sk.index(h, i)]; *c < ^uint32(0) {
+			*c++
+		}
+	}
+	sk.additions++
+	if sk.additions >= sk.resetAt {
+		sk.halveLocked()
+	}
+}
+
+// estimate returns the approximate access count for hash h. It never
+// underestimates, except for the effect of halving.
+func (sk *sketch) estimate(h uint64) uint32 {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	lowest := ^uint32(0)
+	for i := range sk.rows {
+		if c := sk.rows[i][sk.index(h, i)]; c < lowest {
+			lowest = c
+		}
+	}
+	return lowest
+}
+
+func (sk *sketch) halveLocked() {
+	for i := range sk.rows {
+		for j := range sk.rows[i] {
+			sk.rows[i][j] >>= 1
+		}
+	}
+	sk.additions /= 2
+	sk.agings++
+}
+
+// stats describes the sketch.
+func (sk *sketch) stats() FrequencyStats {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	return FrequencyStats{
+		Enabled:  true,
+		Width:    len(sk.rows[0]),
+		Depth:    sketchDepth,
+		Samples:  sk.additions,
+		Agings:   sk.agings,
+		Restored: sk.restored,
+	}
+}
+
+// sketchSize is the number of keys sketches are sized for when nothing
+// hints at a better value.
+const sketchSize = 4096
+
+// trackFrequency starts tracking access frequencies with a sketch sized for
+// about n keys, unless the store already does.
+func (s *store) trackFrequency(n int) {
+	if n < sketchSize {
+		n = sketchSize
+	}
+	s.sketch.CompareAndSwap(nil, newSketch(n))
+}
+
+// recordAccess feeds an access of key to the store's sketch, if it tracks
+// access frequencies, and to the admission policy.
+func (s *store) recordAccess(valueType reflect.Type, key any) {
+	if sk := s.sketch.Load(); sk != nil {
+		sk.increment(sk.hash(valueType, key))
+	}
+	if policy := s.cfg().admission; policy != nil {
+		policy.Record(key)
+	}
+}
+
+// rankedKey is a live key with its estimated access frequency.
+type rankedKey struct {
+	key  any
+	freq uint32
+}
+
+// hotKeys returns up to n live keys of valueType, most frequently accessed
+// first.
+func (s *store) hotKeys(valueType reflect.Type, n int) []any {
+	return topKeys(s.rankedKeys(valueType), n)
+}
+
+// rankedKeys returns the live keys of valueType with their estimated
+// access frequencies, or nil if frequencies are not tracked.
+func (s *store) rankedKeys(valueType reflect.Type) []rankedKey {
+	sk := s.sketch.Load()
+	if sk == nil {
+		return nil
+	}
+	now := s.now()
+	s.rlock()
+	ranked := make([]rankedKey, 0, s.lenLocked(valueType))
+	s.rangeLocked(valueType, func(key any, e *entry) bool {
+		if !e.expired(now) {
+			ranked = append(ranked, rankedKey{key, sk.estimate(sk.hash(valueType, key))})
+		}
+		return true
+	})
+	s.mu.RUnlock()
+	return ranked
+}
+
+// topKeys returns up to n keys of ranked, most frequently accessed first.
+func topKeys(ranked []rankedKey, n int) []any {
+	if ranked == nil {
+		return nil
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].freq > ranked[j].freq })
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	keys := make([]any, len(ranked))
+	for i, r := range ranked {
+		keys[i] = r.key
+	}
+	return keys
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SketchTestSuite struct {
+	suite.Suite
+}
+
+func TestSketchSuite(t *testing.T) {
+	suite.Run(t, new(SketchTestSuite))
+}
+
+// TestSketchEstimates verifies that the sketch ranks keys by frequency
+func (s *SketchTestSuite) TestSketchEstimates() {
+	sk := newSketch(16)
+	valueType := getTypeOf("")
+	hot, cold := sk.hash(valueType, "hot"), sk.hash(valueType, "cold")
+	for i := 0; i < 100; i++ {
+		sk.increment(hot)
+	}
+	sk.increment(cold)
+
+	s.GreaterOrEqual(sk.estimate(hot), uint32(100), "Estimates never undercount")
+	s.Less(sk.estimate(cold), sk.estimate(hot))
+}
+
+// TestSketchAging verifies that counters are halved periodically
+func (s *SketchTestSuite) TestSketchAging() {
+	sk := newSketch(16)
+	h := sk.hash(getTypeOf(""), "key")
+	for i := 0; i < sk.resetAt; i++ {
+		sk.increment(h)
+	}
+
+	s.Equal(uint32(sk.resetAt/2), sk.estimate(h))
+	s.Equal(uint64(1), sk.stats().Agings)
+}
+
+// TestHotKeys verifies that keys are ranked by access frequency
+func (s *SketchTestSuite) TestHotKeys() {
+	c := New[string, int](WithFrequencyTracking())
+	for key, reads := range map[string]int{"cold": 1, "warm": 5, "hot": 20} {
+		c.Set(key, reads)
+		for i := 0; i < reads; i++ {
+			_, _ = c.Get(key)
+		}
+	}
+
+	s.Equal([]string{"hot", "warm"}, c.HotKeys(2))
+	s.Equal([]string{"hot", "warm", "cold"}, c.HotKeys(10))
+	s.Nil(New[string, int]().HotKeys(10), "Without tracking there is no ranking")
+}
+
+// TestFrequencyStats verifies that Stats describes the sketch
+func (s *SketchTestSuite) TestFrequencyStats() {
+	c := New[string, int](WithFrequencyTracking())
+	c.Set("key", 1)
+	_, _ = c.Get("key")
+	_, _ = c.Get("missing")
+
+	freq := c.Stats().Frequency
+	s.True(freq.Enabled)
+	s.Equal(sketchDepth, freq.Depth)
+	s.GreaterOrEqual(freq.Width, sketchSize)
+	s.Equal(2, freq.Samples)
+
+	s.False(New[string, int]().Stats().Frequency.Enabled)
+}
+
+// TestPackageLevelHotKeys verifies SetDefaults(WithFrequencyTracking)
+func (s *SketchTestSuite) TestPackageLevelHotKeys() {
+	Scoped(s.T())
+	SetDefaults(WithFrequencyTracking())
+	getter := func(key int) (int, error) { return key, nil }
+	for i := 0; i < 3; i++ {
+		_, _ = Get(1, getter)
+	}
+	_, _ = Get(2, getter)
+
+	s.Equal([]int{1}, HotKeys[int, int](1))
+}
+
+// TestPersistentFrequencies verifies that frequencies saved on Shutdown
+// rank the keys of a cache created later
+func (s *SketchTestSuite) TestPersistentFrequencies() {
+	path := filepath.Join(s.T().TempDir(), "frequencies")
+	before := New[string, int](WithPersistentFrequencies(PersistentFrequencies{Path: path}))
+	s.Require().NoError(before.Set("rare", 1))
+	s.Require().NoError(before.Set("popular", 2))
+	for i := 0; i < 20; i++ {
+		before.Get("popular")
+	}
+	before.Get("rare")
+	s.Require().NoError(before.Shutdown(context.Background()))
+
+	after := New[string, int](WithPersistentFrequencies(PersistentFrequencies{Path: path}))
+	defer after.Shutdown(context.Background())
+	s.Eventually(func() bool { return after.Stats().Frequency.Restored }, time.Second, time.Millisecond)
+	s.Require().NoError(after.Set("rare", 1))
+	s.Require().NoError(after.Set("popular", 2))
+	s.Equal([]string{"popular", "rare"}, after.HotKeys(2))
+}
+
+// TestSketchMerge verifies that saved counters are added to live ones,
+// whatever the widths of the sketches
+func (s *SketchTestSuite) TestSketchMerge() {
+	for _, width := range []int{512, 2048, 8192} {
+		saved := newPortableSketch(width)
+		live := newPortableSketch(2048)
+		popular := saved.hash(nil, "popular")
+		for i := 0; i < 10; i++ {
+			saved.increment(popular)
+		}
+		live.increment(popular)
+
+		s.Require().NoError(live.merge(saved))
+		s.GreaterOrEqual(live.estimate(popular), uint32(11))
+		s.True(live.stats().Restored)
+	}
+
+	other := newPortableSketch(2048)
+	other.salt++
+	s.Error(newPortableSketch(2048).merge(other))
+}
+
+// TestPersistentFrequenciesInStore verifies that frequencies are kept in
+// the backing store without a path, and that corrupted ones are reported
+func (s *SketchTestSuite) TestPersistentFrequenciesInStore() {
+	remote := newMemoryStore()
+	before := New[string, int](WithStore(remote), WithPersistentFrequencies(PersistentFrequencies{}))
+	s.Require().NoError(before.Shutdown(context.Background()))
+	_, found, _ := remote.Get(context.Background(), sketchKey)
+	s.True(found)
+
+	s.Require().NoError(remote.Set(context.Background(), sketchKey, []byte("garbage"), 0))
+	events := make(chan Event, 1)
+	after := New[string, int](
+		WithStore(remote),
+		WithEventHandler(func(ev Event) { events <- ev }),
+		WithPersistentFrequencies(PersistentFrequencies{}),
+	)
+	defer after.Close()
+	ev := <-events
+	s.Equal(EventStoreError, ev.Kind)
+	s.False(after.Stats().Frequency.Restored)
+}
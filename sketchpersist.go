@@ -0,0 +1,310 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// sketchKey is the backing store key of a persisted sketch.
+const sketchKey = "__sketch__"
+
+// sketchMagic starts encoded sketches.
+const sketchMagic = "cms1"
+
+// sketchSalt salts the hashes of portable sketches. It is the same in
+// every process so that saved counters can be merged into live ones.
+const sketchSalt = 0x9e3779b97f4a7c15
+
+// PersistentFrequencies configures WithPersistentFrequencies.
+type PersistentFrequencies struct {
+	// Path is the local file holding the frequencies. If empty, they are
+	// kept in the backing store instead.
+	Path string
+	// Interval is how often the frequencies are saved. Shutdown saves them
+	// as well. Default 1 minute.
+	Interval time.Duration
+}
+
+// WithPersistentFrequencies saves the access frequencies estimated by
+// frequency tracking periodically and on Shutdown, and a cache created
+// later with the same option merges them in the background into the
+// frequencies it has estimated meanwhile, so that
+// HotKeys and WithWarmup rank keys by the traffic seen before a restart
+// rather than from scratch. Stats().Frequency.Restored reports
+// whether they were loaded. Keys are identified by their printed form,
+// which must not change across processes; pointer keys are not.
+//
+// WithPersistentFrequencies implies WithFrequencyTracking. With an empty
+// Path it has no effect without WithStore.
+//
+//	c := cache.New[string, *Product](
+//		cache.WithStore(redisStore),
+//		cache.WithWarmup(1000),
+//		cache.WithPersistentFrequencies(cache.PersistentFrequencies{Path: "/var/lib/shop/products.cms"}),
+//	)
+func WithPersistentFrequencies(cfg PersistentFrequencies) Option {
+	return func(o *options) {
+		o.frequencies = &cfg
+	}
+}
+
+// portableHash hashes s with FNV-1a, starting from salt.
+func portableHash(salt uint64, s string) uint64 {
+	h := fnv.New64a()
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], salt)
+	h.Write(b[:])
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// newPortableSketch returns a sketch sized for about n distinct hot keys
+// whose counters can be loaded by another process.
+func newPortableSketch(n int) *sketch {
+	sk := newSketch(n)
+	sk.portable = true
+	sk.salt = sketchSalt
+	return sk
+}
+
+// merge adds the counters of other, a sketch loaded from a previous
+// process, to those of sk. Sketches of different widths are folded onto
+// each other, which may only overestimate. It fails if other hashes keys
+// differently.
+func (sk *sketch) merge(other *sketch) error {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	if !sk.portable || other.salt != sk.salt {
+		return errors.New("frequencies were saved with a different hash")
+	}
+	for i := range sk.rows {
+		live, saved := sk.rows[i], other.rows[i]
+		if len(saved) >= len(live) {
+			for j, c := range saved {
+				addCounter(&live[uint64(j)&sk.mask], c)
+			}
+		} else {
+			for j := range live {
+				addCounter(&live[j], saved[uint64(j)&other.mask])
+			}
+		}
+	}
+	sk.additions += other.additions
+	sk.agings += other.agings
+	for sk.additions >= sk.resetAt {
+		sk.halveLocked()
+	}
+	sk.restored = true
+	return nil
+}
+
+// addCounter adds n to the counter c, saturating.
+func addCounter(c *uint32, n uint32) {
+	if *c+n < *c {
+		*c = ^uint32(0)
+		return
+	}
+	*c += n
+}
+
+// MarshalBinary encodes the sketch.
+func (sk *sketch) MarshalBinary() ([]byte, error) {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	width := len(sk.rows[0])
+	data := make([]byte, 0, len(sketchMagic)+28+sketchDepth*width*4)
+	data = append(data, sketchMagic...)
+	data = binary.LittleEndian.AppendUint64(data, sk.salt)
+	data = binary.LittleEndian.AppendUint32(data, uint32(width))
+	data = binary.LittleEndian.AppendUint64(data, uint64(sk.additions))
+	data = binary.LittleEndian.AppendUint64(data, sk.agings)
+	for i := range sk.rows {
+		for _, c := range sk.rows[i] {
+			data = binary.LittleEndian.AppendUint32(data, c)
+		}
+	}
+	return data, nil
+}
+
+// decodeSketch decodes a sketch encoded by MarshalBinary.
+func decodeSketch(data []byte) (*sketch, error) {
+	const header = len(sketchMagic) + 28
+	if len(data) < header || string(data[:len(sketchMagic)]) != sketchMagic {
+		return nil, errors.New("not an encoded frequency sketch")
+	}
+	data = data[len(sketchMagic):]
+	salt := binary.LittleEndian.Uint64(data)
+	width := int(binary.LittleEndian.Uint32(data[8:]))
+	additions := binary.LittleEndian.Uint64(data[12:])
+	agings := binary.LittleEndian.Uint64(data[20:])
+	data = data[28:]
+	if width == 0 || width&(width-1) != 0 || len(data) != sketchDepth*width*4 {
+		return nil, errors.New("corrupted frequency sketch")
+	}
+	sk := &sketch{
+		portable:  true,
+		salt:      salt,
+		mask:      uint64(width - 1),
+		resetAt:   width * 10,
+		additions: int(additions),
+		agings:    agings,
+	}
+	for i := range sk.rows {
+		sk.rows[i] = make([]uint32, width)
+		for j := range sk.rows[i] {
+			sk.rows[i][j] = binary.LittleEndian.Uint32(data)
+			data = data[4:]
+		}
+	}
+	return sk, nil
+}
+
+// sketchSaver loads a store's sketch at startup and saves it periodically.
+type sketchSaver struct {
+	s   *store
+	cfg PersistentFrequencies
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// startSketchSaver makes the store's sketch portable, loads the one saved
+// by a previous process in the background and saves it every interval.
+func (s *store) startSketchSaver(cfg PersistentFrequencies, n int) {
+	if cfg.Path == "" && s.remote == nil {
+		return
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if n < sketchSize {
+		n = sketchSize
+	}
+	s.sketch.Store(newPortableSketch(n))
+	p := &sketchSaver{
+		s:    s,
+		cfg:  cfg,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	s.workersMu.Lock()
+	s.sketchSaver = p
+	s.workersMu.Unlock()
+	go p.run()
+}
+
+// stopSketchSaver stops the store's sketch saver, if any, and saves the
+// sketch one last time.
+func (s *store) stopSketchSaver(ctx context.Context) error {
+	s.workersMu.Lock()
+	p := s.sketchSaver
+	s.sketchSaver = nil
+	s.workersMu.Unlock()
+	if p == nil {
+		return nil
+	}
+	p.stopOnce.Do(func() { close(p.stop) })
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return p.save(ctx)
+}
+
+func (p *sketchSaver) run() {
+	defer close(p.done)
+	if err := p.load(context.Background()); err != nil {
+		p.s.emit(Event{Kind: EventStoreError, Err: err})
+	}
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.save(context.Background()); err != nil {
+				p.s.emit(Event{Kind: EventStoreError, Err: err})
+			}
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// load merges the saved sketch, if any, into the store's, keeping the
+// accesses recorded while it was read.
+func (p *sketchSaver) load(ctx context.Context) error {
+	var data []byte
+	if p.cfg.Path != "" {
+		var err error
+		data, err = os.ReadFile(p.cfg.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cache: reading frequencies: %w", err)
+		}
+	} else {
+		var found bool
+		var err error
+		data, found, err = p.s.remote.Get(ctx, p.s.keyPrefix+sketchKey)
+		if err != nil {
+			return fmt.Errorf("cache: reading frequencies: %w", err)
+		}
+		if !found {
+			return nil
+		}
+	}
+	sk, err := decodeSketch(data)
+	if err != nil {
+		return fmt.Errorf("cache: decoding frequencies: %w", err)
+	}
+	live := p.s.sketch.Load()
+	if live == nil {
+		return nil
+	}
+	if err := live.merge(sk); err != nil {
+		return fmt.Errorf("cache: merging frequencies: %w", err)
+	}
+	return nil
+}
+
+// save writes the store's sketch, replacing a file atomically.
+func (p *sketchSaver) save(ctx context.Context) error {
+	sk := p.s.sketch.Load()
+	if sk == nil {
+		return nil
+	}
+	data, _ := sk.MarshalBinary()
+	if p.cfg.Path == "" {
+		if err := p.s.remote.Set(ctx, p.s.keyPrefix+sketchKey, data, 0); err != nil {
+			return fmt.Errorf("cache: writing frequencies: %w", err)
+		}
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p.cfg.Path), filepath.Base(p.cfg.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("cache: writing frequencies: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cache: writing frequencies: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cache: writing frequencies: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p.cfg.Path); err != nil {
+		return fmt.Errorf("cache: writing frequencies: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// WithSegmentedLRU splits the cache into a hot segment holding at most
+// hotFraction of the entries and a cold segment holding the rest, like an
+// SLRU. Entries start cold and are promoted to the hot segment when read
+// again; when the cache is full, cold entries are evicted first, and the
+// least recently used hot entries are demoted to cold when the hot segment
+// outgrows its share. Keys read once, as in a scan, thus cannot flush the
+// entries read repeatedly, which improves the hit ratio of mixed workloads
+// over plain LRU. hotFraction must be between 0 and 1; zero disables
+// segmentation.
+func WithSegmentedLRU(hotFraction float64) Option {
+	return func(o *options) {
+		o.hotFraction = hotFraction
+	}
+}
+
+// segmented reports whether the store splits entries into segments.
+func (s *store) segmented() bool {
+	fraction := s.hotFraction()
+	return fraction > 0 && fraction < 1
+}
+
+// promote moves e to the hot segment after a repeat read.
+func (s *store) promote(e *entry) {
+	if s.segmented() && e.hot.CompareAndSwap(false, true) {
+		s.hotEntries.Add(1)
+	}
+}
+
+// demote moves e back to the cold segment.
+func (s *store) demote(e *entry) {
+	if e.hot.CompareAndSwap(true, false) {
+		s.hotEntries.Add(-1)
+	}
+}
+
+// hotOverflowLocked reports whether the hot segment holds more than its
+// share of the entries. Must be called with s.mu held.
+func (s *store) hotOverflowLocked() bool {
+	return s.segmented() && float64(s.hotEntries.Load()) > s.hotFraction()*float64(s.count)
+}
+
+// AdaptiveConfig configures the tuning of the hot segment size by
+// WithAdaptiveSegments. Zero fields take their defaults.
+type AdaptiveConfig struct {
+	// Window is how many lookups the hit ratio is measured over between
+	// two adjustments. Default 10,000.
+	Window int64
+	// Step is how much the hot fraction moves at each adjustment.
+	// Default 0.05.
+	Step float64
+	// MinFraction and MaxFraction bound the hot fraction. Defaults 0.05
+	// and 0.95.
+	MinFraction float64
+	MaxFraction float64
+}
+
+// WithAdaptiveSegments tunes the share of the hot segment of the
+// segmented LRU to the workload instead of keeping it fixed. After every
+// window of lookups the hit ratio is compared with that of the previous
+// window: the hot fraction keeps moving in the same direction while the
+// hit ratio improves and turns around when it drops, settling where the
+// workload is served best, like the hill climbing of adaptive caches. It
+// starts at the fraction set with WithSegmentedLRU, or 0.8 without it.
+// Statistics report the current fraction.
+func WithAdaptiveSegments(cfg AdaptiveConfig) Option {
+	return func(o *options) {
+		o.adaptive = &cfg
+	}
+}
+
+// segmentTuner adjusts the hot fraction by hill climbing on the hit ratio.
+type segmentTuner struct {
+	cfg AdaptiveConfig
+
+	// fraction holds the bits of the current hot fraction
+	fraction atomic.Uint64
+	// lookups and hits count the lookups of the current window
+	lookups atomic.Int64
+	hits    atomic.Int64
+
+	// mu guards the climbing state
+	mu        sync.Mutex
+	lastRatio float64
+	direction float64
+}
+
+func newSegmentTuner(cfg AdaptiveConfig, initial float64) *segmentTuner {
+	if cfg.Window <= 0 {
+		cfg.Window = 10_000
+	}
+	if cfg.Step <= 0 {
+		cfg.Step = 0.05
+	}
+	if cfg.MinFraction <= 0 {
+		cfg.MinFraction = 0.05
+	}
+	if cfg.MaxFraction <= 0 || cfg.MaxFraction >= 1 {
+		cfg.MaxFraction = 0.95
+	}
+	if initial <= 0 || initial >= 1 {
+		initial = 0.8
+	}
+	t := &segmentTuner{cfg: cfg, lastRatio: -1, direction: 1}
+	t.fraction.Store(math.Float64bits(t.clamp(initial)))
+	return t
+}
+
+func (t *segmentTuner) current() float64 {
+	return math.Float64frombits(t.fraction.Load())
+}
+
+func (t *segmentTuner) clamp(fraction float64) float64 {
+	return math.Max(t.cfg.MinFraction, math.Min(t.cfg.MaxFraction, fraction))
+}
+
+// observe counts a lookup and adjusts the fraction when it closes a
+// window.
+func (t *segmentTuner) observe(hit bool) {
+	if hit {
+		t.hits.Add(1)
+	}
+	if t.lookups.Add(1)%t.cfg.Window != 0 {
+		return
+	}
+	// This lookup closed the window
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ratio := float64(t.hits.Swap(0)) / float64(t.cfg.Window)
+	if t.lastRatio >= 0 && ratio < t.lastRatio {
+		t.direction = -t.direction
+	}
+	t.lastRatio = ratio
+	next := t.clamp(t.current() + t.direction*t.cfg.Step)
+	if next == t.current() {
+		// Bounced off a bound
+		t.direction = -t.direction
+	}
+	t.fraction.Store(math.Float64bits(next))
+}
+
+// hotFraction returns the share of entries the hot segment may hold.
+func (s *store) hotFraction() float64 {
+	if t := s.tuner.Load(); t != nil {
+		return t.current()
+	}
+	return s.cfg().hotFraction
+}
+
+// tuneSegments feeds a lookup to the segment tuner, if any.
+func (s *store) tuneSegments(hit bool) {
+	if t := s.tuner.Load(); t != nil {
+		t.observe(hit)
+	}
+}
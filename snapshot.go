@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"reflect"
+	"time"
+)
+
+// Snapshot is an immutable, read-only view of a Cache at the moment it was
+// taken. Later writes to the cache are not visible through it. Snapshots
+// are safe for concurrent use.
+type Snapshot[K comparable, V any] struct {
+	entries Backend
+	// at is when the snapshot was taken; entries expired by then are
+	// not part of it
+	at time.Time
+	// bytesMode is how []byte values are served
+	bytesMode BytesMode
+}
+
+// Snapshot returns a point-in-time view of the cache. Taking a snapshot is
+// cheap: no entries are copied up front. Instead the cache copies its
+// storage lazily, the first time it is modified after the snapshot.
+func (c *Cache[K, V]) Snapshot() *Snapshot[K, V] {
+	s := c.s
+	s.lock()
+	defer s.mu.Unlock()
+
+	entries := s.data[c.valueType]
+	if entries != nil {
+		if s.shared == nil {
+			s.shared = make(map[reflect.Type]bool)
+		}
+		s.shared[c.valueType] = true
+	}
+	return &Snapshot[K, V]{entries: entries, at: c.s.now(), bytesMode: c.s.cfg().bytesMode}
+}
+
+// Get returns the value key had when the snapshot was taken.
+func (sn *Snapshot[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if sn.entries == nil {
+		return zero, false
+	}
+	v, ok := sn.entries.Load(canonicalKey(key))
+	if !ok {
+		return zero, false
+	}
+	e := v.(*entry)
+	if e.expired(sn.at) {
+		return zero, false
+	}
+	value, ok := e.get()
+	if !ok {
+		return zero, false
+	}
+	typedValue, ok := sn.bytesMode.serve(value).(V)
+	if !ok {
+		return zero, false
+	}
+	return typedValue, true
+}
+
+// Len returns the number of entries in the snapshot.
+func (sn *Snapshot[K, V]) Len() int {
+	n := 0
+	sn.rangeLive(func(any, *entry) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// Range calls fn for every entry in the snapshot, in no particular order,
+// until fn returns false.
+func (sn *Snapshot[K, V]) Range(fn func(key K, value V) bool) {
+	sn.rangeLive(func(k any, e *entry) bool {
+		key, ok := typedKey[K](k)
+		if !ok {
+			return true
+		}
+		stored, _ := e.get()
+		value, ok := sn.bytesMode.serve(stored).(V)
+		if !ok {
+			return true
+		}
+		return fn(key, value)
+	})
+}
+
+// rangeLive calls fn for every entry not expired when the snapshot was
+// taken, until fn returns false.
+func (sn *Snapshot[K, V]) rangeLive(fn func(key any, e *entry) bool) {
+	if sn.entries == nil {
+		return
+	}
+	rangeEntries(sn.entries, func(key any, e *entry) bool {
+		if e.expired(sn.at) {
+			return true
+		}
+		return fn(key, e)
+	})
+}
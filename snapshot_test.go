@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SnapshotTestSuite struct {
+	suite.Suite
+	cache *Cache[string, int]
+}
+
+func TestSnapshotSuite(t *testing.T) {
+	suite.Run(t, new(SnapshotTestSuite))
+}
+
+// SetupTest runs before each test
+func (s *SnapshotTestSuite) SetupTest() {
+	s.cache = New[string, int]()
+}
+
+// TestSnapshotIsIsolatedFromLaterWrites verifies copy-on-write semantics
+func (s *SnapshotTestSuite) TestSnapshotIsIsolatedFromLaterWrites() {
+	s.cache.Set("a", 1)
+	s.cache.Set("b", 2)
+
+	snap := s.cache.Snapshot()
+
+	// Modify the cache in every possible way
+	s.cache.Set("a", 10)
+	s.cache.Set("c", 3)
+	s.cache.Delete("b")
+	s.NoError(s.cache.Txn(func(tx *Tx[string, int]) error {
+		tx.Set("d", 4)
+		return nil
+	}))
+
+	s.Equal(2, snap.Len())
+	a, found := snap.Get("a")
+	s.True(found)
+	s.Equal(1, a)
+	b, found := snap.Get("b")
+	s.True(found)
+	s.Equal(2, b)
+	_, found = snap.Get("c")
+	s.False(found)
+
+	// The cache itself sees the new state
+	a, _ = s.cache.Peek("a")
+	s.Equal(10, a)
+	_, found = s.cache.Peek("b")
+	s.False(found)
+}
+
+// TestSnapshotSurvivesClear verifies that Clear doesn't affect a snapshot
+func (s *SnapshotTestSuite) TestSnapshotSurvivesClear() {
+	s.cache.Set("a", 1)
+	snap := s.cache.Snapshot()
+	s.cache.Clear()
+
+	a, found := snap.Get("a")
+	s.True(found)
+	s.Equal(1, a)
+}
+
+// TestSnapshotRange verifies iteration over a snapshot
+func (s *SnapshotTestSuite) TestSnapshotRange() {
+	s.cache.Set("a", 1)
+	s.cache.Set("b", 2)
+	s.cache.Set("c", 3)
+
+	snap := s.cache.Snapshot()
+	sum := 0
+	snap.Range(func(key string, value int) bool {
+		sum += value
+		return true
+	})
+	s.Equal(6, sum)
+
+	visited := 0
+	snap.Range(func(key string, value int) bool {
+		visited++
+		return false
+	})
+	s.Equal(1, visited, "Range should stop when fn returns false")
+}
+
+// TestSnapshotOfEmptyCache verifies snapshots of an empty cache
+func (s *SnapshotTestSuite) TestSnapshotOfEmptyCache() {
+	snap := s.cache.Snapshot()
+	s.cache.Set("a", 1)
+
+	s.Equal(0, snap.Len())
+	_, found := snap.Get("a")
+	s.False(found)
+}
+
+// TestConsecutiveSnapshotsShareUnchangedData verifies that no copy happens without writes
+func (s *SnapshotTestSuite) TestConsecutiveSnapshotsShareUnchangedData() {
+	s.cache.Set("a", 1)
+	first := s.cache.Snapshot()
+	second := s.cache.Snapshot()
+	s.Equal(first.Len(), second.Len())
+
+	s.cache.Set("a", 2)
+	third := s.cache.Snapshot()
+
+	a, _ := second.Get("a")
+	s.Equal(1, a)
+	a, _ = third.Get("a")
+	s.Equal(2, a)
+}
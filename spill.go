@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"encoding"
+	"encoding/json"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// SpilloverConfig configures WithSpillover.
+type SpilloverConfig struct {
+	// Threshold is the size in bytes above which values are spilled to
+	// disk, as estimated by WithSizeEstimator or by reflection.
+	Threshold int64
+	// Dir is the directory holding spilled values. If empty, a temporary
+	// directory is created, and removed by Shutdown.
+	Dir string
+	// MaxBytes bounds the disk space used by spilled values; the least
+	// recently used spilled entries are evicted beyond it. Zero means
+	// unbounded.
+	MaxBytes int64
+}
+
+// WithSpillover stores values larger than cfg.Threshold on local disk,
+// encoded with the codec of their type, and keeps only a handle to the file
+// in memory, so a few giant values do not evict everything else. Get reads
+// spilled values back transparently, decoding a fresh copy every time;
+// values that fail to encode stay in memory and files that cannot be read
+// back are misses. Spilled entries do not count towards WithMaxBytes.
+//
+// A spilled value is only as complete as its encoding. With JSONCodec,
+// the default, values of types that JSON cannot reproduce stay in memory:
+// those with unexported or ignored struct fields, interface values, or
+// parts without a JSON form, unless they implement json.Marshaler and
+// json.Unmarshaler. Other codecs are trusted to round-trip the values of
+// their type; set one with WithCodec or Configure to spill such types.
+//
+// Shutdown removes the spilled entries and their files. Snapshots do not
+// keep spilled values alive.
+func WithSpillover(cfg SpilloverConfig) Option {
+	return func(o *options) {
+		o.spillover = &cfg
+	}
+}
+
+// spiller writes the large values of a store to disk.
+type spiller struct {
+	SpilloverConfig
+	// owned is set when the spiller created Dir itself
+	owned bool
+	// bytes is the size of the spilled files and entries the spilled
+	// entries by key, both guarded by the store's mu
+	bytes   int64
+	entries map[entryKey]*entry
+}
+
+// spilledValue is the handle of a value stored on disk.
+type spilledValue struct {
+	path      string
+	size      int64
+	valueType reflect.Type
+	codec     Codec
+}
+
+func newSpiller(cfg SpilloverConfig) *spiller {
+	sp := &spiller{SpilloverConfig: cfg, entries: make(map[entryKey]*entry)}
+	if sp.Dir == "" {
+		if dir, err := os.MkdirTemp("", "cache-spill-"); err == nil {
+			sp.Dir, sp.owned = dir, true
+		}
+	}
+	return sp
+}
+
+// spill writes value, of type valueType, to disk if it is larger than the
+// threshold and moves e to the file.
+func (s *store) spill(valueType reflect.Type, e *entry, value any) {
+	sp := s.spiller
+	if sp == nil || sp.Dir == "" {
+		return
+	}
+	size := e.size
+	if s.cfg().sizeOf == nil {
+		size = estimateSize(value)
+	}
+	if size <= sp.Threshold {
+		return
+	}
+	codec := s.codecFor(valueType)
+	if _, isJSON := codec.(JSONCodec); isJSON && !jsonRoundTrips(valueType) {
+		return
+	}
+	data, err := codec.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(sp.Dir, 0o700); err != nil {
+		return
+	}
+	f, err := os.CreateTemp(sp.Dir, "entry-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return
+	}
+	e.value, e.size = nil, 0
+	e.spill = &spilledValue{path: f.Name(), size: int64(len(data)), valueType: valueType, codec: codec}
+}
+
+// jsonTypes holds whether the types spilled so far round-trip through
+// JSON, by type.
+var jsonTypes sync.Map
+
+var (
+	jsonMarshaler   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshaler   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// jsonRoundTrips reports whether values of t decode from their JSON
+// encoding into equal values.
+func jsonRoundTrips(t reflect.Type) bool {
+	if ok, found := jsonTypes.Load(t); found {
+		return ok.(bool)
+	}
+	ok := roundTrips(t, make(map[reflect.Type]bool))
+	jsonTypes.Store(t, ok)
+	return ok
+}
+
+// roundTrips is jsonRoundTrips for a type met inside the types in seen,
+// which are assumed to round-trip.
+func roundTrips(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return true
+	}
+	seen[t] = true
+	ptr := reflect.PointerTo(t)
+	if (t.Implements(jsonMarshaler) || ptr.Implements(jsonMarshaler)) && ptr.Implements(jsonUnmarshaler) {
+		return true
+	}
+	if (t.Implements(textMarshaler) || ptr.Implements(textMarshaler)) && ptr.Implements(textUnmarshaler) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return roundTrips(t.Elem(), seen)
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			key := reflect.PointerTo(t.Key())
+			if !key.Implements(textUnmarshaler) {
+				return false
+			}
+		}
+		return roundTrips(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Tag.Get("json") == "-" {
+				return false
+			}
+			if !field.IsExported() && (!field.Anonymous || field.Type.Kind() != reflect.Struct) {
+				// Only the fields of embedded structs are encoded
+				return false
+			}
+			if !roundTrips(field.Type, seen) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// load reads the spilled value back and reports whether it could.
+func (v *spilledValue) load() (any, bool) {
+	data, err := os.ReadFile(v.path)
+	if err != nil {
+		return nil, false
+	}
+	ptr := reflect.New(v.valueType)
+	if err := v.codec.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, false
+	}
+	return ptr.Elem().Interface(), true
+}
+
+// addSpilledLocked starts tracking e, stored for k, if it is spilled and
+// evicts spilled entries beyond the disk budget. Must be called with s.mu
+// held for writing.
+func (s *store) addSpilledLocked(k entryKey, e *entry) {
+	if e.spill == nil || s.spiller == nil {
+		return
+	}
+	sp := s.spiller
+	sp.entries[k] = e
+	sp.bytes += e.spill.size
+	for sp.MaxBytes > 0 && sp.bytes > sp.MaxBytes {
+		victim, ok := s.spilledVictimLocked(k)
+		if !ok {
+			// k alone exceeds the budget
+			victim = k
+		}
+		if _, removed := s.removeLocked(victim.valueType, victim.key, RemovalCapacity); !removed {
+			return
+		}
+		s.evictions.Add(1)
+		s.cfg().metrics.Eviction(s.typeName(victim.valueType))
+	}
+}
+
+// spilledVictimLocked picks the least recently used spilled entry among a
+// sample, other than keep. Must be called with s.mu held.
+func (s *store) spilledVictimLocked(keep entryKey) (entryKey, bool) {
+	var victim entryKey
+	var oldest uint64
+	found := false
+	sampled := 0
+	for k, e := range s.spiller.entries {
+		if k == keep || s.leasedLocked(k) {
+			continue
+		}
+		if access := e.lastAccess.Load(); !found || access < oldest {
+			victim, oldest, found = k, access, true
+		}
+		if sampled++; sampled >= evictionSamples {
+			break
+		}
+	}
+	return victim, found
+}
+
+// unspillLocked deletes the file of e, stored for k, if it is spilled.
+// Must be called with s.mu held for writing.
+func (s *store) unspillLocked(k entryKey, e *entry) {
+	if e.spill == nil || s.spiller == nil {
+		return
+	}
+	if s.spiller.entries[k] == e {
+		delete(s.spiller.entries, k)
+		s.spiller.bytes -= e.spill.size
+	}
+	_ = os.Remove(e.spill.path)
+}
+
+// dropSpilled removes every spilled entry and, if the spiller created it,
+// its directory.
+func (s *store) dropSpilled() error {
+	if s.spiller == nil {
+		return nil
+	}
+	s.lock()
+	for k := range s.spiller.entries {
+		s.removeLocked(k.valueType, k.key, RemovalShutdown)
+	}
+	s.mu.Unlock()
+	if s.spiller.owned {
+		return os.RemoveAll(s.spiller.Dir)
+	}
+	return nil
+}
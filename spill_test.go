@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SpillTestSuite struct {
+	suite.Suite
+}
+
+func TestSpillSuite(t *testing.T) {
+	suite.Run(t, new(SpillTestSuite))
+}
+
+// files returns the number of files in dir.
+func (s *SpillTestSuite) files(dir string) int {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	s.Require().NoError(err)
+	return len(entries)
+}
+
+// TestLargeValuesAreSpilled verifies that values above the threshold live
+// on disk and are read back transparently
+func (s *SpillTestSuite) TestLargeValuesAreSpilled() {
+	dir := s.T().TempDir()
+	c := New[string, string](WithSpillover(SpilloverConfig{Threshold: 1024, Dir: dir}))
+	large := strings.Repeat("x", 4096)
+	c.Set("large", large)
+	c.Set("small", "tiny")
+	s.Equal(1, s.files(dir))
+
+	value, err := c.Get("large")
+	s.NoError(err)
+	s.Equal(large, value)
+	value, err = c.Get("small")
+	s.NoError(err)
+	s.Equal("tiny", value)
+	s.Equal(2, c.Stats().Entries)
+
+	c.Set("large", "replaced")
+	s.Equal(0, s.files(dir), "Replaced values leave no file behind")
+	c.Set("large", large)
+	c.Delete("large")
+	s.Equal(0, s.files(dir), "Deleted values leave no file behind")
+}
+
+// TestSpilledValuesDoNotEvictOthers verifies that spilled values are kept
+// out of the memory budget
+func (s *SpillTestSuite) TestSpilledValuesDoNotEvictOthers() {
+	c := New[int, string](
+		WithMaxBytes(10_000),
+		WithSpillover(SpilloverConfig{Threshold: 1024, Dir: s.T().TempDir()}),
+	)
+	for i := 0; i < 10; i++ {
+		c.Set(i, strings.Repeat("x", 4096))
+	}
+	s.Equal(10, c.Stats().Entries)
+	s.Equal(uint64(0), c.Stats().Evictions)
+}
+
+// TestDiskBudgetEvictsLeastRecentlyUsed verifies that spilled entries are
+// evicted in LRU order beyond MaxBytes
+func (s *SpillTestSuite) TestDiskBudgetEvictsLeastRecentlyUsed() {
+	dir := s.T().TempDir()
+	c := New[string, string](WithSpillover(SpilloverConfig{Threshold: 1024, Dir: dir, MaxBytes: 10_000}))
+	large := strings.Repeat("x", 4096)
+	c.Set("a", large)
+	c.Set("b", large)
+	_, err := c.Get("a")
+	s.NoError(err)
+	c.Set("c", large)
+
+	_, found := c.Peek("b")
+	s.False(found, "The least recently used spilled entry should be evicted")
+	_, found = c.Peek("a")
+	s.True(found)
+	s.Equal(2, s.files(dir))
+	s.Equal(uint64(1), c.Stats().Evictions)
+}
+
+// TestMissingFilesAreMisses verifies that a spilled value that cannot be
+// read back is reported as missing
+func (s *SpillTestSuite) TestMissingFilesAreMisses() {
+	dir := s.T().TempDir()
+	c := New[string, string](WithSpillover(SpilloverConfig{Threshold: 1024, Dir: dir}))
+	c.Set("large", strings.Repeat("x", 4096))
+	s.Require().NoError(os.RemoveAll(dir))
+
+	_, err := c.Get("large")
+	s.ErrorIs(err, ErrNotCached)
+}
+
+// TestShutdownRemovesTemporaryDirectory verifies that Shutdown cleans up
+// the directory the cache created
+func (s *SpillTestSuite) TestShutdownRemovesTemporaryDirectory() {
+	c := New[string, string](WithSpillover(SpilloverConfig{Threshold: 1024}))
+	c.Set("large", strings.Repeat("x", 4096))
+	dir := c.s.spiller.Dir
+	s.Equal(1, s.files(dir))
+
+	s.NoError(c.Shutdown(context.Background()))
+	_, err := os.Stat(dir)
+	s.True(os.IsNotExist(err))
+	_, found := c.Peek("large")
+	s.False(found)
+}
+
+// record is a value whose JSON encoding loses its unexported field.
+type record struct {
+	Name  string
+	notes string
+}
+
+// TestIncompleteEncodingsStayInMemory verifies that values JSON cannot
+// reproduce are not spilled with the default codec
+func (s *SpillTestSuite) TestIncompleteEncodingsStayInMemory() {
+	dir := s.T().TempDir()
+	c := New[string, record](WithSpillover(SpilloverConfig{Threshold: 1024, Dir: dir}))
+	large := record{Name: "large", notes: strings.Repeat("x", 4096)}
+	c.Set("large", large)
+	s.Equal(0, s.files(dir))
+
+	value, err := c.Get("large")
+	s.NoError(err)
+	s.Equal(large, value)
+}
+
+// TestJSONRoundTrips verifies which types are found to survive JSON
+func (s *SpillTestSuite) TestJSONRoundTrips() {
+	type tree struct {
+		Children []*tree
+		Labels   map[string]time.Time
+	}
+	type embedded struct {
+		record
+	}
+	type ignored struct {
+		Secret string `json:"-"`
+	}
+	for _, value := range []any{"", 1.5, []byte{}, map[int][]string{}, time.Time{}, tree{}, struct{ A [2]*int }{}} {
+		s.True(jsonRoundTrips(reflect.TypeOf(value)), "%T", value)
+	}
+	for _, value := range []any{record{}, embedded{}, ignored{}, []any{}, map[string]any{}, complex(1, 1), struct{ F func() }{}} {
+		s.False(jsonRoundTrips(reflect.TypeOf(value)), "%T", value)
+	}
+}
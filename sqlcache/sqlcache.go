@@ -0,0 +1,237 @@
+// Package sqlcache caches the results of database/sql queries.
+//
+// Results are materialized into values with a scanner callback and cached
+// by query and arguments. Writes go to the database directly; call one of
+// the Invalidate methods afterwards so readers see them:
+//
+//	db := sqlcache.New(sqlDB, time.Minute)
+//	users, err := sqlcache.Query(ctx, db, scanUser, "SELECT id, name FROM users WHERE team = ?", team)
+//	...
+//	_, err = db.DB().ExecContext(ctx, "UPDATE users SET name = ? WHERE id = ?", name, id)
+//	db.InvalidateQuery("SELECT id, name FROM users WHERE team = ?")
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alexanderbotero/cache"
+	"golang.org/x/sync/singleflight"
+)
+
+// queryKey identifies the result of a query run with some arguments.
+type queryKey struct {
+	query string
+	args  string
+}
+
+// DB wraps a *sql.DB with a cache of query results. It is safe for
+// concurrent use.
+type DB struct {
+	db      *sql.DB
+	results *cache.Cache[queryKey, any]
+	group   singleflight.Group
+	// generation is bumped by every invalidation so queries running
+	// meanwhile do not cache outdated results
+	generation atomic.Uint64
+	// mu makes invalidations and the caching of query results mutually
+	// exclusive, so that a result is never cached between the bump of
+	// generation and the removal of the results it invalidates
+	mu sync.Mutex
+}
+
+// New returns a DB caching the results of queries run on db for ttl. opts
+// configure the underlying cache, for instance cache.WithMaxEntries; the
+// ttl always applies.
+func New(db *sql.DB, ttl time.Duration, opts ...cache.Option) *DB {
+	opts = append(append([]cache.Option(nil), opts...), cache.WithTTL(ttl))
+	return &DB{
+		db:      db,
+		results: cache.New[queryKey, any](opts...),
+	}
+}
+
+// DB returns the wrapped database, for writes and uncached queries.
+func (db *DB) DB() *sql.DB {
+	return db.db
+}
+
+// Query returns the rows of query run with args, each converted with
+// scan. A cached result is returned if present; otherwise the query runs
+// once for all concurrent callers and its result is cached. The shared
+// query carries the values of the context of the caller that started it
+// but not its cancellation, so that one caller giving up does not fail the
+// others; each caller's ctx only bounds its own wait. The returned slice
+// is shared with other callers and must not be modified.
+//
+// Results are cached by query and the type and value of each argument,
+// pointers and driver.Valuers being resolved to the values they stand for,
+// so the same query must always be scanned into the same T. Queries with
+// a driver.Valuer argument whose Value fails run uncached.
+func Query[T any](ctx context.Context, db *DB, scan func(*sql.Rows) (T, error), query string, args ...any) ([]T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	encoded, ok := encodeArgs(args)
+	if !ok {
+		return run(ctx, db.db, scan, query, args)
+	}
+	key := queryKey{query: query, args: encoded}
+	if cached, err := db.results.Get(key); err == nil {
+		if result, ok := cached.([]T); ok {
+			return result, nil
+		}
+	}
+
+	ch := db.group.DoChan(key.query+"\x00"+key.args, func() (any, error) {
+		generation := db.generation.Load()
+		result, err := run(detached{ctx}, db.db, scan, query, args)
+		if err != nil {
+			return nil, err
+		}
+		db.mu.Lock()
+		if db.generation.Load() == generation {
+			db.results.Set(key, result)
+		}
+		db.mu.Unlock()
+		return result, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		result, ok := res.Val.([]T)
+		if !ok {
+			return nil, fmt.Errorf("sqlcache: %q was scanned into %T by a concurrent caller, not %T", query, res.Val, result)
+		}
+		return result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// detached is a context with the values of its parent but without its
+// deadline and cancellation.
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+func (d detached) Value(key any) any         { return d.parent.Value(key) }
+
+// run executes query and scans every row.
+func run[T any](ctx context.Context, db *sql.DB, scan func(*sql.Rows) (T, error), query string, args []any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []T
+	for rows.Next() {
+		value, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Invalidate removes the cached result of query run with args.
+func (db *DB) Invalidate(query string, args ...any) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.generation.Add(1)
+	if encoded, ok := encodeArgs(args); ok {
+		db.results.Delete(queryKey{query: query, args: encoded})
+	}
+}
+
+// InvalidateQuery removes the cached results of query, whatever its
+// arguments. It inspects every cached result.
+func (db *DB) InvalidateQuery(query string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.generation.Add(1)
+	var stale []queryKey
+	db.results.Snapshot().Range(func(key queryKey, _ any) bool {
+		if key.query == query {
+			stale = append(stale, key)
+		}
+		return true
+	})
+	for _, key := range stale {
+		db.results.Delete(key)
+	}
+}
+
+// InvalidateAll removes every cached result.
+func (db *DB) InvalidateAll() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.generation.Add(1)
+	db.results.Clear()
+}
+
+// Stats returns a summary of the result cache.
+func (db *DB) Stats() cache.Statistics {
+	return db.results.Stats()
+}
+
+// encodeArgs renders query arguments as a cache key, keeping their types
+// apart so 1 and "1" differ. Like database/sql, it resolves driver.Valuers
+// and pointers to the values they stand for, so that arguments key by
+// content rather than by address. It reports false if a Valuer fails.
+func encodeArgs(args []any) (string, bool) {
+	var b strings.Builder
+	for _, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			fmt.Fprintf(&b, "@%s=", named.Name)
+			arg = named.Value
+		}
+		arg, ok := resolveArg(arg)
+		if !ok {
+			return "", false
+		}
+		fmt.Fprintf(&b, "%T:%#v\x00", arg, arg)
+	}
+	return b.String(), true
+}
+
+// resolveArg returns the value arg stands for: the result of Value for a
+// driver.Valuer and the pointee of a pointer, nil for a nil pointer.
+func resolveArg(arg any) (any, bool) {
+	for {
+		if valuer, ok := arg.(driver.Valuer); ok {
+			if v := reflect.ValueOf(valuer); v.Kind() == reflect.Pointer && v.IsNil() {
+				return nil, true
+			}
+			value, err := valuer.Value()
+			if err != nil {
+				return nil, false
+			}
+			return value, true
+		}
+		v := reflect.ValueOf(arg)
+		if v.Kind() != reflect.Pointer {
+			return arg, true
+		}
+		if v.IsNil() {
+			return nil, true
+		}
+		arg = v.Elem().Interface()
+	}
+}
@@ -0,0 +1,217 @@
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// queries counts the queries reaching the test driver.
+var queries atomic.Int32
+
+// release, when set, holds queries whose first argument is "slow" until it
+// is closed.
+var release chan struct{}
+
+func init() {
+	sql.Register("sqlcache-test", testDriver{})
+}
+
+// testDriver answers every query with one row per argument, holding the
+// argument.
+type testDriver struct{}
+
+func (testDriver) Open(string) (driver.Conn, error) { return testConn{}, nil }
+
+type testConn struct{}
+
+func (testConn) Prepare(query string) (driver.Stmt, error) { return testStmt{}, nil }
+func (testConn) Close() error                              { return nil }
+func (testConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type testStmt struct{}
+
+func (testStmt) Close() error                               { return nil }
+func (testStmt) NumInput() int                              { return -1 }
+func (testStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+
+func (testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	queries.Add(1)
+	if len(args) > 0 && args[0] == "slow" {
+		<-release
+	}
+	return &testRows{values: args}, nil
+}
+
+type testRows struct {
+	values []driver.Value
+}
+
+func (r *testRows) Columns() []string { return []string{"value"} }
+func (r *testRows) Close() error      { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+func scanString(rows *sql.Rows) (string, error) {
+	var v string
+	err := rows.Scan(&v)
+	return v, err
+}
+
+func scanInt(rows *sql.Rows) (int64, error) {
+	var v int64
+	err := rows.Scan(&v)
+	return v, err
+}
+
+type SQLCacheTestSuite struct {
+	suite.Suite
+	db *DB
+}
+
+func TestSQLCacheSuite(t *testing.T) {
+	suite.Run(t, new(SQLCacheTestSuite))
+}
+
+func (s *SQLCacheTestSuite) SetupTest() {
+	sqlDB, err := sql.Open("sqlcache-test", "")
+	s.Require().NoError(err)
+	s.db = New(sqlDB, time.Minute)
+	queries.Store(0)
+}
+
+func (s *SQLCacheTestSuite) TearDownTest() {
+	s.NoError(s.db.DB().Close())
+}
+
+func (s *SQLCacheTestSuite) query(args ...any) []int64 {
+	result, err := Query(context.Background(), s.db, scanInt, "SELECT", args...)
+	s.Require().NoError(err)
+	return result
+}
+
+// TestResultsAreCached verifies that results are cached per arguments
+func (s *SQLCacheTestSuite) TestResultsAreCached() {
+	s.Equal([]int64{1, 2}, s.query(1, 2))
+	s.Equal([]int64{1, 2}, s.query(1, 2))
+	s.Equal([]int64{3}, s.query(3))
+
+	s.Equal(int32(2), queries.Load())
+	s.Equal(uint64(1), s.db.Stats().Hits)
+}
+
+// TestInvalidate verifies that invalidated results are queried again
+func (s *SQLCacheTestSuite) TestInvalidate() {
+	s.query(1)
+	s.query(2)
+	s.db.Invalidate("SELECT", 1)
+	s.query(1)
+	s.query(2)
+	s.Equal(int32(3), queries.Load())
+
+	s.db.InvalidateQuery("SELECT")
+	s.query(1)
+	s.query(2)
+	s.Equal(int32(5), queries.Load())
+
+	s.db.InvalidateAll()
+	s.query(1)
+	s.Equal(int32(6), queries.Load())
+}
+
+// TestArgumentTypes verifies that arguments of different types do not
+// share results
+func (s *SQLCacheTestSuite) TestArgumentTypes() {
+	s.query(int64(1))
+	s.query("1")
+	s.Equal(int32(2), queries.Load())
+}
+
+// TestCancelledContext verifies that a done context fails a query
+func (s *SQLCacheTestSuite) TestCancelledContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Query(ctx, s.db, scanInt, "SELECT", 1)
+	s.ErrorIs(err, context.Canceled)
+}
+
+// TestCancelledLeader verifies that the caller starting a shared query
+// cancelling does not fail the callers waiting for it
+func (s *SQLCacheTestSuite) TestCancelledLeader() {
+	release = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := Query(ctx, s.db, scanString, "SELECT", "slow")
+		leader <- err
+	}()
+	s.Eventually(func() bool { return queries.Load() == 1 }, time.Second, time.Millisecond)
+
+	follower := make(chan error, 1)
+	go func() {
+		result, err := Query(context.Background(), s.db, scanString, "SELECT", "slow")
+		s.Equal([]string{"slow"}, result)
+		follower <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	s.ErrorIs(<-leader, context.Canceled)
+
+	close(release)
+	s.NoError(<-follower)
+	s.Equal(int32(1), queries.Load())
+}
+
+// TestPointerArguments verifies that pointer arguments are keyed by the
+// values they point to
+func (s *SQLCacheTestSuite) TestPointerArguments() {
+	a, b := int64(1), int64(1)
+	s.Equal([]int64{1}, s.query(&a))
+	s.Equal([]int64{1}, s.query(&b))
+	s.Equal(int32(1), queries.Load())
+
+	a = 2
+	s.Equal([]int64{2}, s.query(&a))
+	s.Equal(int32(2), queries.Load())
+}
+
+// TestValuerArguments verifies that driver.Valuer arguments are keyed by
+// their values
+func (s *SQLCacheTestSuite) TestValuerArguments() {
+	s.Equal([]int64{1}, s.query(sql.NullInt64{Int64: 1, Valid: true}))
+	s.Equal([]int64{1}, s.query(&sql.NullInt64{Int64: 1, Valid: true}))
+	s.Equal([]int64{1}, s.query(int64(1)))
+	s.Equal(int32(1), queries.Load())
+}
+
+// TestInvalidateDuringQuery verifies that a result fetched before an
+// invalidation is not cached
+func (s *SQLCacheTestSuite) TestInvalidateDuringQuery() {
+	release = make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := Query(context.Background(), s.db, scanString, "SELECT", "slow")
+		s.NoError(err)
+	}()
+	s.Eventually(func() bool { return queries.Load() == 1 }, time.Second, time.Millisecond)
+	s.db.Invalidate("SELECT", "slow")
+	close(release)
+	<-done
+
+	_, err := Query(context.Background(), s.db, scanString, "SELECT", "slow")
+	s.NoError(err)
+	s.Equal(int32(2), queries.Load())
+}
@@ -0,0 +1,323 @@
+package cache
+
+import (
+	"fmt"
+	"math/bits"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// Statistics is a point-in-time summary of a cache.
+type Statistics struct {
+	// Hits counts Get calls served from the cache.
+	Hits uint64
+	// Misses counts Get calls that had to go through a getter.
+	Misses uint64
+	// Evictions counts entries removed to respect capacity limits.
+	Evictions uint64
+	// Removals counts the entries that left the cache by reason, telling
+	// TTL churn from capacity pressure; it is nil while none did.
+	Removals map[RemovalReason]uint64
+	// Rejections counts new keys the admission policy kept out of a full
+	// cache.
+	Rejections uint64
+	// InFlight is the number of getter and loader calls running.
+	InFlight int64
+	// Coalesced counts Get calls served by a load started by a concurrent
+	// call for the same key, each of which would otherwise have called
+	// the getter itself.
+	Coalesced uint64
+	// RemoteHits counts the loads served by the backing store. With Hits
+	// and OriginLoads, it tells which tier serves the lookups.
+	RemoteHits uint64
+	// OriginLoads counts getter and loader calls, which reach the origin.
+	OriginLoads uint64
+	// ShadowReads counts the hits compared with the origin by
+	// WithShadowReads, and ShadowDivergences those whose cached value
+	// differed.
+	ShadowReads       uint64
+	ShadowDivergences uint64
+	// Entries is the number of live cached entries, including nil entries.
+	Entries int
+	// NilEntries is the number of entries holding a cached nil.
+	NilEntries int
+	// Bytes is the estimated size of the cached values. It is only tracked
+	// when a byte limit or a size estimator is configured, and for []byte
+	// values held in a bytes mode other than BytesAsIs.
+	Bytes int64
+	// HotFraction is the share of entries the hot segment of the
+	// segmented LRU may hold, as tuned by WithAdaptiveSegments; zero
+	// without segmentation.
+	HotFraction float64
+	// Frequency describes the access frequency tracker.
+	Frequency FrequencyStats
+	// TTL is the distribution of the remaining time to live of the live
+	// entries, showing whether the cache is mostly fresh or about to
+	// expire en masse.
+	TTL TTLDistribution
+	// Internals describes the cache's own machinery, for capacity
+	// planning.
+	Internals InternalStats
+	// Types breaks the statistics down by value type, keyed by type name;
+	// it is nil while no type has been used. Types sharing a name, as
+	// given by WithTypeNamer, are summed up under it.
+	// The package-level cache multiplexes unrelated caches, whose problems
+	// aggregate numbers hide.
+	Types map[string]TypeStatistics
+}
+
+// TypeStatistics summarizes the entries of a single value type.
+type TypeStatistics struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+	// Bytes is the estimated size of the type's values, if tracked.
+	Bytes int64
+	// Loads describes the latency of the getter calls for the type.
+	Loads LatencyStats
+	// HotKeys lists the most frequently accessed keys of the type, most
+	// accessed first, if frequency tracking is enabled.
+	HotKeys []string
+}
+
+// LatencyStats summarizes a latency distribution. Percentiles are
+// approximate: they are the upper bounds of power-of-two buckets.
+type LatencyStats struct {
+	Count uint64
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+}
+
+// ttlBounds are the upper bounds of the buckets of a TTLDistribution.
+var ttlBounds = [...]time.Duration{
+	time.Second,
+	10 * time.Second,
+	time.Minute,
+	10 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+}
+
+// TTLBucketBounds returns the upper bounds of the buckets of
+// TTLDistribution.Expiring, in increasing order.
+func TTLBucketBounds() []time.Duration {
+	return append([]time.Duration(nil), ttlBounds[:]...)
+}
+
+// TTLDistribution counts live entries by remaining time to live.
+type TTLDistribution struct {
+	// Expiring[i] counts the entries expiring within TTLBucketBounds()[i],
+	// but not within the previous bound.
+	Expiring [len(ttlBounds)]int
+	// Later counts the entries expiring after the last bound.
+	Later int
+	// NoExpiry counts the entries that never expire.
+	NoExpiry int
+}
+
+// add counts an entry expiring at expiresAt.
+func (d *TTLDistribution) add(expiresAt, now time.Time) {
+	if expiresAt.IsZero() {
+		d.NoExpiry++
+		return
+	}
+	remaining := expiresAt.Sub(now)
+	for i, bound := range ttlBounds {
+		if remaining <= bound {
+			d.Expiring[i]++
+			return
+		}
+	}
+	d.Later++
+}
+
+// typeCounters holds the running counters of a value type.
+type typeCounters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	loads  latencyHistogram
+}
+
+// latencyBuckets is the number of buckets of a latencyHistogram. Bucket i
+// counts durations of up to 2^i microseconds, the last one everything
+// longer.
+const latencyBuckets = 28
+
+// latencyHistogram is a lock-free histogram of durations with
+// power-of-two buckets.
+type latencyHistogram struct {
+	buckets [latencyBuckets]atomic.Uint64
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	i := 0
+	if us := d.Microseconds(); us > 1 {
+		i = bits.Len64(uint64(us - 1))
+	}
+	if i >= latencyBuckets {
+		i = latencyBuckets - 1
+	}
+	h.buckets[i].Add(1)
+}
+
+// addTo adds the durations counted by h to dst.
+func (h *latencyHistogram) addTo(dst *latencyHistogram) {
+	for i := range h.buckets {
+		dst.buckets[i].Add(h.buckets[i].Load())
+	}
+}
+
+func (h *latencyHistogram) stats() LatencyStats {
+	var counts [latencyBuckets]uint64
+	var st LatencyStats
+	for i := range h.buckets {
+		counts[i] = h.buckets[i].Load()
+		st.Count += counts[i]
+	}
+	if st.Count == 0 {
+		return st
+	}
+	percentile := func(p uint64) time.Duration {
+		rank := (st.Count*p + 99) / 100
+		var seen uint64
+		for i, n := range counts {
+			seen += n
+			if seen >= rank {
+				return time.Duration(1<<i) * time.Microsecond
+			}
+		}
+		return time.Duration(1<<(latencyBuckets-1)) * time.Microsecond
+	}
+	st.P50, st.P90, st.P99 = percentile(50), percentile(90), percentile(99)
+	return st
+}
+
+// countersFor returns the running counters of valueType.
+func (s *store) countersFor(valueType reflect.Type) *typeCounters {
+	if c, ok := s.typeCounters.Load(valueType); ok {
+		return c.(*typeCounters)
+	}
+	c, _ := s.typeCounters.LoadOrStore(valueType, new(typeCounters))
+	return c.(*typeCounters)
+}
+
+// recordLoad counts a getter call for valueType that took d.
+func (s *store) recordLoad(valueType reflect.Type, d time.Duration, err error) {
+	s.originLoads.Add(1)
+	s.cfg().metrics.Load(s.typeName(valueType), d, err)
+	s.countersFor(valueType).loads.observe(d)
+}
+
+// resetCounters forgets the running counters of every type.
+func (s *store) resetCounters() {
+	s.typeCounters.Range(func(valueType, _ any) bool {
+		s.typeCounters.Delete(valueType)
+		return true
+	})
+}
+
+// Stats returns a summary of the package-level cache.
+func Stats() Statistics {
+	return globalStore().stats()
+}
+
+func (s *store) stats() Statistics {
+	st := Statistics{
+		Hits:       s.hits.Load(),
+		Misses:     s.misses.Load(),
+		Evictions:  s.evictions.Load(),
+		Removals:   s.removalCounts(),
+		Rejections: s.rejections.Load(),
+		InFlight:   s.inFlight.Load(),
+		Coalesced:  s.coalesced.Load(),
+
+		RemoteHits:  s.remoteHits.Load(),
+		OriginLoads: s.originLoads.Load(),
+
+		ShadowReads:       s.shadowReads.Load(),
+		ShadowDivergences: s.shadowDivergences.Load(),
+
+		Internals: s.internals(),
+	}
+	if s.segmented() {
+		st.HotFraction = s.hotFraction()
+	}
+	if sk := s.sketch.Load(); sk != nil {
+		st.Frequency = sk.stats()
+	}
+
+	type typeTotals struct {
+		stats TypeStatistics
+		loads latencyHistogram
+	}
+	types := make(map[reflect.Type]*typeTotals)
+	typeStats := func(valueType reflect.Type) *typeTotals {
+		totals, ok := types[valueType]
+		if !ok {
+			totals = &typeTotals{}
+			types[valueType] = totals
+		}
+		return totals
+	}
+	s.typeCounters.Range(func(valueType, c any) bool {
+		counters := c.(*typeCounters)
+		totals := typeStats(valueType.(reflect.Type))
+		totals.stats.Hits = counters.hits.Load()
+		totals.stats.Misses = counters.misses.Load()
+		counters.loads.addTo(&totals.loads)
+		return true
+	})
+
+	s.rlock()
+	st.Bytes = s.bytes
+	now := s.now()
+	s.rangeAllLocked(func(valueType reflect.Type, _ any, e *entry) bool {
+		if !e.expired(now) {
+			st.Entries++
+			st.TTL.add(e.expiresAt, now)
+			if e.holdsNil() {
+				st.NilEntries++
+			}
+			ts := &typeStats(valueType).stats
+			ts.Entries++
+			ts.Bytes += e.size
+		}
+		return true
+	})
+	s.mu.RUnlock()
+
+	if len(types) == 0 {
+		return st
+	}
+	// Types sharing a name, which a type namer may give several of, are
+	// summed up under it
+	named := make(map[string]*typeTotals, len(types))
+	ranked := make(map[string][]rankedKey, len(types))
+	for valueType, totals := range types {
+		name := s.typeName(valueType)
+		sum, ok := named[name]
+		if !ok {
+			sum = &typeTotals{}
+			named[name] = sum
+		}
+		sum.stats.Hits += totals.stats.Hits
+		sum.stats.Misses += totals.stats.Misses
+		sum.stats.Entries += totals.stats.Entries
+		sum.stats.Bytes += totals.stats.Bytes
+		totals.loads.addTo(&sum.loads)
+		ranked[name] = append(ranked[name], s.rankedKeys(valueType)...)
+	}
+	st.Types = make(map[string]TypeStatistics, len(named))
+	for name, sum := range named {
+		ts := sum.stats
+		ts.Loads = sum.loads.stats()
+		for _, key := range topKeys(ranked[name], statsHotKeys) {
+			ts.HotKeys = append(ts.HotKeys, fmt.Sprint(s.redactKey(name, key)))
+		}
+		st.Types[name] = ts
+	}
+	return st
+}
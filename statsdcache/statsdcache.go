@@ -0,0 +1,237 @@
+// Package statsdcache sends cache metrics to a StatsD or DogStatsD agent.
+//
+// A Sink is a cache.MetricsSink buffering metrics in memory and pushing
+// them over the StatsD line protocol, so the hot path never waits for the
+// network:
+//
+//	sink, err := statsdcache.Dial("127.0.0.1:8125", statsdcache.Config{Prefix: "myapp.cache", DogStatsD: true})
+//	if err != nil {
+//		return err
+//	}
+//	defer sink.Close()
+//	cache.SetDefaults(cache.WithMetrics(sink))
+package statsdcache
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config configures a Sink.
+type Config struct {
+	// Prefix is prepended to every metric name, separated by a dot. The
+	// default is "cache".
+	Prefix string
+	// DogStatsD sends the value type as a "type" tag in the DogStatsD
+	// format; plain StatsD has no tags, so by default the value type is
+	// appended to metric names instead.
+	DogStatsD bool
+	// Tags are extra DogStatsD tags, such as "env:prod", sent with every
+	// metric. They are ignored unless DogStatsD is set.
+	Tags []string
+	// FlushInterval is how often buffered metrics are sent. The default
+	// is one second.
+	FlushInterval time.Duration
+	// MaxPacketSize is the largest payload sent at once; buffers reaching
+	// it are sent right away. The default of 1432 bytes fits in one
+	// Ethernet frame.
+	MaxPacketSize int
+}
+
+// Sink is a cache.MetricsSink, cache.CoalescingSink and cache.TieredSink
+// sending metrics in the StatsD line protocol:
+//
+//   - hit, miss, eviction and remote_hit counters
+//   - load and load_error timers in milliseconds
+//   - coalesced counters, counting the callers that shared a load beyond
+//     the one that started it
+//
+// It is safe for concurrent use. Metrics written while the destination is
+// unreachable are lost, as usual with StatsD.
+type Sink struct {
+	w       io.Writer
+	closer  io.Closer
+	prefix  string
+	tags    string
+	dog     bool
+	maxSize int
+
+	mu  sync.Mutex
+	buf []byte
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Dial returns a Sink sending metrics over UDP to the agent at addr, such
+// as "127.0.0.1:8125". Closing the Sink closes the connection.
+func Dial(addr string, cfg Config) (*Sink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := New(conn, cfg)
+	s.closer = conn
+	return s, nil
+}
+
+// New returns a Sink writing metrics to w, one payload per Write call. It
+// flushes every cfg.FlushInterval until it is closed.
+func New(w io.Writer, cfg Config) *Sink {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "cache"
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.MaxPacketSize <= 0 {
+		cfg.MaxPacketSize = 1432
+	}
+	s := &Sink{
+		w:       w,
+		prefix:  strings.TrimSuffix(cfg.Prefix, ".") + ".",
+		dog:     cfg.DogStatsD,
+		maxSize: cfg.MaxPacketSize,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if cfg.DogStatsD && len(cfg.Tags) > 0 {
+		s.tags = strings.Join(cfg.Tags, ",")
+	}
+	go s.flushLoop(cfg.FlushInterval)
+	return s
+}
+
+// Hit counts a lookup served from the cache.
+func (s *Sink) Hit(valueType string) { s.count("hit", valueType, 1) }
+
+// Miss counts a lookup not served from the cache.
+func (s *Sink) Miss(valueType string) { s.count("miss", valueType, 1) }
+
+// Eviction counts an entry evicted to respect a limit.
+func (s *Sink) Eviction(valueType string) { s.count("eviction", valueType, 1) }
+
+// RemoteHit counts a local miss served by the backing store.
+func (s *Sink) RemoteHit(valueType string) { s.count("remote_hit", valueType, 1) }
+
+// Coalesced counts the callers that shared a load without starting it.
+func (s *Sink) Coalesced(valueType string, callers int) {
+	if callers > 1 {
+		s.count("coalesced", valueType, callers-1)
+	}
+}
+
+// Load times a getter or loader call, as load_error if it failed.
+func (s *Sink) Load(valueType string, duration time.Duration, err error) {
+	name := "load"
+	if err != nil {
+		name = "load_error"
+	}
+	ms := strconv.FormatFloat(float64(duration)/float64(time.Millisecond), 'f', -1, 64)
+	s.write(name, valueType, ms, "ms")
+}
+
+// Flush sends the buffered metrics now.
+func (s *Sink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flushLocked()
+}
+
+// Close stops the periodic flushes, sends the buffered metrics and closes
+// the connection of a Sink returned by Dial. Metrics received afterwards
+// are buffered but never sent.
+func (s *Sink) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+	err := s.Flush()
+	if s.closer != nil {
+		if cerr := s.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func (s *Sink) count(name, valueType string, n int) {
+	s.write(name, valueType, strconv.Itoa(n), "c")
+}
+
+// write buffers one metric line, sending the buffer first if the line
+// would not fit in the packet.
+func (s *Sink) write(name, valueType, value, kind string) {
+	line := make([]byte, 0, 64)
+	line = append(line, s.prefix...)
+	line = append(line, name...)
+	if !s.dog {
+		line = append(line, '.')
+		line = appendSanitized(line, valueType)
+	}
+	line = append(line, ':')
+	line = append(line, value...)
+	line = append(line, '|')
+	line = append(line, kind...)
+	if s.dog {
+		line = append(line, "|#type:"...)
+		line = appendSanitized(line, valueType)
+		if s.tags != "" {
+			line = append(line, ',')
+			line = append(line, s.tags...)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buf) > 0 && len(s.buf)+1+len(line) > s.maxSize {
+		_ = s.flushLocked()
+	}
+	if len(s.buf) > 0 {
+		s.buf = append(s.buf, '\n')
+	}
+	s.buf = append(s.buf, line...)
+}
+
+// flushLocked sends the buffer; s.mu must be held. Write errors are
+// returned but the metrics are dropped either way.
+func (s *Sink) flushLocked() error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	_, err := s.w.Write(s.buf)
+	s.buf = s.buf[:0]
+	return err
+}
+
+func (s *Sink) flushLoop(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = s.Flush()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// appendSanitized appends valueType with the characters the StatsD
+// protocol reserves, and those awkward in metric names, replaced by
+// underscores.
+func appendSanitized(b []byte, valueType string) []byte {
+	for i := 0; i < len(valueType); i++ {
+		switch c := valueType[i]; c {
+		case ':', '|', '@', '#', ',', ' ', '\n', '/', '*', '[', ']':
+			b = append(b, '_')
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
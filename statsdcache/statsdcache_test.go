@@ -0,0 +1,124 @@
+package statsdcache
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexanderbotero/cache"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	_ cache.CoalescingSink = (*Sink)(nil)
+	_ cache.TieredSink     = (*Sink)(nil)
+)
+
+// payloads records the payloads written to it.
+type payloads struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (p *payloads) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, string(b))
+	return len(b), nil
+}
+
+func (p *payloads) lines() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var lines []string
+	for _, payload := range p.sent {
+		lines = append(lines, strings.Split(payload, "\n")...)
+	}
+	return lines
+}
+
+type StatsdCacheTestSuite struct {
+	suite.Suite
+}
+
+func TestStatsdCacheSuite(t *testing.T) {
+	suite.Run(t, new(StatsdCacheTestSuite))
+}
+
+// TestPlainFormat verifies that plain StatsD names carry the value type
+func (s *StatsdCacheTestSuite) TestPlainFormat() {
+	out := &payloads{}
+	sink := New(out, Config{Prefix: "app", FlushInterval: time.Hour})
+	sink.Hit("main.User")
+	sink.Miss("[]string")
+	sink.Load("main.User", 1500*time.Microsecond, nil)
+	sink.Load("main.User", time.Millisecond, errors.New("down"))
+	sink.Coalesced("main.User", 3)
+	sink.Coalesced("main.User", 1)
+	s.Require().NoError(sink.Close())
+
+	s.Equal([]string{
+		"app.hit.main.User:1|c",
+		"app.miss.__string:1|c",
+		"app.load.main.User:1.5|ms",
+		"app.load_error.main.User:1|ms",
+		"app.coalesced.main.User:2|c",
+	}, out.lines())
+}
+
+// TestDogStatsDFormat verifies that DogStatsD lines tag the value type
+// and the configured tags
+func (s *StatsdCacheTestSuite) TestDogStatsDFormat() {
+	out := &payloads{}
+	sink := New(out, Config{DogStatsD: true, Tags: []string{"env:test"}, FlushInterval: time.Hour})
+	sink.RemoteHit("main.User")
+	sink.Eviction("map[string]int")
+	s.Require().NoError(sink.Close())
+
+	s.Equal([]string{
+		"cache.remote_hit:1|c|#type:main.User,env:test",
+		"cache.eviction:1|c|#type:map_string_int,env:test",
+	}, out.lines())
+}
+
+// TestPacketSize verifies that payloads stay within MaxPacketSize and that
+// the periodic flush sends buffered metrics
+func (s *StatsdCacheTestSuite) TestPacketSize() {
+	out := &payloads{}
+	sink := New(out, Config{MaxPacketSize: 64, FlushInterval: 10 * time.Millisecond})
+	defer sink.Close()
+	for i := 0; i < 10; i++ {
+		sink.Hit("main.User")
+	}
+	s.Eventually(func() bool { return len(out.lines()) == 10 }, time.Second, time.Millisecond)
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	s.Greater(len(out.sent), 1)
+	for _, payload := range out.sent {
+		s.LessOrEqual(len(payload), 64)
+	}
+}
+
+// TestDial verifies that a dialed Sink sends metrics over UDP from a cache
+func (s *StatsdCacheTestSuite) TestDial() {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	sink, err := Dial(conn.LocalAddr().String(), Config{DogStatsD: true, FlushInterval: time.Hour})
+	s.Require().NoError(err)
+	c := cache.New[string, int](cache.WithMetrics(sink), cache.WithLoader(func(string) (int, error) { return 1, nil }))
+	_, err = c.Get("key")
+	s.Require().NoError(err)
+	s.Require().NoError(sink.Close())
+
+	buf := make([]byte, 1500)
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	s.Require().NoError(err)
+	s.Contains(string(buf[:n]), "cache.miss:1|c|#type:int")
+	s.Contains(string(buf[:n]), "cache.load:")
+}
@@ -0,0 +1,516 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// store is the type-partitioned storage behind both the package-level API
+// and Cache instances. Values are partitioned by their reflect.Type so the
+// same key can be cached independently for different value types.
+type store struct {
+	data map[reflect.Type]Backend
+	mu   sync.RWMutex
+	// groups holds the *callGroup coalescing the loads of each key and
+	// value type. Every instance and namespace has a store of its own, so
+	// loads are never coalesced, forgotten or contended across them
+	groups sync.Map
+
+	// newBackend creates the partition of a value type; nil means a map
+	newBackend func() Backend
+	// initialCapacity sizes new map partitions
+	initialCapacity int
+	// entries allocates the entries; nil allocates them one at a time
+	entries *entryAllocator
+	// view indexes data for lookups under mu. Instance caches hold a
+	// single value type, whose partition it keeps at hand
+	view partitionView
+	// published holds a copy of view for lookups without mu, kept when
+	// the partitions allow loads concurrent with writes; it is replaced
+	// whenever a partition is added, dropped or copied
+	published atomic.Pointer[partitionView]
+	// concurrentReads is set, under mu, once a partition allows loads
+	// concurrent with writes
+	concurrentReads bool
+
+	// shared marks partitions referenced by a snapshot; they are copied
+	// before their next modification
+	shared map[reflect.Type]bool
+	// count is the number of entries across all partitions
+	count int
+	// bytes is the estimated size of all entries
+	bytes int64
+
+	// settings holds the current *settings
+	settings atomic.Pointer[settings]
+	// settingsMu serializes updates of settings
+	settingsMu sync.Mutex
+	// types holds the map[reflect.Type]*typeConfig registered with
+	// Configure; it is replaced, never modified
+	types atomic.Value
+	// typeLimits is set once any type has its own entry limit
+	typeLimits atomic.Bool
+	// memoryLimited is set once a memory monitor has been started
+	memoryLimited atomic.Bool
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+	// removals counts removed entries per RemovalReason
+	removals [removalReasons]atomic.Uint64
+	// removalQueue delivers removals to the WithOnEvict handler
+	removalQueue removalQueue
+	// rejections counts writes turned away by the admission policy
+	rejections atomic.Uint64
+	versions   atomic.Uint64
+	// tick is a logical clock ordering accesses for LRU eviction
+	tick atomic.Uint64
+	// hotEntries is the number of entries in the hot segment
+	hotEntries atomic.Int64
+
+	// typeCounters holds the *typeCounters of each value type
+	typeCounters sync.Map
+	// inFlight is the number of getters running
+	inFlight atomic.Int64
+	// coalesced counts callers served by a load another caller started
+	coalesced atomic.Uint64
+	// remoteHits counts loads served by remote and originLoads getter
+	// calls
+	remoteHits  atomic.Uint64
+	originLoads atomic.Uint64
+	// lockWaits times the contended acquisitions of mu, and sweeps the
+	// janitor's sweeps
+	lockWaits latencyHistogram
+	sweeps    latencyHistogram
+	lastSweep atomic.Int64
+	// shadowReads counts the hits compared with the origin and
+	// shadowDivergences those that differed
+	shadowReads       atomic.Uint64
+	shadowDivergences atomic.Uint64
+	// strictKeys holds the pointer keys checked in strict mode
+	strictKeys strictKeys
+
+	// flights holds the loads in progress per key, guarded by mu
+	flights map[entryKey]map[*flight]struct{}
+	// recent holds getter results for the coalescing window, guarded by
+	// mu; it is swept once it reaches recentSweep entries
+	recent      map[entryKey]recentLoad
+	recentSweep int
+	// leases counts the Acquire calls not yet released per key, and
+	// deferredCloses holds the values removed from leased keys awaiting
+	// closing; both are guarded by mu
+	leases         map[entryKey]int
+	deferredCloses map[entryKey][]any
+
+	// remote is the optional backing store, encoded with codec
+	remote      Store
+	keyPrefix   string
+	keyLocks    keyLocker
+	writeBehind *writeBehind
+	// remoteWrites is how loaded values reach remote
+	remoteWrites RemoteWritePolicy
+	// invalidation configures DeleteThrough
+	invalidation InvalidationConfig
+	// spiller writes large values to disk, if configured
+	spiller *spiller
+
+	// persistOnShutdown makes shutdown write live entries to remote
+	persistOnShutdown bool
+	// sketch holds the *sketch tracking access frequencies, if enabled
+	sketch atomic.Pointer[sketch]
+	// warmup is how many hot keys per type shutdown records
+	warmup int
+	// sketchSaver persists the sketch and schedules holds the scheduled
+	// refreshes, both guarded by workersMu
+	sketchSaver *sketchSaver
+	schedules   map[*ScheduledRefresh]struct{}
+	// workersMu guards janitor and memoryMonitor
+	workersMu     sync.Mutex
+	janitor       *janitor
+	memoryMonitor *memoryMonitor
+
+	// interned holds the canonical strings, if interning is enabled
+	interned interner
+
+	// auditLog holds the *auditLog of invalidations, if enabled
+	auditLog atomic.Pointer[auditLog]
+	// frozen is set while the cache is read-only after Freeze
+	frozen atomic.Bool
+	// pressure detects miss storms, if enabled with WithBackpressure
+	pressure atomic.Pointer[pressureMonitor]
+	// tuner adapts the hot segment size, if configured
+	tuner atomic.Pointer[segmentTuner]
+
+	// absent holds the *absentFilter of known-absent keys, if enabled
+	absent atomic.Pointer[absentFilter]
+
+	// refreshes tracks running refresh-ahead loads; refreshMu guards
+	// refreshStopped and additions to refreshes
+	refreshMu      sync.Mutex
+	refreshStopped bool
+	refreshes      sync.WaitGroup
+}
+
+// entry is a single cached value.
+type entry struct {
+	// value is nil when weak holds it instead; read it with get
+	value any
+	// weak references the value when it is held weakly
+	weak *weakRef
+	// spill locates the value when it is stored on disk
+	spill *spilledValue
+	// version increases monotonically across all entries of a store
+	version uint64
+	// size is the estimated size of value in bytes, if tracked
+	size int64
+	// ttl is the time to live the entry was stored with
+	ttl time.Duration
+	// storedAt is when the entry was created
+	storedAt time.Time
+	// expiresAt is when the entry stops being served; zero means never
+	expiresAt time.Time
+	// refreshAt is when a read triggers a refresh ahead of expiry; zero
+	// means never
+	refreshAt time.Time
+	// refreshing is set while a refresh-ahead of the entry is running
+	refreshing atomic.Bool
+	// lastAccess is the store tick of the most recent read or write
+	lastAccess atomic.Uint64
+	// tags label the entry for InvalidateTags
+	tags []string
+	// priority ranks the entry for capacity eviction
+	priority Priority
+	// hot is set while the entry is in the hot segment of an SLRU
+	hot atomic.Bool
+}
+
+// global holds the store behind the package-level functions. It is only
+// replaced by Scoped.
+var global atomic.Pointer[store]
+
+func init() {
+	global.Store(newStore())
+}
+
+// globalStore returns the store behind the package-level functions.
+func globalStore() *store {
+	return global.Load()
+}
+
+func newStore() *store {
+	s := &store{
+		data: make(map[reflect.Type]Backend),
+	}
+	s.settings.Store(newSettings(options{}))
+	return s
+}
+
+// newEntry wraps a value of the valueType partition in an entry carrying
+// the next version and the partition's expiration.
+func (s *store) newEntry(valueType reflect.Type, value any) *entry {
+	return s.newSizedEntry(valueType, value, -1)
+}
+
+// newSizedEntry is newEntry for a value whose size is known. A negative
+// size is estimated like in newEntry.
+func (s *store) newSizedEntry(valueType reflect.Type, value any, size int64) *entry {
+	value, exact := s.storedBytes(value)
+	if size < 0 {
+		size = exact
+	}
+	e := s.entries.alloc()
+	e.value = s.internValue(value)
+	e.version = s.versions.Add(1)
+	e.priority = s.priorityFor(valueType)
+	e.storedAt = s.now()
+	s.setExpiry(e, s.ttlFor(valueType))
+	if size >= 0 {
+		e.size = size
+	} else if sizeOf := s.cfg().sizeOf; sizeOf != nil {
+		e.size = sizeOf(value)
+	}
+	s.spill(valueType, e, value)
+	if s.cfg().weakValues && e.spill == nil {
+		if ref := newWeakRef(value); ref != nil {
+			e.value, e.weak = nil, ref
+		}
+	}
+	return e
+}
+
+// expiry returns the expiration of an entry with the given time to live
+// written now, or the zero time if ttl is not positive.
+func (s *store) expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return s.now().Add(ttl)
+}
+
+// expired reports whether e must no longer be served at now. Entries whose
+// weakly held value was reclaimed count as expired.
+func (e *entry) expired(now time.Time) bool {
+	return (!e.expiresAt.IsZero() && !now.Before(e.expiresAt)) || e.reclaimed()
+}
+
+// bounded reports whether the store has limits that require eviction.
+func (s *store) bounded() bool {
+	cfg := s.cfg()
+	return cfg.maxEntries > 0 || cfg.maxBytes > 0 || cfg.maxEntriesPerType > 0 ||
+		s.typeLimits.Load() || s.memoryLimited.Load() ||
+		(s.spiller != nil && s.spiller.MaxBytes > 0)
+}
+
+// touch records an access to e for recency-based eviction.
+func (s *store) touch(e *entry) {
+	if s.bounded() {
+		e.lastAccess.Store(s.tick.Add(1))
+	}
+}
+
+// lookupValue returns the live entry stored for key in the valueType
+// partition together with its value. Entries whose weakly held value is
+// reclaimed meanwhile are reported as missing.
+func (s *store) lookupValue(valueType reflect.Type, key any) (*entry, any, bool) {
+	e, ok := s.lookupEntry(valueType, key)
+	if !ok {
+		return nil, nil, false
+	}
+	value, live := e.get()
+	if !live {
+		return nil, nil, false
+	}
+	return e, s.cfg().bytesMode.serve(value), true
+}
+
+// lookupEntry returns the live entry stored for key in the valueType
+// partition. Expired entries are reported as missing.
+func (s *store) lookupEntry(valueType reflect.Type, key any) (*entry, bool) {
+	var e *entry
+	var ok bool
+	if published := s.published.Load(); published != nil {
+		e, ok = published.entry(valueType, key)
+	} else {
+		s.rlock()
+		e, ok = s.entryLocked(valueType, key)
+		s.mu.RUnlock()
+	}
+	if !ok || e.expired(s.now()) {
+		return nil, false
+	}
+	return e, true
+}
+
+// swap stores e for key and returns the entry it replaced, if any. Loads of
+// key in progress are superseded and will not store their result.
+func (s *store) swap(valueType reflect.Type, key any, e *entry) *entry {
+	s.lock()
+	defer s.mu.Unlock()
+	s.cancelFlightsLocked(entryKey{valueType, key})
+	return s.putLocked(valueType, key, e)
+}
+
+// restore puts prev back in place of current, or removes current when prev
+// is nil. Nothing happens if current has been replaced in the meantime.
+func (s *store) restore(valueType reflect.Type, key any, current, prev *entry) {
+	s.lock()
+	defer s.mu.Unlock()
+	if e, _ := s.entryLocked(valueType, key); e != current {
+		return
+	}
+	if prev == nil {
+		s.removeLocked(valueType, key, RemovalDeleted)
+		return
+	}
+	s.putLocked(valueType, key, prev)
+}
+
+// add stores e for key unless a live entry is already cached and reports
+// whether it did.
+func (s *store) add(valueType reflect.Type, key any, e *entry) bool {
+	s.lock()
+	defer s.mu.Unlock()
+	if current, ok := s.entryLocked(valueType, key); ok && !current.expired(s.now()) {
+		return false
+	}
+	s.putLocked(valueType, key, e)
+	return true
+}
+
+// delete removes key from the valueType partition. Loads of key in
+// progress will not store their result.
+func (s *store) delete(valueType reflect.Type, key any) {
+	s.lock()
+	defer s.mu.Unlock()
+	s.cancelFlightsLocked(entryKey{valueType, key})
+	s.removeLocked(valueType, key, RemovalDeleted)
+	s.reclaimExpiredLocked()
+}
+
+// clear removes every entry of every type. Loads in progress will not
+// store their result.
+func (s *store) clear() {
+	s.lock()
+	defer s.mu.Unlock()
+	s.cancelAllFlightsLocked()
+	s.rangeAllLocked(func(valueType reflect.Type, key any, e *entry) bool {
+		s.discardLocked(valueType, key, e, RemovalDeleted)
+		return true
+	})
+	s.data = make(map[reflect.Type]Backend)
+	s.publishLocked()
+	s.shared = nil
+	s.count = 0
+	s.hotEntries.Store(0)
+	s.bytes = 0
+}
+
+// reset returns the store to its initial state: no entries, no per-type
+// configuration, default settings, no janitor and zeroed statistics.
+func (s *store) reset() {
+	_ = s.stopJanitor(context.Background(), true)
+	_ = s.stopSchedules(context.Background())
+	_ = s.stopMemoryMonitor(context.Background())
+	s.settingsMu.Lock()
+	s.settings.Store(newSettings(options{}))
+	s.settingsMu.Unlock()
+	s.lock()
+	s.types.Store(map[reflect.Type]*typeConfig(nil))
+	s.leases = nil
+	s.deferredCloses = nil
+	s.typeLimits.Store(false)
+	s.memoryLimited.Store(false)
+	s.mu.Unlock()
+	s.clear()
+	s.absent.Store(nil)
+	s.sketch.Store(nil)
+	s.auditLog.Store(nil)
+	s.pressure.Store(nil)
+	s.tuner.Store(nil)
+	s.interned.replace(nil)
+	s.refreshMu.Lock()
+	s.refreshStopped = false
+	s.refreshMu.Unlock()
+	s.hits.Store(0)
+	s.misses.Store(0)
+	s.evictions.Store(0)
+	for i := range s.removals {
+		s.removals[i].Store(0)
+	}
+	s.rejections.Store(0)
+	s.coalesced.Store(0)
+	s.resetInternals()
+	s.shadowReads.Store(0)
+	s.shadowDivergences.Store(0)
+	s.strictKeys.reset()
+	s.remoteHits.Store(0)
+	s.originLoads.Store(0)
+	s.resetCounters()
+}
+
+// putLocked stores e for key and returns the entry it replaced, evicting
+// other entries if the store grows beyond its limits. New keys are subject
+// to the admission policy and may be turned away. Must be called with
+// s.mu held for writing.
+func (s *store) putLocked(valueType reflect.Type, key any, e *entry) *entry {
+	key = canonicalKey(key)
+	prev, existed := s.entryLocked(valueType, key)
+	if !existed && !s.admitLocked(valueType, key, e) {
+		return nil
+	}
+	if !existed {
+		key = s.internKey(key)
+	}
+	s.partitionForWrite(valueType).Store(key, e)
+	s.touch(e)
+	s.bytes += e.size
+	if existed {
+		if prev.hot.Load() {
+			// The key keeps its segment
+			s.demote(prev)
+			s.promote(e)
+		}
+		s.bytes -= prev.size
+		// Replacing an entry past its expiration is TTL churn
+		reason := RemovalReplaced
+		if prev.expired(s.now()) {
+			reason = RemovalExpired
+		}
+		s.discardLocked(valueType, key, prev, reason)
+	} else {
+		s.count++
+	}
+	s.addSpilledLocked(entryKey{valueType, key}, e)
+	s.reclaimExpiredLocked()
+	s.evictLocked(entryKey{valueType, key})
+	s.pruneInternedLocked()
+	return prev
+}
+
+// removeLocked deletes key from the valueType partition for reason and
+// returns the removed entry. Must be called with s.mu held for writing.
+func (s *store) removeLocked(valueType reflect.Type, key any, reason RemovalReason) (*entry, bool) {
+	key = canonicalKey(key)
+	e, ok := s.entryLocked(valueType, key)
+	if !ok {
+		return nil, false
+	}
+	b := s.partitionForWrite(valueType)
+	b.Delete(key)
+	if b.Len() == 0 {
+		// Drop empty partitions so types used once do not linger
+		delete(s.data, valueType)
+		s.publishLocked()
+	}
+	s.count--
+	s.bytes -= e.size
+	s.demote(e)
+	s.discardLocked(valueType, key, e, reason)
+	return e, true
+}
+
+// discardLocked records the removal of e from key in the valueType
+// partition for reason and releases its resources: its file if it was
+// spilled to disk, its value if it is to be closed. Must be called with s.mu
+// held for writing.
+func (s *store) discardLocked(valueType reflect.Type, key any, e *entry, reason RemovalReason) {
+	s.removedLocked(valueType, key, e, reason)
+	if e.spill != nil {
+		s.unspillLocked(entryKey{valueType, key}, e)
+		return
+	}
+	value, _ := e.get()
+	s.retireLocked(valueType, key, value)
+}
+
+// partitionForWrite returns the valueType partition ready to be modified,
+// creating it if needed and copying it first if a snapshot shares it.
+// Must be called with s.mu held for writing.
+func (s *store) partitionForWrite(valueType reflect.Type) Backend {
+	b, ok := s.data[valueType]
+	if !ok {
+		b = s.newPartition()
+		s.data[valueType] = b
+		s.publishLocked()
+		return b
+	}
+	if s.shared[valueType] {
+		b = b.Clone()
+		s.data[valueType] = b
+		delete(s.shared, valueType)
+		s.publishLocked()
+	}
+	return b
+}
+
+func (s *store) emit(ev Event) {
+	if fn := s.cfg().onEvent; fn != nil {
+		if ev.Key != nil && ev.Type != nil {
+			ev.Key = s.redactKey(s.typeName(ev.Type), ev.Key)
+		}
+		fn(ev)
+	}
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// maxStrictKeys bounds the pointer keys strict mode remembers.
+const maxStrictKeys = 10_000
+
+// WithStrictMode turns on checks catching misuse during development, at a
+// cost unsuited to production:
+//
+//   - a nil getter panics instead of returning ErrNilGetter
+//
+//   - every getter call that succeeds is made twice and an
+//     EventStrictViolation wrapping ErrNondeterministicGetter is emitted
+//     if the results are not deeply equal
+//
+//   - the values pointer keys point to are copied when the keys are
+//     cached, and an EventStrictViolation wrapping ErrMutatedKey is
+//     emitted when a hit finds the value changed, which the cache cannot
+//     notice otherwise; the copies are shallow
+//
+// Tests can fail on violations through an event handler:
+//
+//	cache.SetDefaults(cache.WithStrictMode(), cache.WithEventHandler(func(ev cache.Event) {
+//		t.Errorf("cache %s: %v", ev.Kind, ev.Err)
+//	}))
+func WithStrictMode() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// strictKeys holds copies of what cached pointer keys point to.
+type strictKeys struct {
+	mu    sync.Mutex
+	saved map[entryKey]reflect.Value
+}
+
+func (sk *strictKeys) reset() {
+	sk.mu.Lock()
+	sk.saved = nil
+	sk.mu.Unlock()
+}
+
+// pointee returns a copy of the value key points to, if key is a non-nil
+// pointer.
+func pointee(key any) (reflect.Value, bool) {
+	v := reflect.ValueOf(key)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return reflect.Value{}, false
+	}
+	saved := reflect.New(v.Elem().Type()).Elem()
+	saved.Set(v.Elem())
+	return saved, true
+}
+
+// rememberKey copies what key points to, if it is a pointer, so that hits
+// can tell whether it was mutated.
+func (s *store) rememberKey(valueType reflect.Type, key any) {
+	saved, ok := pointee(key)
+	if !ok {
+		return
+	}
+	sk := &s.strictKeys
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	if sk.saved == nil {
+		sk.saved = make(map[entryKey]reflect.Value)
+	}
+	k := entryKey{valueType, key}
+	if _, known := sk.saved[k]; known || len(sk.saved) < maxStrictKeys {
+		sk.saved[k] = saved
+	}
+}
+
+// checkKeyUnchanged reports a pointer key whose target changed since it
+// was cached. The change is reported once.
+func (s *store) checkKeyUnchanged(valueType reflect.Type, key any) {
+	current, ok := pointee(key)
+	if !ok {
+		return
+	}
+	sk := &s.strictKeys
+	k := entryKey{valueType, key}
+	sk.mu.Lock()
+	saved, known := sk.saved[k]
+	mutated := known && !reflect.DeepEqual(saved.Interface(), current.Interface())
+	if mutated {
+		sk.saved[k] = current
+	}
+	sk.mu.Unlock()
+	if mutated {
+		s.emit(Event{
+			Kind: EventStrictViolation,
+			Type: valueType,
+			Key:  key,
+			Err:  ErrMutatedKey,
+		})
+	}
+}
+
+// checkDeterministic calls getterFunc for key again and reports it if the
+// result differs from first.
+func checkDeterministic[K comparable, V any](s *store, valueType reflect.Type, key K, getterFunc weightedGetter[K, V], first V) {
+	second, _, err := getterFunc(key)
+	if err == nil && reflect.DeepEqual(first, second) {
+		return
+	}
+	// Values are left out of the error, which would bypass the Redactor
+	problem := ErrNondeterministicGetter
+	if err != nil {
+		problem = fmt.Errorf("%w: the second call failed: %v", ErrNondeterministicGetter, err)
+	}
+	s.emit(Event{
+		Kind: EventStrictViolation,
+		Type: valueType,
+		Key:  key,
+		Err:  problem,
+	})
+}
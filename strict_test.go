@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StrictTestSuite struct {
+	suite.Suite
+	mu     sync.Mutex
+	events []Event
+}
+
+func TestStrictSuite(t *testing.T) {
+	suite.Run(t, new(StrictTestSuite))
+}
+
+func (s *StrictTestSuite) SetupTest() {
+	s.events = nil
+}
+
+func (s *StrictTestSuite) record(ev Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, ev)
+}
+
+// TestNilGetterPanics verifies that strict mode panics on nil getters
+func (s *StrictTestSuite) TestNilGetterPanics() {
+	SetDefaults(WithStrictMode())
+	defer Reset()
+	s.PanicsWithValue(ErrNilGetter, func() { _, _ = Get[string, int]("key", nil) })
+	s.PanicsWithValue(ErrNilGetter, func() { _, _ = Do[string, int]("key", nil) })
+}
+
+// TestNondeterministicGetter verifies that getters returning different
+// values for a key are reported, and deterministic ones are not
+func (s *StrictTestSuite) TestNondeterministicGetter() {
+	var calls atomic.Int32
+	c := New[string, int](WithStrictMode(), WithEventHandler(s.record), WithLoader(func(key string) (int, error) {
+		if key == "counter" {
+			return int(calls.Add(1)), nil
+		}
+		return len(key), nil
+	}))
+	value, err := c.Get("stable")
+	s.Require().NoError(err)
+	s.Equal(6, value)
+	s.Empty(s.events)
+
+	value, err = c.Get("counter")
+	s.Require().NoError(err)
+	s.Equal(1, value, "The first result is used")
+	s.Require().Len(s.events, 1)
+	s.Equal(EventStrictViolation, s.events[0].Kind)
+	s.ErrorIs(s.events[0].Err, ErrNondeterministicGetter)
+}
+
+// TestMutatedKey verifies that hits report pointer keys whose target
+// changed after the key was cached, once
+func (s *StrictTestSuite) TestMutatedKey() {
+	type query struct{ Team string }
+	c := New[*query, int](WithStrictMode(), WithEventHandler(s.record), WithLoader(func(q *query) (int, error) {
+		return len(q.Team), nil
+	}))
+	key := &query{Team: "core"}
+	_, err := c.Get(key)
+	s.Require().NoError(err)
+	_, err = c.Get(key)
+	s.Require().NoError(err)
+	s.Empty(s.events)
+
+	key.Team = "platform"
+	for i := 0; i < 2; i++ {
+		value, err := c.Get(key)
+		s.Require().NoError(err)
+		s.Equal(4, value, "The entry of the mutated key is served")
+	}
+	s.Require().Len(s.events, 1)
+	s.ErrorIs(s.events[0].Err, ErrMutatedKey)
+
+	plain := New[*query, int](WithStrictMode(), WithEventHandler(s.record))
+	other := &query{Team: "data"}
+	s.Require().NoError(plain.Set(other, 7))
+	other.Team = "ml"
+	_, err = plain.Get(other)
+	s.Require().NoError(err)
+	s.Len(s.events, 2, "Keys written with Set are checked too")
+}
@@ -0,0 +1,258 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Transport is an http.RoundTripper caching successful responses to GET
+// requests. A cached response is served without contacting the origin
+// while it is fresh: for the max-age of its Cache-Control header, or the
+// default TTL given to NewTransport. Once it goes stale, a response
+// carrying an ETag or Last-Modified validator is revalidated with a
+// conditional request, and a 304 Not Modified answer renews it without
+// downloading the body again.
+//
+// The RFC 5861 extensions are honored: a stale response within its
+// stale-while-revalidate window is served immediately while it is
+// revalidated in the background, and one within its stale-if-error window
+// is served when the origin fails or answers with a 5xx status.
+//
+// Responses are keyed by URL; Vary is not supported, so responses that
+// depend on request headers must not be cached. Responses marked no-store
+// or private, and requests carrying an Authorization header, are passed
+// through untouched.
+type Transport struct {
+	base  http.RoundTripper
+	ttl   time.Duration
+	cache *Cache[string, *cachedResponse]
+
+	// revalidating holds the keys being revalidated in the background
+	mu           sync.Mutex
+	revalidating map[string]bool
+}
+
+// cachedResponse is a response held by a Transport.
+type cachedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+	// freshUntil is when the response must be revalidated
+	freshUntil time.Time
+	// staleWhileRevalidate and staleIfError are the windows after
+	// freshUntil during which the response may still be served
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+}
+
+// NewTransport returns a Transport sending requests through base, or
+// http.DefaultTransport if it is nil. ttl is how long responses without a
+// max-age directive stay fresh. opts configure the underlying cache; its
+// WithTTL, if any, bounds how long stale responses are kept for
+// revalidation, and WithMaxEntries bounds how many are kept at all.
+func NewTransport(base http.RoundTripper, ttl time.Duration, opts ...Option) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		base:         base,
+		ttl:          ttl,
+		cache:        New[string, *cachedResponse](opts...),
+		revalidating: make(map[string]bool),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+	key := req.URL.String()
+	cached, _ := t.cache.Get(key)
+	now := t.cache.s.now()
+	if cached != nil {
+		if now.Before(cached.freshUntil) {
+			return cached.response(req), nil
+		}
+		if now.Before(cached.freshUntil.Add(cached.staleWhileRevalidate)) {
+			t.revalidate(req, key, cached)
+			return cached.response(req), nil
+		}
+	}
+
+	resp, err := t.fetch(req, key, cached)
+	if cached != nil && (err != nil || resp.StatusCode >= http.StatusInternalServerError) &&
+		now.Before(cached.freshUntil.Add(cached.staleIfError)) {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return cached.response(req), nil
+	}
+	return resp, err
+}
+
+// revalidate refreshes cached in the background unless it is already
+// being refreshed. The request is detached from the caller's context,
+// which usually ends as soon as the stale response has been served.
+func (t *Transport) revalidate(req *http.Request, key string, cached *cachedResponse) {
+	t.mu.Lock()
+	if t.revalidating[key] {
+		t.mu.Unlock()
+		return
+	}
+	t.revalidating[key] = true
+	t.mu.Unlock()
+
+	req = req.Clone(context.Background())
+	go func() {
+		defer func() {
+			t.mu.Lock()
+			delete(t.revalidating, key)
+			t.mu.Unlock()
+		}()
+		resp, err := t.fetch(req, key, cached)
+		if err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+}
+
+// fetch sends req to the origin, conditionally if cached holds a
+// validator, and updates the cache with the outcome.
+func (t *Transport) fetch(req *http.Request, key string, cached *cachedResponse) (*http.Response, error) {
+	outgoing := req
+	if cached != nil {
+		outgoing = conditional(req, cached)
+	}
+	resp, err := t.base.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		header := cached.header.Clone()
+		// The 304 carries the current metadata of the stored response
+		for name, values := range resp.Header {
+			header[name] = values
+		}
+		renewed := t.newCachedResponse(cached.status, header, cached.body)
+		t.cache.Set(key, renewed)
+		return renewed.response(req), nil
+	}
+
+	if !t.storable(resp) {
+		// A failing origin does not invalidate what it served before
+		if cached != nil && resp.StatusCode < http.StatusInternalServerError {
+			t.cache.Delete(key)
+		}
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.cache.Set(key, t.newCachedResponse(resp.StatusCode, resp.Header.Clone(), body))
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// storable reports whether resp may be cached.
+func (t *Transport) storable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	directives := cacheControl(resp.Header)
+	_, noStore := directives["no-store"]
+	_, private := directives["private"]
+	return !noStore && !private
+}
+
+// newCachedResponse prepares a response received now for caching,
+// deriving its freshness from its Cache-Control header.
+func (t *Transport) newCachedResponse(status int, header http.Header, body []byte) *cachedResponse {
+	ttl := t.ttl
+	directives := cacheControl(header)
+	if _, noCache := directives["no-cache"]; noCache {
+		ttl = 0
+	} else if maxAge, ok := seconds(directives, "max-age"); ok {
+		ttl = maxAge
+	}
+	cr := &cachedResponse{
+		status:     status,
+		header:     header,
+		body:       body,
+		freshUntil: t.cache.s.now().Add(ttl),
+	}
+	cr.staleWhileRevalidate, _ = seconds(directives, "stale-while-revalidate")
+	cr.staleIfError, _ = seconds(directives, "stale-if-error")
+	return cr
+}
+
+// seconds returns the duration of a delta-seconds directive.
+func seconds(directives map[string]string, name string) (time.Duration, bool) {
+	value, ok := directives[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// conditional returns a copy of req validating cached with the origin.
+func conditional(req *http.Request, cached *cachedResponse) *http.Request {
+	etag := cached.header.Get("ETag")
+	lastModified := cached.header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return req
+	}
+	req = req.Clone(req.Context())
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	return req
+}
+
+// response builds a response to req from the cached copy.
+func (cr *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(cr.status) + " " + http.StatusText(cr.status),
+		StatusCode:    cr.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cr.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(cr.body)),
+		ContentLength: int64(len(cr.body)),
+		Request:       req,
+	}
+}
+
+// cacheControl parses the Cache-Control header into its directives.
+func cacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, line := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if name == "" {
+				continue
+			}
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return directives
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TransportTestSuite struct {
+	suite.Suite
+	clock *FakeClock
+	// requests counts requests reaching the origin
+	requests atomic.Int32
+	// notModified counts conditional requests answered with 304
+	notModified atomic.Int32
+	// failing makes the origin answer with 503
+	failing atomic.Bool
+	// headers is the Cache-Control header the origin responds with
+	headers string
+	server  *httptest.Server
+	client  *http.Client
+}
+
+func TestTransportSuite(t *testing.T) {
+	suite.Run(t, new(TransportTestSuite))
+}
+
+func (s *TransportTestSuite) SetupTest() {
+	s.clock = NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s.requests.Store(0)
+	s.notModified.Store(0)
+	s.failing.Store(false)
+	s.headers = ""
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if s.headers != "" {
+			w.Header().Set("Cache-Control", s.headers)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			s.notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = io.WriteString(w, "hello")
+	}))
+	s.client = &http.Client{Transport: NewTransport(nil, time.Minute, WithClock(s.clock))}
+}
+
+func (s *TransportTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *TransportTestSuite) get() string {
+	status, body := s.fetch()
+	s.Equal(http.StatusOK, status)
+	return body
+}
+
+func (s *TransportTestSuite) fetch() (int, string) {
+	resp, err := s.client.Get(s.server.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	return resp.StatusCode, string(body)
+}
+
+// TestFreshResponsesAreServedFromCache verifies that the origin is not
+// contacted while a response is fresh
+func (s *TransportTestSuite) TestFreshResponsesAreServedFromCache() {
+	s.Equal("hello", s.get())
+	s.Equal("hello", s.get())
+	s.Equal(int32(1), s.requests.Load())
+}
+
+// TestRevalidation verifies that stale responses are revalidated with their
+// ETag and renewed on 304
+func (s *TransportTestSuite) TestRevalidation() {
+	s.Equal("hello", s.get())
+	s.clock.Advance(2 * time.Minute)
+
+	s.Equal("hello", s.get(), "The cached body is served after a 304")
+	s.Equal(int32(2), s.requests.Load())
+	s.Equal(int32(1), s.notModified.Load())
+
+	s.Equal("hello", s.get())
+	s.Equal(int32(2), s.requests.Load(), "A 304 renews freshness")
+}
+
+// TestMaxAge verifies that max-age overrides the default TTL
+func (s *TransportTestSuite) TestMaxAge() {
+	s.headers = "max-age=10"
+	s.get()
+	s.clock.Advance(11 * time.Second)
+	s.get()
+	s.Equal(int32(1), s.notModified.Load())
+}
+
+// TestNoStore verifies that no-store responses are not cached
+func (s *TransportTestSuite) TestNoStore() {
+	s.headers = "no-store"
+	s.get()
+	s.get()
+	s.Equal(int32(2), s.requests.Load())
+	s.Equal(int32(0), s.notModified.Load())
+}
+
+// TestStaleWhileRevalidate verifies that a stale response is served at
+// once while it is revalidated in the background
+func (s *TransportTestSuite) TestStaleWhileRevalidate() {
+	s.headers = "max-age=10, stale-while-revalidate=60"
+	s.get()
+	s.clock.Advance(20 * time.Second)
+
+	s.Equal("hello", s.get())
+	s.Eventually(func() bool { return s.notModified.Load() == 1 }, time.Second, time.Millisecond,
+		"The response is revalidated in the background")
+
+	s.clock.Advance(2 * time.Minute)
+	s.get()
+	s.Equal(int32(2), s.notModified.Load(), "Past the window, revalidation is synchronous")
+}
+
+// TestStaleIfError verifies that a stale response stands in for a failing
+// origin within its stale-if-error window only
+func (s *TransportTestSuite) TestStaleIfError() {
+	s.headers = "max-age=10, stale-if-error=60"
+	s.get()
+	s.failing.Store(true)
+
+	s.clock.Advance(20 * time.Second)
+	s.Equal("hello", s.get(), "The stale response hides the failure")
+
+	s.clock.Advance(time.Minute)
+	status, _ := s.fetch()
+	s.Equal(http.StatusServiceUnavailable, status, "Past the window the failure shows")
+}
@@ -0,0 +1,111 @@
+package cache
+
+// Tx stages Set and Delete operations on a Cache. Staged operations become
+// visible all at once when the transaction function passed to Cache.Txn
+// returns nil, and are discarded otherwise. A Tx must not be used after its
+// transaction function returns.
+type Tx[K comparable, V any] struct {
+	c      *Cache[K, V]
+	staged map[K]txOp[V]
+	order  []K
+	// err is the first key rejected by stage
+	err error
+}
+
+type txOp[V any] struct {
+	value   V
+	deleted bool
+}
+
+// Get returns the value for key as seen by the transaction: staged changes
+// take precedence over cached entries. It never loads missing keys.
+func (tx *Tx[K, V]) Get(key K) (V, bool) {
+	if !tx.c.s.usableKey(key) {
+		var zero V
+		return zero, false
+	}
+	if op, ok := tx.staged[key]; ok {
+		if op.deleted {
+			var zero V
+			return zero, false
+		}
+		return op.value, true
+	}
+	return tx.c.Peek(key)
+}
+
+// Set stages value for key. A key the cache rejects makes Txn fail
+// without applying anything.
+func (tx *Tx[K, V]) Set(key K, value V) {
+	tx.stage(key, txOp[V]{value: value})
+}
+
+// Delete stages the removal of key. A key the cache rejects makes Txn fail
+// without applying anything.
+func (tx *Tx[K, V]) Delete(key K) {
+	tx.stage(key, txOp[V]{deleted: true})
+}
+
+func (tx *Tx[K, V]) stage(key K, op txOp[V]) {
+	if err := tx.c.s.checkKey(key); err != nil {
+		if tx.err == nil {
+			tx.err = err
+		}
+		return
+	}
+	if _, ok := tx.staged[key]; !ok {
+		tx.order = append(tx.order, key)
+	}
+	tx.staged[key] = op
+}
+
+// Txn runs fn with a new transaction. If fn returns nil, every staged
+// operation is applied atomically under the store lock, so readers observe
+// either none or all of them. If fn returns an error, nothing is applied
+// and the error is returned. Nothing is applied to a frozen cache either,
+// and Txn returns ErrFrozen, nor when a staged key was rejected, such as an
+// unhashable one, in which case Txn returns the error of the first.
+func (c *Cache[K, V]) Txn(fn func(tx *Tx[K, V]) error) error {
+	tx := &Tx[K, V]{
+		c:      c,
+		staged: make(map[K]txOp[V]),
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if tx.err != nil {
+		return tx.err
+	}
+	if len(tx.order) == 0 {
+		return nil
+	}
+	if c.s.frozen.Load() {
+		return ErrFrozen
+	}
+
+	s := c.s
+	versions := make([]uint64, len(tx.order))
+	s.lock()
+	for i, key := range tx.order {
+		op := tx.staged[key]
+		s.cancelFlightsLocked(entryKey{c.valueType, key})
+		if op.deleted {
+			s.removeLocked(c.valueType, key, RemovalDeleted)
+			continue
+		}
+		e := s.newEntry(c.valueType, op.value)
+		versions[i] = e.version
+		s.putLocked(c.valueType, key, e)
+	}
+	s.mu.Unlock()
+
+	// Committed values follow the same write-behind path as Set
+	if s.writeBehind != nil {
+		for i, key := range tx.order {
+			if op := tx.staged[key]; !op.deleted {
+				s.queueWrite(c.valueType, key, op.value, versions[i])
+			}
+		}
+	}
+	return nil
+}
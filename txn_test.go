@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TxnTestSuite struct {
+	suite.Suite
+	cache *Cache[string, int]
+}
+
+func TestTxnSuite(t *testing.T) {
+	suite.Run(t, new(TxnTestSuite))
+}
+
+// SetupTest runs before each test
+func (s *TxnTestSuite) SetupTest() {
+	s.cache = New[string, int]()
+}
+
+// TestCommitAppliesAllOperations verifies that staged Set/Delete are applied on success
+func (s *TxnTestSuite) TestCommitAppliesAllOperations() {
+	s.cache.Set("stale", 1)
+
+	err := s.cache.Txn(func(tx *Tx[string, int]) error {
+		tx.Set("a", 1)
+		tx.Set("b", 2)
+		tx.Delete("stale")
+		return nil
+	})
+	s.NoError(err)
+
+	a, found := s.cache.Peek("a")
+	s.True(found)
+	s.Equal(1, a)
+	b, found := s.cache.Peek("b")
+	s.True(found)
+	s.Equal(2, b)
+	_, found = s.cache.Peek("stale")
+	s.False(found)
+}
+
+// TestErrorDiscardsStagedOperations verifies that nothing is applied when fn fails
+func (s *TxnTestSuite) TestErrorDiscardsStagedOperations() {
+	s.cache.Set("keep", 1)
+	errAbort := errors.New("abort")
+
+	err := s.cache.Txn(func(tx *Tx[string, int]) error {
+		tx.Set("a", 1)
+		tx.Delete("keep")
+		return errAbort
+	})
+	s.ErrorIs(err, errAbort)
+
+	_, found := s.cache.Peek("a")
+	s.False(found, "Staged Set should have been discarded")
+	_, found = s.cache.Peek("keep")
+	s.True(found, "Staged Delete should have been discarded")
+}
+
+// TestRejectedKeysDiscardStagedOperations verifies that a key the cache
+// rejects fails the transaction without applying anything
+func (s *TxnTestSuite) TestRejectedKeysDiscardStagedOperations() {
+	c := New[float64, int]()
+	err := c.Txn(func(tx *Tx[float64, int]) error {
+		tx.Set(1, 1)
+		tx.Set(math.NaN(), 2)
+		tx.Delete(math.NaN())
+		_, found := tx.Get(math.NaN())
+		s.False(found)
+		return nil
+	})
+	s.ErrorIs(err, ErrNaNKey)
+
+	_, found := c.Peek(1)
+	s.False(found, "Staged Set should have been discarded")
+}
+
+// TestTxGetSeesStagedChanges verifies read-your-writes inside a transaction
+func (s *TxnTestSuite) TestTxGetSeesStagedChanges() {
+	s.cache.Set("a", 1)
+	s.cache.Set("b", 2)
+
+	err := s.cache.Txn(func(tx *Tx[string, int]) error {
+		a, found := tx.Get("a")
+		s.True(found)
+		tx.Set("a", a+10)
+		tx.Delete("b")
+
+		a, found = tx.Get("a")
+		s.True(found)
+		s.Equal(11, a)
+		_, found = tx.Get("b")
+		s.False(found)
+
+		// Not visible outside the transaction yet
+		outside, _ := s.cache.Peek("a")
+		s.Equal(1, outside)
+		return nil
+	})
+	s.NoError(err)
+}
+
+// TestReadersNeverObserveHalfAppliedTransactions verifies atomicity under the store lock
+func (s *TxnTestSuite) TestReadersNeverObserveHalfAppliedTransactions() {
+	s.cache.Set("a", 0)
+	s.cache.Set("b", 0)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 200; i++ {
+			_ = s.cache.Txn(func(tx *Tx[string, int]) error {
+				tx.Set("a", i)
+				tx.Set("b", i)
+				return nil
+			})
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		// Read both entries under a single read lock
+		s.cache.s.mu.RLock()
+		ea, _ := s.cache.s.entryLocked(s.cache.valueType, "a")
+		eb, _ := s.cache.s.entryLocked(s.cache.valueType, "b")
+		a, b := ea.value, eb.value
+		s.cache.s.mu.RUnlock()
+		s.Equal(a, b, "Transaction was observed half-applied")
+	}
+	wg.Wait()
+}
@@ -0,0 +1,59 @@
+package cache
+
+// Version returns the version of the entry cached for key. Versions are
+// assigned from a counter that increases with every write to the cache, so
+// a changed value always has a higher version than the one it replaced.
+func (c *Cache[K, V]) Version(key K) (uint64, bool) {
+	if !c.s.usableKey(key) {
+		return 0, false
+	}
+	e, ok := c.s.lookupEntry(c.valueType, key)
+	if !ok {
+		return 0, false
+	}
+	return e.version, true
+}
+
+// GetIfChanged returns the value cached for key and its version, unless the
+// version equals lastVersion, in which case it returns ErrNotModified. Pass
+// zero to always receive the current value. Misses are handled like Get:
+// loaded in read-through mode, ErrNotCached in cache-aside mode. A version
+// of zero is returned for loaded values that were not stored.
+func (c *Cache[K, V]) GetIfChanged(key K, lastVersion uint64) (V, uint64, error) {
+	var zero V
+	if err := c.s.checkKey(key); err != nil {
+		return zero, 0, err
+	}
+	if value, version, ok := c.current(key); ok {
+		c.s.recordHit(c.valueType, key)
+		if version == lastVersion {
+			return zero, version, ErrNotModified
+		}
+		return value, version, nil
+	}
+
+	// Miss (or corrupted entry): go through the regular Get path
+	value, err := c.Get(key)
+	if err != nil {
+		return zero, 0, err
+	}
+	// Read value and version together in case the entry changed meanwhile
+	if current, version, ok := c.current(key); ok {
+		return current, version, nil
+	}
+	return value, 0, nil
+}
+
+// current returns the cached value for key together with its version.
+func (c *Cache[K, V]) current(key K) (V, uint64, bool) {
+	var zero V
+	e, stored, ok := c.s.lookupValue(c.valueType, key)
+	if !ok {
+		return zero, 0, false
+	}
+	value, ok := stored.(V)
+	if !ok {
+		return zero, 0, false
+	}
+	return value, e.version, true
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type VersionsTestSuite struct {
+	suite.Suite
+}
+
+func TestVersionsSuite(t *testing.T) {
+	suite.Run(t, new(VersionsTestSuite))
+}
+
+// TestVersionsIncreaseOnEveryWrite verifies monotonic versions
+func (s *VersionsTestSuite) TestVersionsIncreaseOnEveryWrite() {
+	c := New[string, int]()
+
+	_, found := c.Version("a")
+	s.False(found)
+
+	c.Set("a", 1)
+	v1, found := c.Version("a")
+	s.True(found)
+
+	c.Set("b", 1)
+	c.Set("a", 2)
+	v2, _ := c.Version("a")
+	s.Greater(v2, v1)
+
+	// Transactions assign versions too
+	s.NoError(c.Txn(func(tx *Tx[string, int]) error {
+		tx.Set("a", 3)
+		return nil
+	}))
+	v3, _ := c.Version("a")
+	s.Greater(v3, v2)
+}
+
+// TestGetIfChangedReturnsNotModified verifies conditional gets
+func (s *VersionsTestSuite) TestGetIfChangedReturnsNotModified() {
+	c := New[string, int]()
+	c.Set("a", 1)
+
+	value, version, err := c.GetIfChanged("a", 0)
+	s.NoError(err)
+	s.Equal(1, value)
+
+	// Unchanged entry
+	_, sameVersion, err := c.GetIfChanged("a", version)
+	s.ErrorIs(err, ErrNotModified)
+	s.Equal(version, sameVersion)
+
+	// Changed entry
+	c.Set("a", 2)
+	value, newVersion, err := c.GetIfChanged("a", version)
+	s.NoError(err)
+	s.Equal(2, value)
+	s.Greater(newVersion, version)
+}
+
+// TestGetIfChangedLoadsMisses verifies that misses follow the cache mode
+func (s *VersionsTestSuite) TestGetIfChangedLoadsMisses() {
+	var loads atomic.Int32
+	readThrough := New[string, int](WithLoader(func(key string) (int, error) {
+		loads.Add(1)
+		return 42, nil
+	}))
+
+	value, version, err := readThrough.GetIfChanged("a", 0)
+	s.NoError(err)
+	s.Equal(42, value)
+	s.NotZero(version)
+	s.Equal(int32(1), loads.Load())
+
+	_, _, err = readThrough.GetIfChanged("a", version)
+	s.ErrorIs(err, ErrNotModified)
+	s.Equal(int32(1), loads.Load())
+
+	cacheAside := New[string, int]()
+	_, _, err = cacheAside.GetIfChanged("a", 0)
+	s.ErrorIs(err, ErrNotCached)
+}
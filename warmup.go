@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// hotKeysPrefix starts the backing store keys of hot key lists.
+const hotKeysPrefix = "__hotkeys__:"
+
+// WithWarmup makes the cache track how often keys are accessed and, on
+// Shutdown, record its n most frequently accessed keys in the backing
+// store. A cache created later with the same option loads those keys from
+// the backing store in the background, so a freshly started process does
+// not begin cold. Hot key lists are JSON encoded. It has no effect without
+// WithStore.
+//
+// WithWarmup implies WithFrequencyTracking.
+func WithWarmup(n int) Option {
+	return func(o *options) {
+		o.warmup = n
+	}
+}
+
+// Warm loads the hot keys recorded by the last Shutdown of a cache using
+// WithWarmup from the backing store into memory and returns how many it
+// loaded. Keys that are already cached are left untouched. Caches created
+// with WithWarmup call Warm on their own; it is exported for callers that
+// want to wait for it.
+//
+// Warm returns ErrNoStore if the cache has no backing store.
+func (c *Cache[K, V]) Warm(ctx context.Context) (int, error) {
+	s := c.s
+	if s.remote == nil {
+		return 0, ErrNoStore
+	}
+	data, found, err := s.remote.Get(ctx, s.hotKeysKey(c.valueType))
+	if err != nil {
+		return 0, fmt.Errorf("cache: reading hot keys: %w", err)
+	}
+	if !found {
+		return 0, nil
+	}
+	var keys []K
+	if err := (JSONCodec{}).Unmarshal(data, &keys); err != nil {
+		return 0, fmt.Errorf("cache: decoding hot keys: %w", err)
+	}
+
+	loaded := 0
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return loaded, err
+		}
+		if _, cached := c.Peek(key); cached {
+			continue
+		}
+		if value, found := loadRemote[V](ctx, s, c.valueType, key); found {
+			if s.add(c.valueType, key, s.newEntry(c.valueType, value)) {
+				loaded++
+			}
+		}
+	}
+	return loaded, nil
+}
+
+// hotKeysKey returns the backing store key of the hot key list of
+// valueType.
+func (s *store) hotKeysKey(valueType reflect.Type) string {
+	return s.keyPrefix + hotKeysPrefix + valueType.String()
+}
+
+// saveHotKeys records the hot keys of every value type in the backing
+// store.
+func (s *store) saveHotKeys(ctx context.Context) error {
+	s.rlock()
+	types := make([]reflect.Type, 0, len(s.data))
+	for valueType := range s.data {
+		types = append(types, valueType)
+	}
+	s.mu.RUnlock()
+
+	for _, valueType := range types {
+		keys := s.hotKeys(valueType, s.warmup)
+		if len(keys) == 0 {
+			continue
+		}
+		data, err := (JSONCodec{}).Marshal(keys)
+		if err != nil {
+			return fmt.Errorf("cache: encoding hot keys of %v: %w", valueType, err)
+		}
+		if err := s.remote.Set(ctx, s.hotKeysKey(valueType), data, 0); err != nil {
+			return fmt.Errorf("cache: writing hot keys of %v: %w", valueType, err)
+		}
+	}
+	return nil
+}
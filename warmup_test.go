@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WarmupTestSuite struct {
+	suite.Suite
+	remote *memoryStore
+}
+
+func TestWarmupSuite(t *testing.T) {
+	suite.Run(t, new(WarmupTestSuite))
+}
+
+// SetupTest runs before each test
+func (s *WarmupTestSuite) SetupTest() {
+	s.remote = newMemoryStore()
+}
+
+// TestWarmLoadsHotKeys verifies that a new cache starts with the hot keys of
+// the previous one
+func (s *WarmupTestSuite) TestWarmLoadsHotKeys() {
+	first := New[int, string](WithStore(s.remote), WithWarmup(2))
+	for key := 1; key <= 3; key++ {
+		s.NoError(first.SetThrough(key, "value"))
+	}
+	for i := 0; i < 10; i++ {
+		_, _ = first.Get(2)
+	}
+	for i := 0; i < 5; i++ {
+		_, _ = first.Get(3)
+	}
+	_, _ = first.Get(1)
+	s.NoError(first.Shutdown(context.Background()))
+
+	second := New[int, string](WithStore(s.remote), WithWarmup(2))
+	s.Eventually(func() bool {
+		_, hot := second.Peek(2)
+		_, warm := second.Peek(3)
+		return hot && warm
+	}, time.Second, time.Millisecond)
+	_, found := second.Peek(1)
+	s.False(found, "Only the n hottest keys should be loaded")
+}
+
+// TestWarmKeepsCachedValues verifies that warming never overwrites entries
+func (s *WarmupTestSuite) TestWarmKeepsCachedValues() {
+	first := New[int, string](WithStore(s.remote), WithWarmup(10))
+	s.NoError(first.SetThrough(1, "stored"))
+	_, _ = first.Get(1)
+	s.NoError(first.Shutdown(context.Background()))
+
+	second := New[int, string](WithStore(s.remote))
+	second.Set(1, "fresh")
+	loaded, err := second.Warm(context.Background())
+	s.NoError(err)
+	s.Zero(loaded)
+	value, _ := second.Peek(1)
+	s.Equal("fresh", value)
+}
+
+// TestWarmWithoutStore verifies the error returned without a backing store
+func (s *WarmupTestSuite) TestWarmWithoutStore() {
+	_, err := New[int, string]().Warm(context.Background())
+	s.ErrorIs(err, ErrNoStore)
+}
@@ -0,0 +1,38 @@
+//go:build go1.24
+
+package cache
+
+import (
+	"reflect"
+	"unsafe"
+	"weak"
+)
+
+// weakRef is a weak reference to a pointer value of any type.
+type weakRef struct {
+	typ reflect.Type
+	ptr weak.Pointer[byte]
+}
+
+// newWeakRef returns a weak reference to value, or nil if value cannot be
+// held weakly.
+func newWeakRef(value any) *weakRef {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Type().Elem().Size() == 0 {
+		return nil
+	}
+	return &weakRef{
+		typ: v.Type(),
+		ptr: weak.Make((*byte)(v.UnsafePointer())),
+	}
+}
+
+// value returns the referenced value and reports whether it is still
+// available.
+func (r *weakRef) value() (any, bool) {
+	p := r.ptr.Value()
+	if p == nil {
+		return nil, false
+	}
+	return reflect.NewAt(r.typ.Elem(), unsafe.Pointer(p)).Convert(r.typ).Interface(), true
+}
@@ -0,0 +1,16 @@
+//go:build !go1.24
+
+package cache
+
+// weakRef is a weak reference to a value. Weak references need Go 1.24;
+// earlier versions never create one.
+type weakRef struct{}
+
+// newWeakRef returns nil: values are always held strongly.
+func newWeakRef(value any) *weakRef {
+	return nil
+}
+
+func (r *weakRef) value() (any, bool) {
+	return nil, false
+}
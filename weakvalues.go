@@ -0,0 +1,49 @@
+package cache
+
+// WithWeakValues holds cached pointer values weakly, so the garbage
+// collector may reclaim any value nothing outside the cache references.
+// The cache then shrinks by itself under memory pressure, and reclaimed
+// entries are treated as misses: Get loads them again. Values that are not
+// pointers, or point to zero-sized types, are held as usual.
+//
+// Weak values require Go 1.24 or later; built with an earlier version,
+// the cache holds every value strongly.
+func WithWeakValues() Option {
+	return func(o *options) {
+		o.weakValues = true
+	}
+}
+
+// get returns the value of e and reports whether it is still available:
+// weakly held values may have been reclaimed, and spilled ones fail to be
+// read back.
+func (e *entry) get() (any, bool) {
+	switch {
+	case e.spill != nil:
+		return e.spill.load()
+	case e.weak != nil:
+		return e.weak.value()
+	default:
+		return e.value, true
+	}
+}
+
+// holdsNil reports whether e caches a nil value. Spilled values, which are
+// never nil, are not read back.
+func (e *entry) holdsNil() bool {
+	if e.spill != nil {
+		return false
+	}
+	value, _ := e.get()
+	return isNil(value)
+}
+
+// reclaimed reports whether the value of e was weakly held and has been
+// reclaimed by the garbage collector.
+func (e *entry) reclaimed() bool {
+	if e.weak == nil {
+		return false
+	}
+	_, ok := e.weak.value()
+	return !ok
+}
@@ -0,0 +1,77 @@
+//go:build go1.24
+
+package cache
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WeakValuesTestSuite struct {
+	suite.Suite
+}
+
+func TestWeakValuesSuite(t *testing.T) {
+	suite.Run(t, new(WeakValuesTestSuite))
+}
+
+// document is large enough to get its own allocation.
+type document struct {
+	body [64]byte
+}
+
+// TestUnreferencedValuesAreReclaimed verifies that the garbage collector
+// reclaims values only the cache holds, and that they are then misses
+func (s *WeakValuesTestSuite) TestUnreferencedValuesAreReclaimed() {
+	loads := 0
+	c := New[string, *document](
+		WithWeakValues(),
+		WithLoader(func(key string) (*document, error) {
+			loads++
+			return &document{}, nil
+		}),
+	)
+	_, err := c.Get("a")
+	s.NoError(err)
+
+	s.Eventually(func() bool {
+		runtime.GC()
+		_, found := c.Peek("a")
+		return !found
+	}, time.Second, time.Millisecond)
+	s.Equal(0, c.Stats().Entries)
+
+	_, err = c.Get("a")
+	s.NoError(err)
+	s.Equal(2, loads, "A reclaimed entry is loaded again")
+}
+
+// TestReferencedValuesStay verifies that values still in use elsewhere are
+// served from the cache
+func (s *WeakValuesTestSuite) TestReferencedValuesStay() {
+	c := New[string, *document](WithWeakValues())
+	doc := &document{}
+	c.Set("a", doc)
+
+	runtime.GC()
+	runtime.GC()
+	cached, found := c.Peek("a")
+	s.True(found)
+	s.Same(doc, cached)
+	runtime.KeepAlive(doc)
+}
+
+// TestNonPointerValuesAreHeldStrongly verifies that values the cache
+// cannot reference weakly are never reclaimed
+func (s *WeakValuesTestSuite) TestNonPointerValuesAreHeldStrongly() {
+	c := New[string, document](WithWeakValues())
+	c.Set("a", document{})
+
+	runtime.GC()
+	runtime.GC()
+	_, found := c.Peek("a")
+	s.True(found)
+}
@@ -0,0 +1,59 @@
+package cache
+
+// weightedGetter is the form getters take inside the cache: it also
+// returns the cost of the value, or a negative cost if it is not known.
+type weightedGetter[K comparable, V any] func(K) (V, int64, error)
+
+// unweighted adapts a getter that does not report costs.
+func unweighted[K comparable, V any](fn func(K) (V, error)) weightedGetter[K, V] {
+	if fn == nil {
+		return nil
+	}
+	return func(key K) (V, int64, error) {
+		value, err := fn(key)
+		return value, -1, err
+	}
+}
+
+// WithWeightedLoader is WithLoader for loaders that also report the cost
+// of the values they produce, such as the byte size of a parsed document.
+// The cost is used as the size of the entry by WithMaxBytes and statistics
+// instead of the size estimator, which only measures values whose cost is
+// negative or that are written with Set.
+func WithWeightedLoader[K comparable, V any](fn func(K) (V, int64, error)) Option {
+	return func(o *options) {
+		o.loader = fn
+	}
+}
+
+// GetWeighted is Get for getters that also report the cost of the values
+// they produce; see WithWeightedLoader.
+//
+//	doc, err := cache.GetWeighted(url, func(url string) (*Document, int64, error) {
+//		raw, err := fetch(url)
+//		if err != nil {
+//			return nil, 0, err
+//		}
+//		doc, err := parse(raw)
+//		return doc, int64(len(raw)), err
+//	})
+func GetWeighted[K comparable, V any](key K, getterFunc func(K) (V, int64, error), opts ...Option) (V, error) {
+	s := globalStore()
+	if err := s.checkKey(key); err != nil {
+		var zero V
+		return zero, err
+	}
+	var call options
+	for _, opt := range opts {
+		opt(&call)
+	}
+	if call.forceRefresh {
+		var zero V
+		if s.refreshThrottled(getTypeOf(zero), key) {
+			call.forceRefresh = false
+		} else {
+			s.audit(AuditRefresh, getTypeOf(zero), key, nil, call.reason)
+		}
+	}
+	return load(s, key, getterFunc, call)
+}
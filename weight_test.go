@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WeightTestSuite struct {
+	suite.Suite
+}
+
+func TestWeightSuite(t *testing.T) {
+	suite.Run(t, new(WeightTestSuite))
+}
+
+func (s *WeightTestSuite) SetupTest() {
+	Scoped(s.T())
+}
+
+// TestWeightedLoader verifies that costs reported by the loader drive byte
+// limits
+func (s *WeightTestSuite) TestWeightedLoader() {
+	c := New[string, string](WithMaxBytes(100), WithWeightedLoader(func(key string) (string, int64, error) {
+		return key, 60, nil
+	}))
+
+	_, err := c.Get("a")
+	s.Require().NoError(err)
+	s.Equal(int64(60), c.Stats().Bytes)
+	_, err = c.Get("b")
+	s.Require().NoError(err)
+	s.Equal(1, c.Stats().Entries, "Two entries of 60 bytes exceed the limit")
+	s.Equal(int64(60), c.Stats().Bytes)
+}
+
+// TestGetWeighted verifies that negative costs fall back to the size
+// estimator
+func (s *WeightTestSuite) TestGetWeighted() {
+	SetDefaults(WithSizeEstimator(func(value any) int64 {
+		return int64(len(value.(string)))
+	}))
+	_, err := GetWeighted("parsed", func(key string) (string, int64, error) {
+		return key, 1000, nil
+	})
+	s.Require().NoError(err)
+	_, err = GetWeighted("estimated", func(key string) (string, int64, error) {
+		return strings.Repeat("x", 10), -1, nil
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(1010), Stats().Bytes)
+}
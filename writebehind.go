@@ -0,0 +1,254 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WriteBehindConfig configures asynchronous write-behind to the backing
+// store. Zero fields take their defaults.
+type WriteBehindConfig struct {
+	// QueueSize bounds the number of pending writes. Set blocks while the
+	// queue is full. Default 1024.
+	QueueSize int
+	// BatchSize is the maximum number of writes flushed together.
+	// Default 100.
+	BatchSize int
+	// FlushInterval is the longest a write waits before being flushed.
+	// Default 1s.
+	FlushInterval time.Duration
+	// MaxRetries is how many times a failed write is retried before it is
+	// dropped and reported as an EventStoreError. Default 3.
+	MaxRetries int
+	// RetryBackoff is the pause between retries. Default 100ms.
+	RetryBackoff time.Duration
+}
+
+// BatchStore is implemented by stores that can write several values in one
+// round trip. Write-behind flushes use SetMany when available.
+type BatchStore interface {
+	Store
+	SetMany(ctx context.Context, items []StoreItem) error
+}
+
+// StoreItem is a single write in a BatchStore.SetMany call.
+type StoreItem struct {
+	Key   string
+	Value []byte
+	TTL   time.Duration
+}
+
+// WithWriteBehind makes Set write to the backing store asynchronously: the
+// local entry is updated immediately and the write is queued for a
+// background worker that flushes in batches with retries. Close and
+// Shutdown drain the queue. It has no effect without WithStore.
+func WithWriteBehind(cfg WriteBehindConfig) Option {
+	return func(o *options) {
+		o.writeBehind = &cfg
+	}
+}
+
+type writeOp struct {
+	item      StoreItem
+	valueType reflect.Type
+	key       any
+	// version is the version of the entry the write was queued for
+	version uint64
+}
+
+type writeBehind struct {
+	cfg   WriteBehindConfig
+	s     *store
+	queue chan writeOp
+	done  chan struct{}
+	// pending counts the writes queued or being flushed
+	pending atomic.Int64
+
+	// mu guards closed against concurrent enqueues
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newWriteBehind(s *store, cfg WriteBehindConfig) *writeBehind {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 100 * time.Millisecond
+	}
+	w := &writeBehind{
+		cfg:   cfg,
+		s:     s,
+		queue: make(chan writeOp, cfg.QueueSize),
+		done:  make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// enqueue queues op, blocking while the queue is full. It reports false if
+// the worker has been closed.
+func (w *writeBehind) enqueue(op writeOp) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return false
+	}
+	w.pending.Add(1)
+	w.queue <- op
+	return true
+}
+
+// shutdown stops accepting writes and waits until everything queued has
+// been flushed or ctx ends. The worker keeps flushing in the background
+// after ctx ends.
+func (w *writeBehind) shutdown(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *writeBehind) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]writeOp, 0, w.cfg.BatchSize)
+	for {
+		select {
+		case op, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, op)
+			if len(batch) >= w.cfg.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush writes a batch, retrying failed writes. Writes whose entry has
+// been replaced locally are dropped, since the Set and the enqueue of
+// concurrent writers can interleave, and the remaining writes to the same
+// key are collapsed so only the latest value is sent.
+func (w *writeBehind) flush(batch []writeOp) {
+	if len(batch) == 0 {
+		return
+	}
+	defer w.pending.Add(-int64(len(batch)))
+	latest := make(map[string]int, len(batch))
+	pending := make([]writeOp, 0, len(batch))
+	for _, op := range w.current(batch) {
+		if i, ok := latest[op.item.Key]; ok {
+			pending[i] = op
+			continue
+		}
+		latest[op.item.Key] = len(pending)
+		pending = append(pending, op)
+	}
+
+	for attempt := 0; ; attempt++ {
+		failed, err := w.write(pending)
+		if len(failed) == 0 {
+			return
+		}
+		if attempt >= w.cfg.MaxRetries {
+			for _, op := range failed {
+				w.s.emit(Event{
+					Kind: EventStoreError,
+					Type: op.valueType,
+					Key:  op.key,
+					Err:  fmt.Errorf("write-behind dropped after %d retries: %w", w.cfg.MaxRetries, err),
+				})
+			}
+			return
+		}
+		time.Sleep(w.cfg.RetryBackoff)
+		pending = failed
+	}
+}
+
+// current returns the ops whose entry is still the one cached for their
+// key, or whose key is no longer cached at all.
+func (w *writeBehind) current(ops []writeOp) []writeOp {
+	kept := make([]writeOp, 0, len(ops))
+	w.s.rlock()
+	for _, op := range ops {
+		if e, ok := w.s.entryLocked(op.valueType, op.key); ok && e.version != op.version {
+			continue
+		}
+		kept = append(kept, op)
+	}
+	w.s.mu.RUnlock()
+	return kept
+}
+
+// write sends ops to the store and returns those that failed along with
+// the last error seen.
+func (w *writeBehind) write(ops []writeOp) ([]writeOp, error) {
+	ctx := context.Background()
+	if bs, ok := w.s.remote.(BatchStore); ok {
+		items := make([]StoreItem, len(ops))
+		for i, op := range ops {
+			items[i] = op.item
+		}
+		if err := bs.SetMany(ctx, items); err != nil {
+			return ops, err
+		}
+		return nil, nil
+	}
+
+	var failed []writeOp
+	var lastErr error
+	for _, op := range ops {
+		if err := w.s.remote.Set(ctx, op.item.Key, op.item.Value, op.item.TTL); err != nil {
+			failed = append(failed, op)
+			lastErr = err
+		}
+	}
+	return failed, lastErr
+}
+
+// queueWrite encodes value, stored locally in the entry of the given
+// version, and hands it to the write-behind worker.
+func (s *store) queueWrite(valueType reflect.Type, key, value any, version uint64) {
+	data, err := s.codecFor(valueType).Marshal(value)
+	if err != nil {
+		s.emit(Event{Kind: EventStoreError, Type: valueType, Key: key, Err: fmt.Errorf("encoding value: %w", err)})
+		return
+	}
+	s.writeBehind.enqueue(writeOp{
+		item:      StoreItem{Key: s.remoteKey(valueType, key), Value: data, TTL: s.ttlFor(valueType)},
+		valueType: valueType,
+		key:       key,
+		version:   version,
+	})
+}
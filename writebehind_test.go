@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// batchingStore records SetMany calls on top of memoryStore
+type batchingStore struct {
+	*memoryStore
+	batches atomic.Int32
+}
+
+func (b *batchingStore) SetMany(ctx context.Context, items []StoreItem) error {
+	b.batches.Add(1)
+	for _, item := range items {
+		if err := b.memoryStore.Set(ctx, item.Key, item.Value, item.TTL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flakyStore fails the first n writes
+type flakyStore struct {
+	*memoryStore
+	remaining atomic.Int32
+}
+
+func (f *flakyStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if f.remaining.Add(-1) >= 0 {
+		return errors.New("transient failure")
+	}
+	return f.memoryStore.Set(ctx, key, value, ttl)
+}
+
+type WriteBehindTestSuite struct {
+	suite.Suite
+	remote *memoryStore
+}
+
+func TestWriteBehindSuite(t *testing.T) {
+	suite.Run(t, new(WriteBehindTestSuite))
+}
+
+// SetupTest runs before each test
+func (s *WriteBehindTestSuite) SetupTest() {
+	s.remote = newMemoryStore()
+}
+
+func (s *WriteBehindTestSuite) stored(key string) (string, bool) {
+	data, found, err := s.remote.Get(context.Background(), key)
+	s.Require().NoError(err)
+	return string(data), found
+}
+
+// TestSetIsAppliedLocallyAndFlushedOnClose verifies local visibility and draining on Close
+func (s *WriteBehindTestSuite) TestSetIsAppliedLocallyAndFlushedOnClose() {
+	c := New[int, string](
+		WithStore(s.remote),
+		WithWriteBehind(WriteBehindConfig{FlushInterval: time.Hour}),
+	)
+
+	c.Set(1, "one")
+	c.Set(2, "two")
+
+	// Visible locally right away
+	local, found := c.Peek(1)
+	s.True(found)
+	s.Equal("one", local)
+
+	s.NoError(c.Close())
+	value, found := s.stored(keyString(c.valueType, 1))
+	s.True(found, "Close should flush pending writes")
+	s.Equal(`"one"`, value)
+	_, found = s.stored(keyString(c.valueType, 2))
+	s.True(found)
+
+	// Close is idempotent
+	s.NoError(c.Close())
+}
+
+// TestWritesAreFlushedPeriodically verifies the flush interval
+func (s *WriteBehindTestSuite) TestWritesAreFlushedPeriodically() {
+	c := New[int, string](
+		WithStore(s.remote),
+		WithWriteBehind(WriteBehindConfig{FlushInterval: 10 * time.Millisecond}),
+	)
+	defer c.Close()
+
+	c.Set(1, "one")
+	s.Eventually(func() bool {
+		_, found := s.stored(keyString(c.valueType, 1))
+		return found
+	}, time.Second, 5*time.Millisecond)
+}
+
+// TestWritesAreBatchedAndCollapsed verifies SetMany batching and last-write-wins per key
+func (s *WriteBehindTestSuite) TestWritesAreBatchedAndCollapsed() {
+	remote := &batchingStore{memoryStore: s.remote}
+	c := New[int, string](
+		WithStore(remote),
+		WithWriteBehind(WriteBehindConfig{BatchSize: 10, FlushInterval: time.Hour}),
+	)
+
+	for i := 0; i < 5; i++ {
+		c.Set(i, "first")
+	}
+	c.Set(0, "last")
+	s.NoError(c.Close())
+
+	s.Equal(int32(1), remote.batches.Load(), "All writes should go out in one batch")
+	s.Equal(int32(5), s.remote.sets.Load(), "Writes to the same key should be collapsed")
+	value, _ := s.stored(keyString(c.valueType, 0))
+	s.Equal(`"last"`, value)
+}
+
+// TestSupersededWritesAreDropped verifies that a write queued after the
+// entry it was made for was replaced does not overwrite the newer value
+func (s *WriteBehindTestSuite) TestSupersededWritesAreDropped() {
+	c := New[int, string](
+		WithStore(s.remote),
+		WithWriteBehind(WriteBehindConfig{FlushInterval: time.Hour}),
+	)
+	s.Require().NoError(c.Set(1, "first"))
+	first, _ := c.Version(1)
+	s.Require().NoError(c.Set(1, "second"))
+
+	// The first writer is only now enqueueing, after the second one
+	c.s.queueWrite(c.valueType, 1, "first", first)
+	s.NoError(c.Close())
+
+	value, _ := s.stored(keyString(c.valueType, 1))
+	s.Equal(`"second"`, value)
+}
+
+// TestFailedWritesAreRetried verifies retries and the event emitted when they are exhausted
+func (s *WriteBehindTestSuite) TestFailedWritesAreRetried() {
+	remote := &flakyStore{memoryStore: s.remote}
+	remote.remaining.Store(2)
+
+	var mu sync.Mutex
+	var events []Event
+	c := New[int, string](
+		WithStore(remote),
+		WithWriteBehind(WriteBehindConfig{MaxRetries: 2, RetryBackoff: time.Millisecond}),
+		WithEventHandler(func(ev Event) {
+			mu.Lock()
+			events = append(events, ev)
+			mu.Unlock()
+		}),
+	)
+
+	// Succeeds on the third attempt
+	c.Set(1, "one")
+	s.NoError(c.Close())
+	_, found := s.stored(keyString(c.valueType, 1))
+	s.True(found)
+	s.Empty(events)
+
+	// Exhausts retries and reports the dropped write
+	remote.remaining.Store(10)
+	c = New[int, string](
+		WithStore(remote),
+		WithWriteBehind(WriteBehindConfig{MaxRetries: 2, RetryBackoff: time.Millisecond}),
+		WithEventHandler(func(ev Event) {
+			mu.Lock()
+			events = append(events, ev)
+			mu.Unlock()
+		}),
+	)
+	c.Set(2, "two")
+	s.NoError(c.Close())
+	_, found = s.stored(keyString(c.valueType, 2))
+	s.False(found)
+	s.Require().Len(events, 1)
+	s.Equal(EventStoreError, events[0].Kind)
+	s.Equal(2, events[0].Key)
+}
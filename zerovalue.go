@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"reflect"
+	"time"
+)
+
+// WithZeroValueTTL makes getter results equal to the zero value of their
+// type, such as 0, "", an empty struct or a nil pointer, expire after ttl
+// when that is sooner than their usual expiration. Many origins return
+// zero values to mean absent, which is usually wrong to cache for long. A
+// negative ttl keeps zero values out of the cache entirely; they are still
+// returned to the caller. Zero, the default, caches them like any other
+// value. Values written with Set are not affected.
+func WithZeroValueTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.zeroValueTTL = ttl
+	}
+}
+
+// zeroValueTTL returns the TTL of value if it is a zero value subject to
+// WithZeroValueTTL.
+func (s *store) zeroValueTTL(value any) (time.Duration, bool) {
+	ttl := s.cfg().zeroValueTTL
+	if ttl == 0 || !isZero(value) {
+		return 0, false
+	}
+	return ttl, true
+}
+
+// limitExpiry makes e expire after ttl unless it expires sooner already.
+func (s *store) limitExpiry(e *entry, ttl time.Duration) {
+	if e.expiresAt.IsZero() || s.now().Add(ttl).Before(e.expiresAt) {
+		s.setExpiry(e, ttl)
+	}
+}
+
+// isZero reports whether v is nil or the zero value of its type.
+func isZero(v any) bool {
+	return v == nil || reflect.ValueOf(v).IsZero()
+}